@@ -0,0 +1,85 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+const (
+	// AnnotationPrefix is the prefix of the annotations the operator reads
+	// and writes. BSSQuery outputs cannot write annotations with it.
+	AnnotationPrefix = "bss.localhost/"
+
+	// AnnotationClusterID records the ID of the cluster registered with the
+	// bss-api for a BssCluster. Pre-delete hooks use it to deregister the
+	// remote cluster when the BssCluster is deleted; without it the remote
+	// cluster is looked up by spec.name.
+	AnnotationClusterID = "bss.localhost/cluster-id"
+
+	// AnnotationSkipPreDeleteHooks, when set to "true" on a BssCluster that is
+	// being deleted, skips the pre-delete hooks. Use it to release a BssCluster
+	// whose external cleanup can never succeed.
+	AnnotationSkipPreDeleteHooks = "bss.localhost/skip-pre-delete-hooks"
+
+	// AnnotationConfigHash is stamped on the pod template of the bss-api
+	// workload with a hash of spec.config, so configuration changes roll the
+	// pods.
+	AnnotationConfigHash = "bss.localhost/config-hash"
+
+	// AnnotationRotateCredentials requests a rotation of the bss-api
+	// credentials of a BssCluster whenever its value changes, for example to
+	// the current time. Generated credentials are regenerated; pods reading a
+	// referenced Secret are rolled to pick up its current values.
+	AnnotationRotateCredentials = "bss.localhost/rotate-credentials"
+
+	// AnnotationCredentialsRotatedAt is stamped on the credentials Secret and
+	// on the pod template of the bss-api workload with the time of the last
+	// credentials rotation
+	AnnotationCredentialsRotatedAt = "bss.localhost/credentials-rotated-at"
+
+	// AnnotationCredentialsRotationRequest is stamped on the generated
+	// credentials Secret with the value of the rotate-credentials annotation
+	// of its last rotation
+	AnnotationCredentialsRotationRequest = "bss.localhost/credentials-rotation-request"
+
+	// AnnotationVersion is stamped on the pod template of the bss-api workload
+	// with the bss-api version it runs
+	AnnotationVersion = "bss.localhost/version"
+
+	// AnnotationAllowUnsafeUpgrade, when set to "true" on a BssCluster, allows
+	// spec.version to be downgraded or to skip a major version
+	AnnotationAllowUnsafeUpgrade = "bss.localhost/allow-unsafe-upgrade"
+
+	// AnnotationUpgradeStartTime is stamped on upgrade Jobs with the start
+	// time of the upgrade they belong to, so Jobs left over from an earlier
+	// upgrade are replaced
+	AnnotationUpgradeStartTime = "bss.localhost/upgrade-start-time"
+
+	// AnnotationAppliedHash is stamped on child resources with a hash of the
+	// state the operator last applied, so changes the operator makes are told
+	// apart from drift
+	AnnotationAppliedHash = "bss.localhost/applied-hash"
+
+	// AnnotationPodTemplateHash is stamped on the bss-api workload with a
+	// hash of the pod template last rolled out
+	AnnotationPodTemplateHash = "bss.localhost/pod-template-hash"
+
+	// AnnotationPendingPodTemplateHash is stamped on the bss-api workload with
+	// a hash of a pod template that waits for the maintenance window
+	AnnotationPendingPodTemplateHash = "bss.localhost/pending-pod-template-hash"
+
+	// AnnotationPaused, when set to "true" on a BssCluster or BSSQuery, pauses
+	// it like spec.paused does, without changing its spec
+	AnnotationPaused = "bss.localhost/paused"
+)
@@ -17,7 +17,14 @@ limitations under the License.
 package v1alpha1
 
 import (
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
 )
 
 // NOTE: json tags are required.  Any new fields you add must have json tags for the fields to be serialized.
@@ -30,23 +37,684 @@ type BssClusterSpec struct {
 	// Name is the name of the bss-api cluster to create
 	Name string `json:"name"`
 
-	// Replicas is the number of bss-api replicas to deploy
+	// Replicas is the number of bss-api replicas to deploy. It can be changed
+	// through the scale subresource, and is ignored while spec.autoscaling is set.
 	// +kubebuilder:default=1
 	// +kubebuilder:validation:Minimum=1
 	Replicas *int32 `json:"replicas,omitempty"`
 
-	// Version is the version of bss-api to deploy
+	// Version is the version of bss-api to deploy. Changing it starts an
+	// upgrade that is rolled back if the new version does not become healthy.
+	// Downgrades and upgrades that skip a major version are rejected unless
+	// the bss.localhost/allow-unsafe-upgrade annotation is set.
 	Version string `json:"version"`
+
+	// Upgrade configures how changes to spec.version are rolled out
+	// +optional
+	Upgrade *UpgradeSpec `json:"upgrade,omitempty"`
+
+	// Paused stops the operator from changing the children of the BssCluster,
+	// for example during manual maintenance. Drift is no longer corrected and
+	// spec changes are only rolled out once the cluster is resumed. The
+	// bss.localhost/paused annotation pauses the cluster too.
+	// +optional
+	Paused bool `json:"paused,omitempty"`
+
+	// MaintenanceWindow restricts disruptive changes to a recurring window:
+	// starting a version upgrade, a scheduled credentials rotation, and any
+	// other change to the pod template, such as the image, that rolls the
+	// pods. They wait for the next window to open, which the Progressing
+	// condition reports; changes that are not disruptive are rolled out
+	// immediately. Rotations requested with the
+	// bss.localhost/rotate-credentials annotation are not held back.
+	// +optional
+	MaintenanceWindow *MaintenanceWindowSpec `json:"maintenanceWindow,omitempty"`
+
+	// Image configures the bss-api container image. By default the image is
+	// pulled from the operator's default registry and tagged with spec.version.
+	// +optional
+	Image *ImageSpec `json:"image,omitempty"`
+
+	// Service configures the Service that exposes the bss-api GraphQL endpoint
+	// +optional
+	Service *BssClusterServiceSpec `json:"service,omitempty"`
+
+	// Exposure publishes the bss-api GraphQL endpoint outside the cluster
+	// through an Ingress or a Gateway API HTTPRoute
+	// +optional
+	Exposure *ExposureSpec `json:"exposure,omitempty"`
+
+	// NetworkPolicy restricts the traffic to, and optionally from, the bss-api
+	// pods with an owned NetworkPolicy
+	// +optional
+	NetworkPolicy *NetworkPolicySpec `json:"networkPolicy,omitempty"`
+
+	// Availability protects bss-api replicas against voluntary disruptions
+	// such as node drains
+	// +optional
+	Availability *AvailabilitySpec `json:"availability,omitempty"`
+
+	// Autoscaling scales the bss-api workload with a HorizontalPodAutoscaler
+	// +optional
+	Autoscaling *AutoscalingSpec `json:"autoscaling,omitempty"`
+
+	// Config is the bss-api configuration file. It is rendered into a
+	// ConfigMap mounted into the bss-api pods, and changing it rolls the pods.
+	// +optional
+	Config *ConfigSpec `json:"config,omitempty"`
+
+	// Credentials configures the admin and API credentials of bss-api, either
+	// generated by the operator or read from an existing Secret
+	// +optional
+	Credentials *CredentialsSpec `json:"credentials,omitempty"`
+
+	// Workload selects the kind of workload that runs bss-api. Changing it
+	// migrates the cluster: the new workload is rolled out and becomes ready
+	// before the old one is removed, and the old one is left unchanged in the
+	// meantime. The volume claims of a StatefulSet migrated away from are
+	// retained, whatever storage.retentionPolicy says, and are not deleted
+	// with the BssCluster.
+	// +kubebuilder:default=Deployment
+	// +optional
+	Workload WorkloadType `json:"workload,omitempty"`
+
+	// Resources are the compute resources of the bss-api container
+	// +optional
+	Resources corev1.ResourceRequirements `json:"resources,omitempty"`
+
+	// Env are extra environment variables for the bss-api container
+	// +optional
+	Env []corev1.EnvVar `json:"env,omitempty"`
+
+	// EnvFrom populates environment variables of the bss-api container from
+	// ConfigMaps or Secrets
+	// +optional
+	EnvFrom []corev1.EnvFromSource `json:"envFrom,omitempty"`
+
+	// LivenessProbe overrides the default liveness probe, an HTTP GET on
+	// /graphql
+	// +optional
+	LivenessProbe *corev1.Probe `json:"livenessProbe,omitempty"`
+
+	// ReadinessProbe overrides the default readiness probe, an HTTP GET on
+	// /graphql
+	// +optional
+	ReadinessProbe *corev1.Probe `json:"readinessProbe,omitempty"`
+
+	// StartupProbe overrides the default startup probe, an HTTP GET on
+	// /graphql that allows bss-api up to five minutes to start
+	// +optional
+	StartupProbe *corev1.Probe `json:"startupProbe,omitempty"`
+
+	// NodeSelector constrains bss-api pods to nodes with matching labels
+	// +optional
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+
+	// Tolerations of the bss-api pods
+	// +optional
+	Tolerations []corev1.Toleration `json:"tolerations,omitempty"`
+
+	// Affinity are the scheduling constraints of the bss-api pods
+	// +optional
+	Affinity *corev1.Affinity `json:"affinity,omitempty"`
+
+	// TopologySpreadConstraints spread bss-api pods across topology domains
+	// +optional
+	TopologySpreadConstraints []corev1.TopologySpreadConstraint `json:"topologySpreadConstraints,omitempty"`
+
+	// PriorityClassName is the priority class of the bss-api pods
+	// +optional
+	PriorityClassName string `json:"priorityClassName,omitempty"`
+
+	// PodSecurityContext overrides the default pod security context, which
+	// satisfies the restricted Pod Security Standard
+	// +optional
+	PodSecurityContext *corev1.PodSecurityContext `json:"podSecurityContext,omitempty"`
+
+	// ContainerSecurityContext overrides the default security context of the
+	// bss-api container, which satisfies the restricted Pod Security Standard
+	// +optional
+	ContainerSecurityContext *corev1.SecurityContext `json:"containerSecurityContext,omitempty"`
+
+	// Storage configures a persistent volume for every bss-api replica.
+	// Only supported with the StatefulSet workload.
+	// +optional
+	Storage *StorageSpec `json:"storage,omitempty"`
+}
+
+// ImageSpec defines where the bss-api image is pulled from
+type ImageSpec struct {
+	// Repository is the image repository without a tag or digest, such as
+	// registry.example.com/bss/bss-api. Defaults to bss-api in the operator's
+	// default registry.
+	// +kubebuilder:validation:Pattern=`^[^@:]+(:[0-9]+)?(/[^@:]+)*$`
+	// +optional
+	Repository string `json:"repository,omitempty"`
+
+	// Tag overrides the image tag, which defaults to spec.version
+	// +kubebuilder:validation:Pattern=`^[A-Za-z0-9_][A-Za-z0-9_.-]{0,127}$`
+	// +optional
+	Tag string `json:"tag,omitempty"`
+
+	// Digest pins the image to a content digest and takes precedence over the tag
+	// +kubebuilder:validation:Pattern=`^sha256:[a-f0-9]{64}$`
+	// +optional
+	Digest string `json:"digest,omitempty"`
+
+	// PullPolicy is the image pull policy of the bss-api container
+	// +kubebuilder:validation:Enum=Always;IfNotPresent;Never
+	// +optional
+	PullPolicy corev1.PullPolicy `json:"pullPolicy,omitempty"`
+
+	// PullSecrets are Secrets in the BssCluster namespace used to pull the image
+	// +optional
+	PullSecrets []corev1.LocalObjectReference `json:"pullSecrets,omitempty"`
+}
+
+// BssClusterServiceSpec defines how the bss-api Service is exposed
+type BssClusterServiceSpec struct {
+	// Type is the type of the Service
+	// +kubebuilder:validation:Enum=ClusterIP;NodePort;LoadBalancer
+	// +kubebuilder:default=ClusterIP
+	// +optional
+	Type corev1.ServiceType `json:"type,omitempty"`
+
+	// Port is the port the Service exposes the GraphQL endpoint on
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	// +kubebuilder:default=80
+	// +optional
+	Port int32 `json:"port,omitempty"`
+
+	// Annotations are added to the Service, for example to configure a cloud
+	// load balancer
+	// +optional
+	Annotations map[string]string `json:"annotations,omitempty"`
+
+	// LoadBalancerSourceRanges restricts the client IPs allowed through a
+	// LoadBalancer Service
+	// +optional
+	LoadBalancerSourceRanges []string `json:"loadBalancerSourceRanges,omitempty"`
+
+	// ExternalTrafficPolicy of a NodePort or LoadBalancer Service
+	// +kubebuilder:validation:Enum=Cluster;Local
+	// +optional
+	ExternalTrafficPolicy corev1.ServiceExternalTrafficPolicy `json:"externalTrafficPolicy,omitempty"`
+
+	// SessionAffinity of the Service
+	// +kubebuilder:validation:Enum=None;ClientIP
+	// +optional
+	SessionAffinity corev1.ServiceAffinity `json:"sessionAffinity,omitempty"`
+
+	// ExtraPorts are additional ports published by the Service, for example
+	// for sidecars added through the pod template
+	// +optional
+	ExtraPorts []corev1.ServicePort `json:"extraPorts,omitempty"`
+}
+
+// ExposureType is the kind of resource that routes external traffic to bss-api
+// +kubebuilder:validation:Enum=Ingress;HTTPRoute
+type ExposureType string
+
+const (
+	ExposureTypeIngress   ExposureType = "Ingress"
+	ExposureTypeHTTPRoute ExposureType = "HTTPRoute"
+)
+
+// ExposureSpec defines how the bss-api GraphQL endpoint is reached from
+// outside the cluster
+type ExposureSpec struct {
+	// Type selects whether an Ingress or a Gateway API HTTPRoute is created
+	// +kubebuilder:default=Ingress
+	// +optional
+	Type ExposureType `json:"type,omitempty"`
+
+	// Host is the DNS name clients use to reach bss-api
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?(\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*$`
+	// +kubebuilder:validation:MaxLength=253
+	Host string `json:"host"`
+
+	// Path is the path prefix routed to bss-api. Requests are forwarded
+	// unchanged, so the prefix must be served by bss-api.
+	// +kubebuilder:validation:Pattern=`^/`
+	// +kubebuilder:default="/graphql"
+	// +optional
+	Path string `json:"path,omitempty"`
+
+	// Annotations are added to the Ingress or HTTPRoute, for example to
+	// configure the ingress controller
+	// +optional
+	Annotations map[string]string `json:"annotations,omitempty"`
+
+	// IngressClassName selects the ingress controller. The cluster default is
+	// used when unset. Only applies to the Ingress type.
+	// +optional
+	IngressClassName *string `json:"ingressClassName,omitempty"`
+
+	// ParentRefs are the Gateways the HTTPRoute attaches to. Required for the
+	// HTTPRoute type.
+	// +kubebuilder:validation:MaxItems=32
+	// +optional
+	ParentRefs []gatewayv1.ParentReference `json:"parentRefs,omitempty"`
+
+	// TLS serves bss-api over HTTPS
+	// +optional
+	TLS *ExposureTLSSpec `json:"tls,omitempty"`
+}
+
+// ExposureTLSSpec configures TLS for the exposed GraphQL endpoint
+type ExposureTLSSpec struct {
+	// SecretName is the Secret holding the certificate of the Ingress. It must
+	// be left unset for the HTTPRoute type, where TLS is terminated by the
+	// listener of the parent Gateway.
+	// +optional
+	SecretName string `json:"secretName,omitempty"`
+}
+
+// NetworkPolicySpec defines the NetworkPolicy of the bss-api pods. Ingress
+// to the Service ports is always allowed from the namespace of the operator,
+// so BSSQuery can poll bss-api, and from post-upgrade Jobs.
+type NetworkPolicySpec struct {
+	// From are additional peers allowed to reach the Service ports of bss-api,
+	// such as the namespace of an ingress controller or client pods
+	// +optional
+	From []networkingv1.NetworkPolicyPeer `json:"from,omitempty"`
+
+	// Egress restricts the traffic leaving the bss-api pods to these rules.
+	// Egress is not restricted when it is empty.
+	// +optional
+	Egress []networkingv1.NetworkPolicyEgressRule `json:"egress,omitempty"`
+}
+
+// AvailabilitySpec defines the availability guarantees of bss-api
+type AvailabilitySpec struct {
+	// PodDisruptionBudget limits how many bss-api replicas can be evicted at once
+	// +optional
+	PodDisruptionBudget *PodDisruptionBudgetSpec `json:"podDisruptionBudget,omitempty"`
+}
+
+// PodDisruptionBudgetSpec defines the PodDisruptionBudget of bss-api. At most
+// one of minAvailable and maxUnavailable can be set; when neither is, at most
+// one replica is unavailable at a time.
+type PodDisruptionBudgetSpec struct {
+	// MinAvailable is the number or percentage of replicas that must remain
+	// available during a disruption
+	// +optional
+	MinAvailable *intstr.IntOrString `json:"minAvailable,omitempty"`
+
+	// MaxUnavailable is the number or percentage of replicas that can be
+	// unavailable during a disruption
+	// +optional
+	MaxUnavailable *intstr.IntOrString `json:"maxUnavailable,omitempty"`
+}
+
+// AutoscalingSpec defines the HorizontalPodAutoscaler of bss-api. When no
+// target is set, replicas are scaled to 80% average CPU utilization.
+type AutoscalingSpec struct {
+	// MinReplicas is the lower bound of the replica count
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:default=1
+	// +optional
+	MinReplicas *int32 `json:"minReplicas,omitempty"`
+
+	// MaxReplicas is the upper bound of the replica count
+	// +kubebuilder:validation:Minimum=1
+	MaxReplicas int32 `json:"maxReplicas"`
+
+	// TargetCPUUtilizationPercentage is the target average CPU utilization,
+	// relative to the CPU requests of the bss-api container
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	TargetCPUUtilizationPercentage *int32 `json:"targetCPUUtilizationPercentage,omitempty"`
+
+	// TargetMemoryUtilizationPercentage is the target average memory
+	// utilization, relative to the memory requests of the bss-api container
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	TargetMemoryUtilizationPercentage *int32 `json:"targetMemoryUtilizationPercentage,omitempty"`
+
+	// Metrics are additional metric targets, such as custom or external metrics
+	// +optional
+	Metrics []autoscalingv2.MetricSpec `json:"metrics,omitempty"`
+
+	// Behavior configures the scaling velocity in each direction
+	// +optional
+	Behavior *autoscalingv2.HorizontalPodAutoscalerBehavior `json:"behavior,omitempty"`
+}
+
+// ConfigSpec defines the bss-api configuration file, either as structured
+// settings or as a raw file body
+type ConfigSpec struct {
+	// Settings are rendered as a flat YAML document with sorted keys
+	// +optional
+	Settings map[string]string `json:"settings,omitempty"`
+
+	// Raw is the verbatim YAML body of the configuration file. It cannot be
+	// combined with settings.
+	// +kubebuilder:validation:MaxLength=524288
+	// +optional
+	Raw string `json:"raw,omitempty"`
+}
+
+// CredentialsInjection selects how credentials are passed to bss-api
+// +kubebuilder:validation:Enum=Env;File
+type CredentialsInjection string
+
+const (
+	CredentialsInjectionEnv  CredentialsInjection = "Env"
+	CredentialsInjectionFile CredentialsInjection = "File"
+)
+
+// CredentialsSpec defines the admin and API credentials of bss-api
+type CredentialsSpec struct {
+	// SecretName references an existing Secret in the namespace of the
+	// BssCluster with the keys admin-username, admin-password and api-key.
+	// When unset, the operator generates the credentials into an owned Secret.
+	// +optional
+	SecretName string `json:"secretName,omitempty"`
+
+	// Injection selects whether the credentials are passed as environment
+	// variables or mounted as files
+	// +kubebuilder:default=Env
+	// +optional
+	Injection CredentialsInjection `json:"injection,omitempty"`
+
+	// RotateEvery regenerates operator-generated credentials periodically.
+	// Credentials can also be rotated on demand with the
+	// bss.localhost/rotate-credentials annotation.
+	// +optional
+	RotateEvery *metav1.Duration `json:"rotateEvery,omitempty"`
+}
+
+// UpgradeSpec defines how bss-api is upgraded between versions
+type UpgradeSpec struct {
+	// PreUpgrade is a Job run to completion before the new version is rolled
+	// out, such as a database migration. The upgrade fails, leaving the
+	// current version running, if the Job fails.
+	// +optional
+	PreUpgrade *UpgradeJobSpec `json:"preUpgrade,omitempty"`
+
+	// PostUpgrade is a Job run once the new version is rolled out, such as a
+	// smoke test. The upgrade is rolled back if the Job fails.
+	// +optional
+	PostUpgrade *UpgradeJobSpec `json:"postUpgrade,omitempty"`
+
+	// Timeout is how long the new version may take to become ready before
+	// the upgrade is rolled back
+	// +kubebuilder:default="10m"
+	// +optional
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
+}
+
+// UpgradeJobSpec defines a Job run during an upgrade. The Job runs the
+// bss-api container with its configuration, credentials and environment, plus
+// BSS_UPGRADE_FROM_VERSION and BSS_UPGRADE_TO_VERSION.
+type UpgradeJobSpec struct {
+	// Image overrides the image of the Job, which defaults to the bss-api
+	// image of the version being upgraded to
+	// +optional
+	Image string `json:"image,omitempty"`
+
+	// Command overrides the entrypoint of the image
+	// +optional
+	Command []string `json:"command,omitempty"`
+
+	// Args are the arguments to the entrypoint
+	// +optional
+	Args []string `json:"args,omitempty"`
+
+	// BackoffLimit is the number of retries before the Job is considered failed
+	// +kubebuilder:default=0
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	BackoffLimit *int32 `json:"backoffLimit,omitempty"`
+}
+
+// MaintenanceWindowSpec defines a weekly recurring maintenance window
+type MaintenanceWindowSpec struct {
+	// Days are the days of the week the window opens on. The window opens
+	// every day when empty.
+	// +listType=set
+	// +optional
+	Days []Weekday `json:"days,omitempty"`
+
+	// Start is the time of day the window opens, as HH:MM
+	// +kubebuilder:validation:Pattern=`^([01][0-9]|2[0-3]):[0-5][0-9]$`
+	Start string `json:"start"`
+
+	// Duration is how long the window stays open, at most a week
+	Duration metav1.Duration `json:"duration"`
+
+	// TimeZone is the IANA time zone of the start time, such as Europe/Paris
+	// +kubebuilder:default=UTC
+	// +optional
+	TimeZone string `json:"timeZone,omitempty"`
+}
+
+// Weekday is a day of the week
+// +kubebuilder:validation:Enum=Sunday;Monday;Tuesday;Wednesday;Thursday;Friday;Saturday
+type Weekday string
+
+// UpgradePhase is the step an upgrade of bss-api has reached
+// +kubebuilder:validation:Enum=PreUpgrade;RollingOut;PostUpgrade;RollingBack;Succeeded;Failed;RolledBack
+type UpgradePhase string
+
+const (
+	// UpgradePhasePreUpgrade runs the pre-upgrade Job
+	UpgradePhasePreUpgrade UpgradePhase = "PreUpgrade"
+	// UpgradePhaseRollingOut rolls out the new version and waits for it to become ready
+	UpgradePhaseRollingOut UpgradePhase = "RollingOut"
+	// UpgradePhasePostUpgrade runs the post-upgrade Job against the new version
+	UpgradePhasePostUpgrade UpgradePhase = "PostUpgrade"
+	// UpgradePhaseRollingBack rolls the previous version back out
+	UpgradePhaseRollingBack UpgradePhase = "RollingBack"
+	// UpgradePhaseSucceeded means the new version is running
+	UpgradePhaseSucceeded UpgradePhase = "Succeeded"
+	// UpgradePhaseFailed means the pre-upgrade Job failed before the new version was rolled out
+	UpgradePhaseFailed UpgradePhase = "Failed"
+	// UpgradePhaseRolledBack means the previous version is running again
+	UpgradePhaseRolledBack UpgradePhase = "RolledBack"
+)
+
+// WorkloadType is the kind of workload that runs bss-api
+// +kubebuilder:validation:Enum=Deployment;StatefulSet
+type WorkloadType string
+
+const (
+	WorkloadTypeDeployment  WorkloadType = "Deployment"
+	WorkloadTypeStatefulSet WorkloadType = "StatefulSet"
+)
+
+// StorageSpec defines the persistent volume claimed by each bss-api replica
+type StorageSpec struct {
+	// Size is the requested capacity of each volume. Volumes can be expanded
+	// if the storage class allows it, but never shrunk.
+	// +kubebuilder:default="1Gi"
+	// +optional
+	Size resource.Quantity `json:"size,omitempty"`
+
+	// StorageClassName is the storage class of the volumes. The cluster
+	// default is used when unset.
+	// +optional
+	StorageClassName *string `json:"storageClassName,omitempty"`
+
+	// AccessModes are the access modes of the volumes
+	// +kubebuilder:default={ReadWriteOnce}
+	// +optional
+	AccessModes []corev1.PersistentVolumeAccessMode `json:"accessModes,omitempty"`
+
+	// RetentionPolicy controls what happens to the volumes when the
+	// BssCluster is deleted or scaled down
+	// +optional
+	RetentionPolicy *StorageRetentionPolicy `json:"retentionPolicy,omitempty"`
+}
+
+// StorageRetentionPolicy controls the lifecycle of bss-api volumes
+type StorageRetentionPolicy struct {
+	// WhenDeleted controls whether volumes are deleted with the BssCluster
+	// +kubebuilder:validation:Enum=Retain;Delete
+	// +kubebuilder:default=Retain
+	// +optional
+	WhenDeleted appsv1.PersistentVolumeClaimRetentionPolicyType `json:"whenDeleted,omitempty"`
+
+	// WhenScaled controls whether volumes of removed replicas are deleted on scale down
+	// +kubebuilder:validation:Enum=Retain;Delete
+	// +kubebuilder:default=Retain
+	// +optional
+	WhenScaled appsv1.PersistentVolumeClaimRetentionPolicyType `json:"whenScaled,omitempty"`
 }
 
 // BssClusterStatus defines the observed state of BssCluster.
 type BssClusterStatus struct {
 	// Important: Run "make" to regenerate code after modifying this file
-	Phase string `json:"phase,omitempty"`
+
+	// Conditions represent the latest available observations of the BssCluster's state.
+	// Known condition types are Available, Progressing, Degraded, Upgrading and
+	// ReconcileError.
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+
+	// ObservedGeneration reflects the generation of the most recently observed BssCluster
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// Replicas is the number of bss-api pods of the serving workload
+	// +optional
+	Replicas int32 `json:"replicas,omitempty"`
+
+	// Selector is the label selector of the bss-api pods, used by the scale
+	// subresource
+	// +optional
+	Selector string `json:"selector,omitempty"`
+
+	// ReadyReplicas is the number of bss-api pods with a Ready condition
+	// +optional
+	ReadyReplicas int32 `json:"readyReplicas,omitempty"`
+
+	// UpdatedReplicas is the number of bss-api pods running the desired pod template
+	// +optional
+	UpdatedReplicas int32 `json:"updatedReplicas,omitempty"`
+
+	// Image is the bss-api image currently rolled out to all replicas
+	// +optional
+	Image string `json:"image,omitempty"`
+
+	// ImageID is the digest-qualified ID of the image that the bss-api
+	// containers are running, as reported by the kubelet
+	// +optional
+	ImageID string `json:"imageID,omitempty"`
+
+	// LastDriftCorrection records the last time fields of a child resource
+	// were changed outside the operator and reverted
+	// +optional
+	LastDriftCorrection *DriftCorrection `json:"lastDriftCorrection,omitempty"`
+
+	// Workload is the kind of workload currently serving bss-api. It lags
+	// spec.workload until a migration to the new workload has completed.
+	// +optional
+	Workload WorkloadType `json:"workload,omitempty"`
+
+	// Credentials reports the Secret holding the bss-api credentials and
+	// their last rotation
+	// +optional
+	Credentials *CredentialsStatus `json:"credentials,omitempty"`
+
+	// URL is the externally reachable GraphQL endpoint, set when spec.exposure is
+	// configured
+	// +optional
+	URL string `json:"url,omitempty"`
+
+	// CurrentVersion is the bss-api version the cluster runs outside of an
+	// upgrade, and is rolled back to if an upgrade fails
+	// +optional
+	CurrentVersion string `json:"currentVersion,omitempty"`
+
+	// TargetVersion is the bss-api version being upgraded to. It is empty
+	// when no upgrade is in progress.
+	// +optional
+	TargetVersion string `json:"targetVersion,omitempty"`
+
+	// Upgrade reports the progress of the current or last upgrade
+	// +optional
+	Upgrade *UpgradeStatus `json:"upgrade,omitempty"`
+}
+
+// UpgradeStatus defines the observed state of an upgrade of bss-api
+type UpgradeStatus struct {
+	// Phase is the step the upgrade has reached
+	Phase UpgradePhase `json:"phase"`
+
+	// FromVersion is the version the upgrade started from
+	FromVersion string `json:"fromVersion"`
+
+	// ToVersion is the version being upgraded to. A failed or rolled back
+	// upgrade is not retried until spec.version changes.
+	ToVersion string `json:"toVersion"`
+
+	// StartTime is when the upgrade started
+	StartTime metav1.Time `json:"startTime"`
+
+	// RolloutStartTime is when the new version started rolling out, from
+	// which the upgrade timeout is measured
+	// +optional
+	RolloutStartTime *metav1.Time `json:"rolloutStartTime,omitempty"`
+
+	// CompletionTime is when the upgrade succeeded, failed or was rolled back
+	// +optional
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+
+	// Message explains why an upgrade failed or was rolled back
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// CredentialsStatus defines the observed state of the bss-api credentials
+type CredentialsStatus struct {
+	// SecretName is the Secret the bss-api pods read their credentials from
+	SecretName string `json:"secretName"`
+
+	// LastRotationTime is when the credentials were last generated or rotated.
+	// Pods are rolled whenever it changes. For generated credentials it
+	// mirrors the bss.localhost/credentials-rotated-at annotation of their
+	// Secret.
+	// +optional
+	LastRotationTime *metav1.Time `json:"lastRotationTime,omitempty"`
+
+	// ObservedRotationRequest is the value of the
+	// bss.localhost/rotate-credentials annotation at the last rotation
+	// +optional
+	ObservedRotationRequest string `json:"observedRotationRequest,omitempty"`
+}
+
+// DriftCorrection describes child resource fields that drifted from the
+// desired state and were reverted
+type DriftCorrection struct {
+	// Kind is the kind of the corrected child resource
+	Kind string `json:"kind"`
+
+	// Name is the name of the corrected child resource
+	Name string `json:"name"`
+
+	// Fields are the paths of the fields that drifted
+	// +optional
+	Fields []string `json:"fields,omitempty"`
+
+	// Time is when the drift was corrected
+	Time metav1.Time `json:"time"`
 }
 
 // +kubebuilder:object:root=true
 // +kubebuilder:subresource:status
+// +kubebuilder:subresource:scale:specpath=.spec.replicas,statuspath=.status.replicas,selectorpath=.status.selector
+// +kubebuilder:printcolumn:name="Version",type=string,JSONPath=`.spec.version`
+// +kubebuilder:printcolumn:name="Upgrade",type=string,JSONPath=`.status.upgrade.phase`,priority=1
+// +kubebuilder:printcolumn:name="Workload",type=string,JSONPath=`.status.workload`
+// +kubebuilder:printcolumn:name="Ready",type=integer,JSONPath=`.status.readyReplicas`
+// +kubebuilder:printcolumn:name="Available",type=string,JSONPath=`.status.conditions[?(@.type=="Available")].status`
+// +kubebuilder:printcolumn:name="URL",type=string,JSONPath=`.status.url`,priority=1
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
 
 // BssCluster is the Schema for the bssclusters API.
 type BssCluster struct {
@@ -17,14 +17,28 @@ limitations under the License.
 package v1alpha1
 
 import (
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 )
 
 // BSSQuerySpec defines the desired state of BSSQuery
 type BSSQuerySpec struct {
-	// APIEndpoint is the URL of the BSS API GraphQL endpoint
-	// +kubebuilder:validation:Required
-	APIEndpoint string `json:"apiEndpoint"`
+	// APIEndpoint is the URL of the BSS API GraphQL endpoint. Exactly one of
+	// apiEndpoint and clusterRef must be set.
+	// +optional
+	APIEndpoint string `json:"apiEndpoint,omitempty"`
+
+	// ClusterRef queries the bss-api of a BssCluster managed by this operator
+	// through its Service. The query waits until the BssCluster is available.
+	// +optional
+	ClusterRef *BssClusterReference `json:"clusterRef,omitempty"`
+
+	// Auth authenticates the requests sent to the BSS API and secures their
+	// connection. Changes to the referenced Secrets and ConfigMaps are
+	// picked up.
+	// +optional
+	Auth *QueryAuthSpec `json:"auth,omitempty"`
 
 	// Query specifies what to query from the BSS API
 	// +kubebuilder:validation:Required
@@ -34,31 +48,251 @@ type BSSQuerySpec struct {
 	// +optional
 	ClusterID string `json:"clusterID,omitempty"`
 
+	// GraphQL is the GraphQL document run by graphql queries
+	// +optional
+	GraphQL *GraphQLQuerySpec `json:"graphql,omitempty"`
+
 	// RefreshInterval defines how often to refresh the query results (in seconds)
 	// +kubebuilder:default=30
 	// +optional
 	RefreshInterval int32 `json:"refreshInterval,omitempty"`
+
+	// Paused stops polling the BSS API until it is unset. The last result is
+	// kept. The bss.localhost/paused annotation pauses the query too.
+	// +optional
+	Paused bool `json:"paused,omitempty"`
+
+	// Output extracts fields of the result into a ConfigMap or Secret, or
+	// annotations on another object. The result is then no longer kept in
+	// status, only its digest and size.
+	// +optional
+	Output *QueryOutputSpec `json:"output,omitempty"`
+}
+
+// QueryOutputSpec defines the fields extracted from the result of a BSSQuery
+// and where they are written. Exactly one of configMap, secret and
+// annotations must be set.
+type QueryOutputSpec struct {
+	// Fields are the values extracted from the result
+	// +kubebuilder:validation:MinItems=1
+	// +listType=map
+	// +listMapKey=name
+	Fields []QueryOutputField `json:"fields"`
+
+	// ConfigMap writes the fields as the keys of a ConfigMap owned by the
+	// BSSQuery
+	// +optional
+	ConfigMap *QueryOutputObject `json:"configMap,omitempty"`
+
+	// Secret writes the fields as the keys of a Secret owned by the BSSQuery
+	// +optional
+	Secret *QueryOutputObject `json:"secret,omitempty"`
+
+	// Annotations writes the fields as annotations of an existing object in
+	// the namespace of the BSSQuery. The operator must be allowed to patch it.
+	// +optional
+	Annotations *QueryOutputAnnotations `json:"annotations,omitempty"`
+}
+
+// QueryOutputField extracts one value from the result. Exactly one of
+// jsonPath and expression must be set. Strings are written as they are,
+// other values as JSON.
+type QueryOutputField struct {
+	// Name is the key the value is written to, or the name of the annotation
+	// after the prefix
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	// JSONPath selects the value with a kubectl-style JSONPath expression
+	// such as {.cluster.name}
+	// +optional
+	JSONPath string `json:"jsonPath,omitempty"`
+
+	// Expression computes the value with a CEL expression over the result,
+	// which is bound to the variable result
+	// +optional
+	Expression string `json:"expression,omitempty"`
+}
+
+// QueryOutputObject names the ConfigMap or Secret the fields are written to
+type QueryOutputObject struct {
+	// Name is the name of the object, which defaults to the name of the
+	// BSSQuery
+	// +optional
+	Name string `json:"name,omitempty"`
+}
+
+const (
+	// QueryOutputAnnotationDomain is the domain the annotation keys of
+	// BSSQuery outputs belong to
+	QueryOutputAnnotationDomain = "query.bss.localhost"
+
+	// DefaultQueryOutputAnnotationPrefix is the prefix of the annotations of
+	// a BSSQuery output that sets none
+	DefaultQueryOutputAnnotationPrefix = QueryOutputAnnotationDomain + "/"
+)
+
+// QueryOutputAnnotations defines the object annotated with the fields
+type QueryOutputAnnotations struct {
+	// TargetRef is the object annotated with the fields: a Deployment or
+	// StatefulSet of apps/v1, or a Service, ConfigMap or Secret of v1
+	TargetRef QueryOutputTargetReference `json:"targetRef"`
+
+	// Prefix is prepended to the name of every field to form the annotation
+	// key. It must be query.bss.localhost/ or a subdomain of it, such as
+	// team.query.bss.localhost/, so the annotations cannot configure other
+	// controllers.
+	// +kubebuilder:default="query.bss.localhost/"
+	// +optional
+	Prefix string `json:"prefix,omitempty"`
+}
+
+// QueryOutputTargetReference refers to an object in the namespace of the
+// BSSQuery
+type QueryOutputTargetReference struct {
+	// APIVersion is the API version of the object, such as apps/v1
+	// +kubebuilder:validation:MinLength=1
+	APIVersion string `json:"apiVersion"`
+
+	// Kind is the kind of the object, such as Deployment
+	// +kubebuilder:validation:MinLength=1
+	Kind string `json:"kind"`
+
+	// Name is the name of the object
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+}
+
+// BssClusterReference refers to a BssCluster
+type BssClusterReference struct {
+	// Name is the name of the BssCluster
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	// Namespace is the namespace of the BssCluster, which defaults to the
+	// namespace of the BSSQuery
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+}
+
+// QueryAuthSpec defines the credentials, headers and TLS settings of the
+// requests sent to the BSS API. At most one of bearerTokenSecretRef and
+// basicAuthSecretRef can be set.
+type QueryAuthSpec struct {
+	// BearerTokenSecretRef reads the bearer token sent in the Authorization
+	// header from a key of a Secret in the namespace of the BSSQuery
+	// +optional
+	BearerTokenSecretRef *corev1.SecretKeySelector `json:"bearerTokenSecretRef,omitempty"`
+
+	// BasicAuthSecretRef reads the username and password keys of a Secret of
+	// type kubernetes.io/basic-auth in the namespace of the BSSQuery
+	// +optional
+	BasicAuthSecretRef *corev1.LocalObjectReference `json:"basicAuthSecretRef,omitempty"`
+
+	// Headers are sent with every request. The Authorization header cannot
+	// be combined with a bearer token or basic auth.
+	// +optional
+	Headers map[string]string `json:"headers,omitempty"`
+
+	// TLS configures the TLS connection to an https apiEndpoint
+	// +optional
+	TLS *QueryTLSSpec `json:"tls,omitempty"`
+}
+
+// QueryTLSSpec defines how the BSS API is verified and how the query
+// authenticates to it with a client certificate
+type QueryTLSSpec struct {
+	// CA is the PEM bundle of the certificate authorities the server
+	// certificate is verified with, instead of the system roots
+	// +optional
+	CA *CABundleSource `json:"ca,omitempty"`
+
+	// ClientCertSecretRef names a Secret of type kubernetes.io/tls in the
+	// namespace of the BSSQuery whose tls.crt and tls.key are presented to
+	// the server
+	// +optional
+	ClientCertSecretRef *corev1.LocalObjectReference `json:"clientCertSecretRef,omitempty"`
+
+	// ServerName is the name the server certificate is verified against,
+	// which defaults to the host of the apiEndpoint
+	// +optional
+	ServerName string `json:"serverName,omitempty"`
+}
+
+// CABundleSource reads a PEM bundle from a key of a ConfigMap or Secret in
+// the namespace of the BSSQuery. Exactly one of configMapKeyRef and
+// secretKeyRef must be set.
+type CABundleSource struct {
+	// ConfigMapKeyRef reads the bundle from a ConfigMap
+	// +optional
+	ConfigMapKeyRef *corev1.ConfigMapKeySelector `json:"configMapKeyRef,omitempty"`
+
+	// SecretKeyRef reads the bundle from a Secret
+	// +optional
+	SecretKeyRef *corev1.SecretKeySelector `json:"secretKeyRef,omitempty"`
+}
+
+// GraphQLQuerySpec defines a GraphQL document, the operation to run from it
+// and its variables
+type GraphQLQuerySpec struct {
+	// Document is the GraphQL document. Exactly one of document and
+	// documentFrom must be set.
+	// +optional
+	Document string `json:"document,omitempty"`
+
+	// DocumentFrom reads the GraphQL document from a key of a ConfigMap in the
+	// namespace of the BSSQuery. Changes to the ConfigMap are picked up.
+	// +optional
+	DocumentFrom *corev1.ConfigMapKeySelector `json:"documentFrom,omitempty"`
+
+	// OperationName selects the operation to run when the document has
+	// several. Only query operations can be run.
+	// +optional
+	OperationName string `json:"operationName,omitempty"`
+
+	// Variables are the variables of the operation, as a JSON object
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// +optional
+	Variables *runtime.RawExtension `json:"variables,omitempty"`
 }
 
 // BSSQueryType defines the type of query to execute
-// +kubebuilder:validation:Enum=cluster;clusters
+// +kubebuilder:validation:Enum=cluster;clusters;graphql
 type BSSQueryType string
 
 const (
 	QueryTypeCluster  BSSQueryType = "cluster"
 	QueryTypeClusters BSSQueryType = "clusters"
+
+	// QueryTypeGraphQL runs the document of spec.graphql once it has been
+	// validated against the schema of the BSS API
+	QueryTypeGraphQL BSSQueryType = "graphql"
 )
 
 // BSSQueryStatus defines the observed state of BSSQuery
 type BSSQueryStatus struct {
+	// Endpoint is the GraphQL endpoint the query is sent to, resolved from
+	// spec.clusterRef or copied from spec.apiEndpoint
+	// +optional
+	Endpoint string `json:"endpoint,omitempty"`
+
 	// LastQueryTime is the timestamp of the last successful query
 	// +optional
 	LastQueryTime *metav1.Time `json:"lastQueryTime,omitempty"`
 
-	// Result contains the JSON result from the GraphQL query
+	// Result contains the JSON result from the GraphQL query. It is not kept
+	// when spec.output is set.
 	// +optional
 	Result string `json:"result,omitempty"`
 
+	// ResultDigest is the SHA-256 digest of the last result
+	// +optional
+	ResultDigest string `json:"resultDigest,omitempty"`
+
+	// ResultSize is the size of the last result in bytes
+	// +optional
+	ResultSize int `json:"resultSize,omitempty"`
+
 	// ClusterCount is the number of clusters returned (for list queries)
 	// +optional
 	ClusterCount int `json:"clusterCount,omitempty"`
@@ -76,7 +310,7 @@ type BSSQueryStatus struct {
 // +kubebuilder:subresource:status
 // +kubebuilder:resource:shortName=bssq
 // +kubebuilder:printcolumn:name="Query Type",type=string,JSONPath=`.spec.query`
-// +kubebuilder:printcolumn:name="Endpoint",type=string,JSONPath=`.spec.apiEndpoint`
+// +kubebuilder:printcolumn:name="Endpoint",type=string,JSONPath=`.status.endpoint`
 // +kubebuilder:printcolumn:name="Last Query",type=date,JSONPath=`.status.lastQueryTime`
 // +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
 
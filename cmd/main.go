@@ -26,7 +26,9 @@ import (
 	// to ensure that exec-entrypoint and run can make use of them.
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 
+	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -36,9 +38,13 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/metrics/filters"
 	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
 
 	bssv1alpha1 "github.com/brmorris/bss-operator/api/v1alpha1"
+	bssclient "github.com/brmorris/bss-operator/internal/client"
 	"github.com/brmorris/bss-operator/internal/controller"
+	"github.com/brmorris/bss-operator/internal/hooks"
+	webhookv1alpha1 "github.com/brmorris/bss-operator/internal/webhook/v1alpha1"
 	// +kubebuilder:scaffold:imports
 )
 
@@ -50,6 +56,8 @@ var (
 func init() {
 	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
 
+	utilruntime.Must(gatewayv1.Install(scheme))
+
 	utilruntime.Must(bssv1alpha1.AddToScheme(scheme))
 	// +kubebuilder:scaffold:scheme
 }
@@ -63,6 +71,10 @@ func main() {
 	var probeAddr string
 	var secureMetrics bool
 	var enableHTTP2 bool
+	var bssAPIEndpoint string
+	var imageRegistry string
+	var operatorNamespace string
+	var queryLimits bssclient.EndpointLimits
 	var tlsOpts []func(*tls.Config)
 	flag.StringVar(&metricsAddr, "metrics-bind-address", "0", "The address the metrics endpoint binds to. "+
 		"Use :8443 for HTTPS or :8080 for HTTP, or leave as 0 to disable the metrics service.")
@@ -81,6 +93,28 @@ func main() {
 	flag.StringVar(&metricsCertKey, "metrics-cert-key", "tls.key", "The name of the metrics server key file.")
 	flag.BoolVar(&enableHTTP2, "enable-http2", false,
 		"If set, HTTP/2 will be enabled for the metrics and webhook servers")
+	flag.StringVar(&bssAPIEndpoint, "bss-api-endpoint", "",
+		"The bss-api GraphQL endpoint used to deregister remote clusters when a BssCluster is deleted. "+
+			"Leave empty to disable deregistration.")
+	flag.StringVar(&imageRegistry, "image-registry", os.Getenv("BSS_IMAGE_REGISTRY"),
+		"The registry bss-api images are pulled from when a BssCluster does not set spec.image.repository. "+
+			"Defaults to the BSS_IMAGE_REGISTRY environment variable.")
+	flag.StringVar(&operatorNamespace, "operator-namespace", os.Getenv("POD_NAMESPACE"),
+		"The namespace the operator runs in, allowed by the NetworkPolicies of BssClusters so BSSQuery can poll bss-api. "+
+			"Defaults to the POD_NAMESPACE environment variable.")
+	flag.Float64Var(&queryLimits.QPS, "bss-query-qps", bssclient.DefaultEndpointLimits.QPS,
+		"The rate of requests BSSQueries send to each bss-api endpoint. Set to 0 to disable rate limiting.")
+	flag.IntVar(&queryLimits.Burst, "bss-query-burst", bssclient.DefaultEndpointLimits.Burst,
+		"The number of requests BSSQueries send at once to each bss-api endpoint before --bss-query-qps applies.")
+	flag.IntVar(&queryLimits.FailureThreshold, "bss-query-failure-threshold",
+		bssclient.DefaultEndpointLimits.FailureThreshold,
+		"The number of consecutive failures after which requests to a bss-api endpoint stop until its circuit "+
+			"breaker timeout. Set to 0 to disable the circuit breaker.")
+	flag.DurationVar(&queryLimits.OpenTimeout, "bss-query-breaker-timeout", bssclient.DefaultEndpointLimits.OpenTimeout,
+		"How long requests to a failing bss-api endpoint first stop. It doubles every time the endpoint still fails.")
+	flag.DurationVar(&queryLimits.MaxOpenTimeout, "bss-query-breaker-max-timeout",
+		bssclient.DefaultEndpointLimits.MaxOpenTimeout,
+		"The longest requests to a failing bss-api endpoint stop.")
 	opts := zap.Options{
 		Development: true,
 	}
@@ -202,22 +236,64 @@ func main() {
 		os.Exit(1)
 	}
 
+	// Hooks run before the children of a deleted BssCluster are torn down
+	bssClusterRecorder := mgr.GetEventRecorderFor("bsscluster-controller")
+	var preDeleteHooks []controller.PreDeleteHook
+	if bssAPIEndpoint != "" {
+		setupLog.Info("Deregistering deleted BssClusters from bss-api", "bss-api-endpoint", bssAPIEndpoint)
+		preDeleteHooks = append(preDeleteHooks, hooks.NewBSSAPIDeregistration(bssAPIEndpoint, bssClusterRecorder))
+	}
+
+	bssClusterOpts := []controller.BssClusterOption{
+		controller.WithPreDeleteHooks(preDeleteHooks...),
+		controller.WithImageRegistry(imageRegistry),
+		controller.WithOperatorNamespace(operatorNamespace),
+		controller.WithEventRecorder(bssClusterRecorder),
+	}
+
+	// HTTPRoutes are only managed, and watched, when the Gateway API is installed
+	_, err = mgr.GetRESTMapper().RESTMapping(
+		schema.GroupKind{Group: gatewayv1.GroupName, Kind: "HTTPRoute"}, gatewayv1.GroupVersion.Version)
+	switch {
+	case err == nil:
+		setupLog.Info("Gateway API found, enabling HTTPRoute exposure")
+		bssClusterOpts = append(bssClusterOpts, controller.WithGatewayAPI())
+	case meta.IsNoMatchError(err):
+		setupLog.Info("Gateway API not installed, HTTPRoute exposure is disabled")
+	default:
+		setupLog.Error(err, "unable to discover the Gateway API")
+		os.Exit(1)
+	}
+
 	// Initialize the controller with all dependencies
 	if err := controller.NewBssClusterReconciler(
 		mgr.GetClient(),
 		mgr.GetScheme(),
+		bssClusterOpts...,
 	).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "BssCluster")
 		os.Exit(1)
 	}
 
 	if err = (&controller.BSSQueryReconciler{
-		Client: mgr.GetClient(),
-		Scheme: mgr.GetScheme(),
+		Client:  mgr.GetClient(),
+		Scheme:  mgr.GetScheme(),
+		Clients: bssclient.NewRegistry(bssclient.DefaultIdleTimeout, queryLimits),
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "BSSQuery")
 		os.Exit(1)
 	}
+	// nolint:goconst
+	if os.Getenv("ENABLE_WEBHOOKS") != "false" {
+		if err = webhookv1alpha1.SetupBssClusterWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "BssCluster")
+			os.Exit(1)
+		}
+		if err = webhookv1alpha1.SetupBSSQueryWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "BSSQuery")
+			os.Exit(1)
+		}
+	}
 	// +kubebuilder:scaffold:builder
 
 	if metricsCertWatcher != nil {
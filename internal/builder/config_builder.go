@@ -0,0 +1,119 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package builder
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+
+	bssv1alpha1 "github.com/brmorris/bss-operator/api/v1alpha1"
+)
+
+const (
+	// ConfigFileName is the key of the configuration file in the ConfigMap
+	ConfigFileName = "config.yaml"
+
+	// ConfigMountPath is the directory the ConfigMap is mounted at
+	ConfigMountPath = "/etc/bss-api"
+
+	// ConfigEnvVar tells bss-api where to find its configuration file
+	ConfigEnvVar = "BSS_API_CONFIG"
+
+	configVolumeName = "config"
+)
+
+// ConfigMapName returns the name of the ConfigMap holding the bss-api
+// configuration of a BssCluster
+func ConfigMapName(bssCluster *bssv1alpha1.BssCluster) string {
+	return bssCluster.Name + "-config"
+}
+
+// RenderConfig renders spec.config into the body of the configuration file.
+// Settings are marshalled with sorted keys so the output is stable.
+func RenderConfig(bssCluster *bssv1alpha1.BssCluster) string {
+	config := bssCluster.Spec.Config
+	if config == nil {
+		return ""
+	}
+	if config.Raw != "" {
+		return config.Raw
+	}
+	if len(config.Settings) == 0 {
+		return ""
+	}
+	// A map of strings always marshals
+	body, _ := yaml.Marshal(config.Settings)
+	return string(body)
+}
+
+// ConfigHash returns the hash of the rendered configuration file that is
+// stamped on the pod template
+func ConfigHash(bssCluster *bssv1alpha1.BssCluster) string {
+	sum := sha256.Sum256([]byte(RenderConfig(bssCluster)))
+	return hex.EncodeToString(sum[:])
+}
+
+// ConfigMapBuilder builds the ConfigMap holding the bss-api configuration
+type ConfigMapBuilder struct {
+	bssCluster *bssv1alpha1.BssCluster
+}
+
+// NewConfigMapBuilder creates a new ConfigMapBuilder
+func NewConfigMapBuilder(bssCluster *bssv1alpha1.BssCluster) *ConfigMapBuilder {
+	return &ConfigMapBuilder{
+		bssCluster: bssCluster,
+	}
+}
+
+// Build constructs the ConfigMap
+func (b *ConfigMapBuilder) Build() *corev1.ConfigMap {
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      ConfigMapName(b.bssCluster),
+			Namespace: b.bssCluster.Namespace,
+			Labels:    CommonLabels(b.bssCluster),
+		},
+		Data: map[string]string{
+			ConfigFileName: RenderConfig(b.bssCluster),
+		},
+	}
+}
+
+// configVolume returns the pod volume projecting the ConfigMap
+func configVolume(bssCluster *bssv1alpha1.BssCluster) corev1.Volume {
+	return corev1.Volume{
+		Name: configVolumeName,
+		VolumeSource: corev1.VolumeSource{
+			ConfigMap: &corev1.ConfigMapVolumeSource{
+				LocalObjectReference: corev1.LocalObjectReference{Name: ConfigMapName(bssCluster)},
+			},
+		},
+	}
+}
+
+// configVolumeMount returns the read-only mount of the configuration volume
+func configVolumeMount() corev1.VolumeMount {
+	return corev1.VolumeMount{
+		Name:      configVolumeName,
+		MountPath: ConfigMountPath,
+		ReadOnly:  true,
+	}
+}
@@ -0,0 +1,169 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package builder
+
+import (
+	"crypto/rand"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	bssv1alpha1 "github.com/brmorris/bss-operator/api/v1alpha1"
+)
+
+const (
+	// Keys of the bss-api credentials in the credentials Secret
+	CredentialsAdminUsernameKey = "admin-username"
+	CredentialsAdminPasswordKey = "admin-password"
+	CredentialsAPIKeyKey        = "api-key"
+
+	// CredentialsMountPath is the directory credentials are mounted at when
+	// injected as files
+	CredentialsMountPath = "/var/run/secrets/bss-api"
+
+	// defaultAdminUsername is the admin user of generated credentials
+	defaultAdminUsername = "admin"
+
+	credentialsVolumeName = "credentials"
+)
+
+// CredentialsKeys are the keys every credentials Secret must provide
+var CredentialsKeys = []string{CredentialsAdminUsernameKey, CredentialsAdminPasswordKey, CredentialsAPIKeyKey}
+
+// credentialsEnvVars maps the credentials keys to the environment variables
+// bss-api reads them from
+var credentialsEnvVars = map[string]string{
+	CredentialsAdminUsernameKey: "BSS_ADMIN_USERNAME",
+	CredentialsAdminPasswordKey: "BSS_ADMIN_PASSWORD",
+	CredentialsAPIKeyKey:        "BSS_API_KEY",
+}
+
+// GeneratedCredentialsSecretName returns the name of the Secret the operator
+// generates credentials into for a BssCluster
+func GeneratedCredentialsSecretName(bssCluster *bssv1alpha1.BssCluster) string {
+	return bssCluster.Name + "-credentials"
+}
+
+// CredentialsSecretName returns the name of the Secret the bss-api pods read
+// their credentials from, or an empty string if credentials are not configured
+func CredentialsSecretName(bssCluster *bssv1alpha1.BssCluster) string {
+	credentials := bssCluster.Spec.Credentials
+	switch {
+	case credentials == nil:
+		return ""
+	case credentials.SecretName != "":
+		return credentials.SecretName
+	default:
+		return GeneratedCredentialsSecretName(bssCluster)
+	}
+}
+
+// GeneratesCredentials reports whether the operator generates the
+// credentials of a BssCluster
+func GeneratesCredentials(bssCluster *bssv1alpha1.BssCluster) bool {
+	return bssCluster.Spec.Credentials != nil && bssCluster.Spec.Credentials.SecretName == ""
+}
+
+// credentialsRotatedAt returns the last rotation time recorded in status,
+// formatted for the rotation annotations
+func credentialsRotatedAt(bssCluster *bssv1alpha1.BssCluster) string {
+	status := bssCluster.Status.Credentials
+	if status == nil || status.LastRotationTime == nil {
+		return ""
+	}
+	return status.LastRotationTime.UTC().Format(time.RFC3339)
+}
+
+// CredentialsSecretBuilder builds the Secret holding generated credentials
+type CredentialsSecretBuilder struct {
+	bssCluster *bssv1alpha1.BssCluster
+}
+
+// NewCredentialsSecretBuilder creates a new CredentialsSecretBuilder
+func NewCredentialsSecretBuilder(bssCluster *bssv1alpha1.BssCluster) *CredentialsSecretBuilder {
+	return &CredentialsSecretBuilder{
+		bssCluster: bssCluster,
+	}
+}
+
+// Build constructs the Secret with freshly generated credentials. The values
+// of an existing Secret are carried over until the rotation time stamped on
+// it changes, see resources.MutateCredentialsSecret. The Secret records the
+// last rotation, so a rotation is not repeated if status is not written.
+func (b *CredentialsSecretBuilder) Build() *corev1.Secret {
+	annotations := map[string]string{
+		bssv1alpha1.AnnotationCredentialsRotatedAt: credentialsRotatedAt(b.bssCluster),
+	}
+	if status := b.bssCluster.Status.Credentials; status != nil {
+		annotations[bssv1alpha1.AnnotationCredentialsRotationRequest] = status.ObservedRotationRequest
+	}
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        GeneratedCredentialsSecretName(b.bssCluster),
+			Namespace:   b.bssCluster.Namespace,
+			Labels:      CommonLabels(b.bssCluster),
+			Annotations: annotations,
+		},
+		Type: corev1.SecretTypeOpaque,
+		Data: map[string][]byte{
+			CredentialsAdminUsernameKey: []byte(defaultAdminUsername),
+			CredentialsAdminPasswordKey: []byte(rand.Text()),
+			CredentialsAPIKeyKey:        []byte(rand.Text() + rand.Text()),
+		},
+	}
+}
+
+// credentialsEnv returns the environment variables that read the
+// credentials from their Secret
+func credentialsEnv(bssCluster *bssv1alpha1.BssCluster) []corev1.EnvVar {
+	secretName := CredentialsSecretName(bssCluster)
+	env := make([]corev1.EnvVar, 0, len(CredentialsKeys))
+	for _, key := range CredentialsKeys {
+		env = append(env, corev1.EnvVar{
+			Name: credentialsEnvVars[key],
+			ValueFrom: &corev1.EnvVarSource{
+				SecretKeyRef: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: secretName},
+					Key:                  key,
+				},
+			},
+		})
+	}
+	return env
+}
+
+// credentialsVolume returns the pod volume projecting the credentials Secret
+func credentialsVolume(bssCluster *bssv1alpha1.BssCluster) corev1.Volume {
+	return corev1.Volume{
+		Name: credentialsVolumeName,
+		VolumeSource: corev1.VolumeSource{
+			Secret: &corev1.SecretVolumeSource{
+				SecretName: CredentialsSecretName(bssCluster),
+			},
+		},
+	}
+}
+
+// credentialsVolumeMount returns the read-only mount of the credentials volume
+func credentialsVolumeMount() corev1.VolumeMount {
+	return corev1.VolumeMount{
+		Name:      credentialsVolumeName,
+		MountPath: CredentialsMountPath,
+		ReadOnly:  true,
+	}
+}
@@ -18,7 +18,6 @@ package builder
 
 import (
 	appsv1 "k8s.io/api/apps/v1"
-	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	bssv1alpha1 "github.com/brmorris/bss-operator/api/v1alpha1"
@@ -26,7 +25,8 @@ import (
 
 // DeploymentBuilder builds a Deployment for a BssCluster
 type DeploymentBuilder struct {
-	bssCluster *bssv1alpha1.BssCluster
+	bssCluster    *bssv1alpha1.BssCluster
+	imageResolver ImageResolver
 }
 
 // NewDeploymentBuilder creates a new DeploymentBuilder
@@ -36,11 +36,18 @@ func NewDeploymentBuilder(bssCluster *bssv1alpha1.BssCluster) *DeploymentBuilder
 	}
 }
 
+// WithImageResolver sets the resolver of the bss-api image reference
+func (b *DeploymentBuilder) WithImageResolver(imageResolver ImageResolver) *DeploymentBuilder {
+	b.imageResolver = imageResolver
+	return b
+}
+
 // Build constructs the Deployment for bss-api
 func (b *DeploymentBuilder) Build() *appsv1.Deployment {
-	replicas := b.getReplicas()
+	replicas := Replicas(b.bssCluster)
 	labels := CommonLabels(b.bssCluster)
 	selectorLabels := SelectorLabels(b.bssCluster)
+	template := NewPodTemplateBuilder(b.bssCluster).WithImageResolver(b.imageResolver).Build()
 
 	return &appsv1.Deployment{
 		ObjectMeta: metav1.ObjectMeta{
@@ -53,41 +60,7 @@ func (b *DeploymentBuilder) Build() *appsv1.Deployment {
 			Selector: &metav1.LabelSelector{
 				MatchLabels: selectorLabels,
 			},
-			Template: corev1.PodTemplateSpec{
-				ObjectMeta: metav1.ObjectMeta{
-					Labels: labels,
-				},
-				Spec: b.buildPodSpec(),
-			},
+			Template: template,
 		},
 	}
 }
-
-func (b *DeploymentBuilder) buildPodSpec() corev1.PodSpec {
-	return corev1.PodSpec{
-		Containers: []corev1.Container{
-			{
-				Name:  "bss-api",
-				Image: b.getImage(),
-				Ports: []corev1.ContainerPort{
-					{
-						Name:          "http",
-						ContainerPort: 8880,
-						Protocol:      corev1.ProtocolTCP,
-					},
-				},
-			},
-		},
-	}
-}
-
-func (b *DeploymentBuilder) getReplicas() int32 {
-	if b.bssCluster.Spec.Replicas != nil {
-		return *b.bssCluster.Spec.Replicas
-	}
-	return 1
-}
-
-func (b *DeploymentBuilder) getImage() string {
-	return "bss-api:" + b.bssCluster.Spec.Version
-}
@@ -0,0 +1,185 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package builder
+
+import (
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+
+	bssv1alpha1 "github.com/brmorris/bss-operator/api/v1alpha1"
+)
+
+// Exposure returns the kind of resource that exposes a BssCluster outside the
+// cluster, or an empty type if it is not exposed
+func Exposure(bssCluster *bssv1alpha1.BssCluster) bssv1alpha1.ExposureType {
+	exposure := bssCluster.Spec.Exposure
+	if exposure == nil {
+		return ""
+	}
+	if exposure.Type == "" {
+		return bssv1alpha1.ExposureTypeIngress
+	}
+	return exposure.Type
+}
+
+// exposurePath returns the path prefix routed to bss-api
+func exposurePath(exposure *bssv1alpha1.ExposureSpec) string {
+	if exposure.Path == "" {
+		return GraphQLPath
+	}
+	return exposure.Path
+}
+
+// GraphQLURL returns the externally reachable GraphQL endpoint of an exposed
+// BssCluster, or an empty string if it is not exposed
+func GraphQLURL(bssCluster *bssv1alpha1.BssCluster) string {
+	exposure := bssCluster.Spec.Exposure
+	if exposure == nil {
+		return ""
+	}
+	scheme := "http"
+	if exposure.TLS != nil {
+		scheme = "https"
+	}
+	return scheme + "://" + exposure.Host + exposurePath(exposure)
+}
+
+// IngressBuilder builds an Ingress for a BssCluster
+type IngressBuilder struct {
+	bssCluster *bssv1alpha1.BssCluster
+}
+
+// NewIngressBuilder creates a new IngressBuilder
+func NewIngressBuilder(bssCluster *bssv1alpha1.BssCluster) *IngressBuilder {
+	return &IngressBuilder{
+		bssCluster: bssCluster,
+	}
+}
+
+// Build constructs the Ingress that routes the exposed host and path to the
+// http port of the Service
+func (b *IngressBuilder) Build() *networkingv1.Ingress {
+	exposure := b.bssCluster.Spec.Exposure
+	if exposure == nil {
+		exposure = &bssv1alpha1.ExposureSpec{}
+	}
+
+	ingress := &networkingv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        b.bssCluster.Name,
+			Namespace:   b.bssCluster.Namespace,
+			Labels:      CommonLabels(b.bssCluster),
+			Annotations: exposure.Annotations,
+		},
+		Spec: networkingv1.IngressSpec{
+			IngressClassName: exposure.IngressClassName,
+			Rules: []networkingv1.IngressRule{
+				{
+					Host: exposure.Host,
+					IngressRuleValue: networkingv1.IngressRuleValue{
+						HTTP: &networkingv1.HTTPIngressRuleValue{
+							Paths: []networkingv1.HTTPIngressPath{
+								{
+									Path:     exposurePath(exposure),
+									PathType: ptr.To(networkingv1.PathTypePrefix),
+									Backend: networkingv1.IngressBackend{
+										Service: &networkingv1.IngressServiceBackend{
+											Name: b.bssCluster.Name,
+											Port: networkingv1.ServiceBackendPort{Name: HTTPPortName},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	if exposure.TLS != nil {
+		ingress.Spec.TLS = []networkingv1.IngressTLS{
+			{
+				Hosts:      []string{exposure.Host},
+				SecretName: exposure.TLS.SecretName,
+			},
+		}
+	}
+
+	return ingress
+}
+
+// HTTPRouteBuilder builds a Gateway API HTTPRoute for a BssCluster
+type HTTPRouteBuilder struct {
+	bssCluster *bssv1alpha1.BssCluster
+}
+
+// NewHTTPRouteBuilder creates a new HTTPRouteBuilder
+func NewHTTPRouteBuilder(bssCluster *bssv1alpha1.BssCluster) *HTTPRouteBuilder {
+	return &HTTPRouteBuilder{
+		bssCluster: bssCluster,
+	}
+}
+
+// Build constructs the HTTPRoute that attaches the exposed host and path to
+// the parent Gateways and forwards it to the Service. TLS is terminated by
+// the Gateway listener.
+func (b *HTTPRouteBuilder) Build() *gatewayv1.HTTPRoute {
+	exposure := b.bssCluster.Spec.Exposure
+	if exposure == nil {
+		exposure = &bssv1alpha1.ExposureSpec{}
+	}
+
+	return &gatewayv1.HTTPRoute{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        b.bssCluster.Name,
+			Namespace:   b.bssCluster.Namespace,
+			Labels:      CommonLabels(b.bssCluster),
+			Annotations: exposure.Annotations,
+		},
+		Spec: gatewayv1.HTTPRouteSpec{
+			CommonRouteSpec: gatewayv1.CommonRouteSpec{
+				ParentRefs: exposure.ParentRefs,
+			},
+			Hostnames: []gatewayv1.Hostname{gatewayv1.Hostname(exposure.Host)},
+			Rules: []gatewayv1.HTTPRouteRule{
+				{
+					Matches: []gatewayv1.HTTPRouteMatch{
+						{
+							Path: &gatewayv1.HTTPPathMatch{
+								Type:  ptr.To(gatewayv1.PathMatchPathPrefix),
+								Value: ptr.To(exposurePath(exposure)),
+							},
+						},
+					},
+					BackendRefs: []gatewayv1.HTTPBackendRef{
+						{
+							BackendRef: gatewayv1.BackendRef{
+								BackendObjectReference: gatewayv1.BackendObjectReference{
+									Name: gatewayv1.ObjectName(b.bssCluster.Name),
+									Port: ptr.To(gatewayv1.PortNumber(ServicePort(b.bssCluster))),
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
@@ -0,0 +1,97 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package builder
+
+import (
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+
+	bssv1alpha1 "github.com/brmorris/bss-operator/api/v1alpha1"
+)
+
+// defaultTargetCPUUtilization is the CPU target used when autoscaling sets no target
+const defaultTargetCPUUtilization = 80
+
+// HorizontalPodAutoscalerBuilder builds a HorizontalPodAutoscaler for a BssCluster
+type HorizontalPodAutoscalerBuilder struct {
+	bssCluster *bssv1alpha1.BssCluster
+}
+
+// NewHorizontalPodAutoscalerBuilder creates a new HorizontalPodAutoscalerBuilder
+func NewHorizontalPodAutoscalerBuilder(bssCluster *bssv1alpha1.BssCluster) *HorizontalPodAutoscalerBuilder {
+	return &HorizontalPodAutoscalerBuilder{
+		bssCluster: bssCluster,
+	}
+}
+
+// Build constructs the HorizontalPodAutoscaler scaling the requested workload
+func (b *HorizontalPodAutoscalerBuilder) Build() *autoscalingv2.HorizontalPodAutoscaler {
+	autoscaling := b.bssCluster.Spec.Autoscaling
+	if autoscaling == nil {
+		autoscaling = &bssv1alpha1.AutoscalingSpec{}
+	}
+
+	return &autoscalingv2.HorizontalPodAutoscaler{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      b.bssCluster.Name,
+			Namespace: b.bssCluster.Namespace,
+			Labels:    CommonLabels(b.bssCluster),
+		},
+		Spec: autoscalingv2.HorizontalPodAutoscalerSpec{
+			ScaleTargetRef: autoscalingv2.CrossVersionObjectReference{
+				APIVersion: "apps/v1",
+				Kind:       string(Workload(b.bssCluster)),
+				Name:       b.bssCluster.Name,
+			},
+			MinReplicas: ptr.To(Replicas(b.bssCluster)),
+			MaxReplicas: autoscaling.MaxReplicas,
+			Metrics:     b.buildMetrics(autoscaling),
+			Behavior:    autoscaling.Behavior,
+		},
+	}
+}
+
+func (b *HorizontalPodAutoscalerBuilder) buildMetrics(autoscaling *bssv1alpha1.AutoscalingSpec) []autoscalingv2.MetricSpec {
+	var metrics []autoscalingv2.MetricSpec
+	if autoscaling.TargetCPUUtilizationPercentage != nil {
+		metrics = append(metrics, resourceMetric(corev1.ResourceCPU, *autoscaling.TargetCPUUtilizationPercentage))
+	}
+	if autoscaling.TargetMemoryUtilizationPercentage != nil {
+		metrics = append(metrics, resourceMetric(corev1.ResourceMemory, *autoscaling.TargetMemoryUtilizationPercentage))
+	}
+	metrics = append(metrics, autoscaling.Metrics...)
+	if len(metrics) == 0 {
+		metrics = append(metrics, resourceMetric(corev1.ResourceCPU, defaultTargetCPUUtilization))
+	}
+	return metrics
+}
+
+// resourceMetric targets an average utilization of a container resource
+func resourceMetric(name corev1.ResourceName, utilization int32) autoscalingv2.MetricSpec {
+	return autoscalingv2.MetricSpec{
+		Type: autoscalingv2.ResourceMetricSourceType,
+		Resource: &autoscalingv2.ResourceMetricSource{
+			Name: name,
+			Target: autoscalingv2.MetricTarget{
+				Type:               autoscalingv2.UtilizationMetricType,
+				AverageUtilization: ptr.To(utilization),
+			},
+		},
+	}
+}
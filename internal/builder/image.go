@@ -0,0 +1,67 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package builder
+
+import (
+	"strings"
+
+	bssv1alpha1 "github.com/brmorris/bss-operator/api/v1alpha1"
+)
+
+// ImageName is the name of the bss-api image within a registry
+const ImageName = "bss-api"
+
+// ImageResolver resolves the bss-api image reference of a BssCluster
+type ImageResolver struct {
+	// Registry is prepended to ImageName when a BssCluster does not set its
+	// own repository. Images are pulled from the container runtime's default
+	// registry when empty.
+	Registry string
+}
+
+// Resolve returns the image reference of the version currently deployed for
+// a BssCluster, see DeployedVersion
+func (r ImageResolver) Resolve(bssCluster *bssv1alpha1.BssCluster) string {
+	return r.ResolveVersion(bssCluster, DeployedVersion(bssCluster))
+}
+
+// ResolveVersion returns the image reference of a bss-api version for a
+// BssCluster: the repository from spec.image or the default registry, pinned
+// by digest if one is set and otherwise tagged with spec.image.tag or the version
+func (r ImageResolver) ResolveVersion(bssCluster *bssv1alpha1.BssCluster, version string) string {
+	image := bssCluster.Spec.Image
+	if image == nil {
+		image = &bssv1alpha1.ImageSpec{}
+	}
+
+	repository := image.Repository
+	if repository == "" {
+		repository = ImageName
+		if registry := strings.TrimSuffix(r.Registry, "/"); registry != "" {
+			repository = registry + "/" + ImageName
+		}
+	}
+
+	if image.Digest != "" {
+		return repository + "@" + image.Digest
+	}
+	tag := image.Tag
+	if tag == "" {
+		tag = version
+	}
+	return repository + ":" + tag
+}
@@ -48,6 +48,16 @@ func SelectorLabels(bssCluster *bssv1alpha1.BssCluster) map[string]string {
 	}
 }
 
+// UpgradeJobSelectorLabels generates the labels of the pods of upgrade Jobs.
+// They differ from SelectorLabels so that Job pods are never selected by the
+// Service, workload or disruption budget of bss-api.
+func UpgradeJobSelectorLabels(bssCluster *bssv1alpha1.BssCluster) map[string]string {
+	return map[string]string{
+		LabelApp:      "bss-cluster-upgrade",
+		LabelInstance: bssCluster.Name,
+	}
+}
+
 // MergeLabels merges multiple label maps with later maps taking precedence
 func MergeLabels(labelMaps ...map[string]string) map[string]string {
 	result := make(map[string]string)
@@ -0,0 +1,74 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package builder
+
+import (
+	"slices"
+	"time"
+
+	bssv1alpha1 "github.com/brmorris/bss-operator/api/v1alpha1"
+)
+
+// MaintenanceWindowTimeOfDay is the layout of spec.maintenanceWindow.start
+const MaintenanceWindowTimeOfDay = "15:04"
+
+// MaintenanceWindowWait returns how long until the maintenance window of a
+// BssCluster next opens, or zero while it is open or if the cluster has none
+func MaintenanceWindowWait(bssCluster *bssv1alpha1.BssCluster, now time.Time) time.Duration {
+	opens, open := NextMaintenanceWindow(bssCluster, now)
+	if open || opens.IsZero() {
+		return 0
+	}
+	return opens.Sub(now)
+}
+
+// NextMaintenanceWindow returns when the maintenance window of a BssCluster
+// next opens, and whether it is open now. The returned time is zero if the
+// cluster has no window.
+func NextMaintenanceWindow(bssCluster *bssv1alpha1.BssCluster, now time.Time) (time.Time, bool) {
+	window := bssCluster.Spec.MaintenanceWindow
+	if window == nil {
+		return time.Time{}, true
+	}
+	start, err := time.Parse(MaintenanceWindowTimeOfDay, window.Start)
+	if err != nil {
+		return time.Time{}, true
+	}
+	location, err := time.LoadLocation(window.TimeZone)
+	if err != nil {
+		location = time.UTC
+	}
+
+	// A window lasts at most a week, so one open now opened within the past
+	// week, and the next one opens within the coming week
+	local := now.In(location)
+	var next time.Time
+	for offset := -7; offset <= 7; offset++ {
+		opens := time.Date(local.Year(), local.Month(), local.Day()+offset,
+			start.Hour(), start.Minute(), 0, 0, location)
+		if len(window.Days) > 0 && !slices.Contains(window.Days, bssv1alpha1.Weekday(opens.Weekday().String())) {
+			continue
+		}
+		if !now.Before(opens) && now.Before(opens.Add(window.Duration.Duration)) {
+			return opens, true
+		}
+		if next.IsZero() && opens.After(now) {
+			next = opens
+		}
+	}
+	return next, false
+}
@@ -0,0 +1,132 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package builder
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/ptr"
+
+	bssv1alpha1 "github.com/brmorris/bss-operator/api/v1alpha1"
+)
+
+// namespaceNameLabel is set on every namespace by the API server
+const namespaceNameLabel = "kubernetes.io/metadata.name"
+
+// NetworkPolicyBuilder builds a NetworkPolicy for a BssCluster
+type NetworkPolicyBuilder struct {
+	bssCluster        *bssv1alpha1.BssCluster
+	operatorNamespace string
+}
+
+// NewNetworkPolicyBuilder creates a new NetworkPolicyBuilder
+func NewNetworkPolicyBuilder(bssCluster *bssv1alpha1.BssCluster) *NetworkPolicyBuilder {
+	return &NetworkPolicyBuilder{
+		bssCluster: bssCluster,
+	}
+}
+
+// WithOperatorNamespace sets the namespace the operator runs in, which is
+// always allowed to reach bss-api
+func (b *NetworkPolicyBuilder) WithOperatorNamespace(namespace string) *NetworkPolicyBuilder {
+	b.operatorNamespace = namespace
+	return b
+}
+
+// Build constructs the NetworkPolicy selecting the bss-api pods
+func (b *NetworkPolicyBuilder) Build() *networkingv1.NetworkPolicy {
+	policySpec := b.bssCluster.Spec.NetworkPolicy
+	if policySpec == nil {
+		policySpec = &bssv1alpha1.NetworkPolicySpec{}
+	}
+
+	var from []networkingv1.NetworkPolicyPeer
+	if b.operatorNamespace != "" {
+		from = append(from, networkingv1.NetworkPolicyPeer{
+			NamespaceSelector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{namespaceNameLabel: b.operatorNamespace},
+			},
+		})
+	}
+	// Post-upgrade Jobs, such as smoke tests, run against the new version
+	if UpgradeJob(b.bssCluster, PostUpgradeHook) != nil {
+		from = append(from, networkingv1.NetworkPolicyPeer{
+			PodSelector: &metav1.LabelSelector{
+				MatchLabels: UpgradeJobSelectorLabels(b.bssCluster),
+			},
+		})
+	}
+	from = append(from, policySpec.From...)
+
+	policy := &networkingv1.NetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      b.bssCluster.Name,
+			Namespace: b.bssCluster.Namespace,
+			Labels:    CommonLabels(b.bssCluster),
+		},
+		Spec: networkingv1.NetworkPolicySpec{
+			PodSelector: metav1.LabelSelector{
+				MatchLabels: SelectorLabels(b.bssCluster),
+			},
+			PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeIngress},
+			Ingress: []networkingv1.NetworkPolicyIngressRule{
+				{
+					Ports: b.buildIngressPorts(),
+					From:  from,
+				},
+			},
+		},
+	}
+
+	if len(policySpec.Egress) > 0 {
+		policy.Spec.PolicyTypes = append(policy.Spec.PolicyTypes, networkingv1.PolicyTypeEgress)
+		policy.Spec.Egress = policySpec.Egress
+	}
+
+	return policy
+}
+
+// buildIngressPorts returns the pod ports behind the Service: the http port
+// and the target ports of any extra Service ports
+func (b *NetworkPolicyBuilder) buildIngressPorts() []networkingv1.NetworkPolicyPort {
+	ports := []networkingv1.NetworkPolicyPort{
+		{
+			Protocol: ptr.To(corev1.ProtocolTCP),
+			Port:     ptr.To(intstr.FromString(HTTPPortName)),
+		},
+	}
+	if b.bssCluster.Spec.Service == nil {
+		return ports
+	}
+	for _, extraPort := range b.bssCluster.Spec.Service.ExtraPorts {
+		target := extraPort.TargetPort
+		if target.Type == intstr.Int && target.IntVal == 0 {
+			target = intstr.FromInt32(extraPort.Port)
+		}
+		protocol := extraPort.Protocol
+		if protocol == "" {
+			protocol = corev1.ProtocolTCP
+		}
+		ports = append(ports, networkingv1.NetworkPolicyPort{
+			Protocol: ptr.To(protocol),
+			Port:     ptr.To(target),
+		})
+	}
+	return ports
+}
@@ -0,0 +1,75 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package builder
+
+import (
+	policyv1 "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/ptr"
+
+	bssv1alpha1 "github.com/brmorris/bss-operator/api/v1alpha1"
+)
+
+// HasPodDisruptionBudget reports whether a BssCluster requests a PodDisruptionBudget
+func HasPodDisruptionBudget(bssCluster *bssv1alpha1.BssCluster) bool {
+	return bssCluster.Spec.Availability != nil && bssCluster.Spec.Availability.PodDisruptionBudget != nil
+}
+
+// PodDisruptionBudgetBuilder builds a PodDisruptionBudget for a BssCluster
+type PodDisruptionBudgetBuilder struct {
+	bssCluster *bssv1alpha1.BssCluster
+}
+
+// NewPodDisruptionBudgetBuilder creates a new PodDisruptionBudgetBuilder
+func NewPodDisruptionBudgetBuilder(bssCluster *bssv1alpha1.BssCluster) *PodDisruptionBudgetBuilder {
+	return &PodDisruptionBudgetBuilder{
+		bssCluster: bssCluster,
+	}
+}
+
+// Build constructs the PodDisruptionBudget covering the bss-api pods of
+// either workload kind
+func (b *PodDisruptionBudgetBuilder) Build() *policyv1.PodDisruptionBudget {
+	pdb := &policyv1.PodDisruptionBudget{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      b.bssCluster.Name,
+			Namespace: b.bssCluster.Namespace,
+			Labels:    CommonLabels(b.bssCluster),
+		},
+		Spec: policyv1.PodDisruptionBudgetSpec{
+			Selector: &metav1.LabelSelector{
+				MatchLabels: SelectorLabels(b.bssCluster),
+			},
+		},
+	}
+
+	var budget *bssv1alpha1.PodDisruptionBudgetSpec
+	if b.bssCluster.Spec.Availability != nil {
+		budget = b.bssCluster.Spec.Availability.PodDisruptionBudget
+	}
+	switch {
+	case budget != nil && budget.MinAvailable != nil:
+		pdb.Spec.MinAvailable = budget.MinAvailable
+	case budget != nil && budget.MaxUnavailable != nil:
+		pdb.Spec.MaxUnavailable = budget.MaxUnavailable
+	default:
+		pdb.Spec.MaxUnavailable = ptr.To(intstr.FromInt32(1))
+	}
+
+	return pdb
+}
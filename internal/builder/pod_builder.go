@@ -0,0 +1,241 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package builder
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/ptr"
+
+	bssv1alpha1 "github.com/brmorris/bss-operator/api/v1alpha1"
+)
+
+const (
+	// ContainerName is the name of the bss-api container in workload pod templates
+	ContainerName = "bss-api"
+
+	// nonRootID is the user and group bss-api runs as by default, matching the
+	// nonroot user of distroless images
+	nonRootID = 65532
+)
+
+// PodTemplateBuilder builds the bss-api pod template shared by the Deployment
+// and StatefulSet builders
+type PodTemplateBuilder struct {
+	bssCluster    *bssv1alpha1.BssCluster
+	imageResolver ImageResolver
+	volumeMounts  []corev1.VolumeMount
+	version       string
+}
+
+// NewPodTemplateBuilder creates a new PodTemplateBuilder
+func NewPodTemplateBuilder(bssCluster *bssv1alpha1.BssCluster) *PodTemplateBuilder {
+	return &PodTemplateBuilder{
+		bssCluster: bssCluster,
+	}
+}
+
+// WithImageResolver sets the resolver of the bss-api image reference
+func (b *PodTemplateBuilder) WithImageResolver(imageResolver ImageResolver) *PodTemplateBuilder {
+	b.imageResolver = imageResolver
+	return b
+}
+
+// WithVersion sets the bss-api version of the pod template, which defaults to
+// the deployed version
+func (b *PodTemplateBuilder) WithVersion(version string) *PodTemplateBuilder {
+	b.version = version
+	return b
+}
+
+// WithVolumeMounts adds volume mounts to the bss-api container
+func (b *PodTemplateBuilder) WithVolumeMounts(volumeMounts ...corev1.VolumeMount) *PodTemplateBuilder {
+	b.volumeMounts = append(b.volumeMounts, volumeMounts...)
+	return b
+}
+
+// Build constructs the pod template
+func (b *PodTemplateBuilder) Build() corev1.PodTemplateSpec {
+	spec := b.bssCluster.Spec
+
+	template := corev1.PodTemplateSpec{
+		ObjectMeta: metav1.ObjectMeta{
+			Labels: CommonLabels(b.bssCluster),
+		},
+		Spec: corev1.PodSpec{
+			Containers:                []corev1.Container{b.buildContainer()},
+			ImagePullSecrets:          b.imagePullSecrets(),
+			NodeSelector:              spec.NodeSelector,
+			Tolerations:               spec.Tolerations,
+			Affinity:                  spec.Affinity,
+			TopologySpreadConstraints: spec.TopologySpreadConstraints,
+			PriorityClassName:         spec.PriorityClassName,
+			SecurityContext:           b.buildPodSecurityContext(),
+		},
+	}
+
+	// The version, config hash and credentials rotation time change the pod
+	// template, and so roll the pods, whenever bss-api is upgraded, even with a
+	// pinned image, the configuration changes or the credentials are rotated
+	annotations := map[string]string{
+		bssv1alpha1.AnnotationVersion: b.deployedVersion(),
+	}
+	if spec.Config != nil {
+		annotations[bssv1alpha1.AnnotationConfigHash] = ConfigHash(b.bssCluster)
+		template.Spec.Volumes = append(template.Spec.Volumes, configVolume(b.bssCluster))
+	}
+	if spec.Credentials != nil {
+		annotations[bssv1alpha1.AnnotationCredentialsRotatedAt] = credentialsRotatedAt(b.bssCluster)
+		if injectsCredentialFiles(b.bssCluster) {
+			template.Spec.Volumes = append(template.Spec.Volumes, credentialsVolume(b.bssCluster))
+		}
+	}
+	template.Annotations = annotations
+
+	return template
+}
+
+func (b *PodTemplateBuilder) buildContainer() corev1.Container {
+	spec := b.bssCluster.Spec
+
+	return corev1.Container{
+		Name:            ContainerName,
+		Image:           b.imageResolver.ResolveVersion(b.bssCluster, b.deployedVersion()),
+		ImagePullPolicy: b.imagePullPolicy(),
+		Ports:           []corev1.ContainerPort{HTTPContainerPort()},
+		Env:             b.buildEnv(),
+		EnvFrom:         spec.EnvFrom,
+		Resources:       spec.Resources,
+		VolumeMounts:    b.buildVolumeMounts(),
+		LivenessProbe:   probeOrDefault(spec.LivenessProbe, defaultProbe(3)),
+		ReadinessProbe:  probeOrDefault(spec.ReadinessProbe, defaultProbe(3)),
+		StartupProbe:    probeOrDefault(spec.StartupProbe, defaultProbe(30)),
+		SecurityContext: b.buildContainerSecurityContext(),
+	}
+}
+
+func (b *PodTemplateBuilder) deployedVersion() string {
+	if b.version != "" {
+		return b.version
+	}
+	return DeployedVersion(b.bssCluster)
+}
+
+// buildEnv points bss-api at its configuration file and credentials, if any.
+// Variables from spec.env come last so they can override them.
+func (b *PodTemplateBuilder) buildEnv() []corev1.EnvVar {
+	spec := b.bssCluster.Spec
+	var env []corev1.EnvVar
+	if spec.Config != nil {
+		env = append(env, corev1.EnvVar{Name: ConfigEnvVar, Value: ConfigMountPath + "/" + ConfigFileName})
+	}
+	if spec.Credentials != nil && !injectsCredentialFiles(b.bssCluster) {
+		env = append(env, credentialsEnv(b.bssCluster)...)
+	}
+	if env == nil {
+		return spec.Env
+	}
+	return append(env, spec.Env...)
+}
+
+func (b *PodTemplateBuilder) buildVolumeMounts() []corev1.VolumeMount {
+	var volumeMounts []corev1.VolumeMount
+	if b.bssCluster.Spec.Config != nil {
+		volumeMounts = append(volumeMounts, configVolumeMount())
+	}
+	if injectsCredentialFiles(b.bssCluster) {
+		volumeMounts = append(volumeMounts, credentialsVolumeMount())
+	}
+	return append(volumeMounts, b.volumeMounts...)
+}
+
+// injectsCredentialFiles reports whether credentials are mounted as files
+// rather than passed as environment variables
+func injectsCredentialFiles(bssCluster *bssv1alpha1.BssCluster) bool {
+	credentials := bssCluster.Spec.Credentials
+	return credentials != nil && credentials.Injection == bssv1alpha1.CredentialsInjectionFile
+}
+
+func (b *PodTemplateBuilder) imagePullPolicy() corev1.PullPolicy {
+	if b.bssCluster.Spec.Image == nil {
+		return ""
+	}
+	return b.bssCluster.Spec.Image.PullPolicy
+}
+
+func (b *PodTemplateBuilder) imagePullSecrets() []corev1.LocalObjectReference {
+	if b.bssCluster.Spec.Image == nil {
+		return nil
+	}
+	return b.bssCluster.Spec.Image.PullSecrets
+}
+
+// buildPodSecurityContext defaults to a context that satisfies the restricted
+// Pod Security Standard. The fsGroup makes data volumes writable.
+func (b *PodTemplateBuilder) buildPodSecurityContext() *corev1.PodSecurityContext {
+	if b.bssCluster.Spec.PodSecurityContext != nil {
+		return b.bssCluster.Spec.PodSecurityContext
+	}
+	return &corev1.PodSecurityContext{
+		RunAsNonRoot: ptr.To(true),
+		RunAsUser:    ptr.To[int64](nonRootID),
+		RunAsGroup:   ptr.To[int64](nonRootID),
+		FSGroup:      ptr.To[int64](nonRootID),
+		SeccompProfile: &corev1.SeccompProfile{
+			Type: corev1.SeccompProfileTypeRuntimeDefault,
+		},
+	}
+}
+
+// buildContainerSecurityContext defaults to a context that satisfies the
+// restricted Pod Security Standard
+func (b *PodTemplateBuilder) buildContainerSecurityContext() *corev1.SecurityContext {
+	if b.bssCluster.Spec.ContainerSecurityContext != nil {
+		return b.bssCluster.Spec.ContainerSecurityContext
+	}
+	return &corev1.SecurityContext{
+		AllowPrivilegeEscalation: ptr.To(false),
+		Capabilities: &corev1.Capabilities{
+			Drop: []corev1.Capability{"ALL"},
+		},
+	}
+}
+
+// defaultProbe returns an HTTP probe against the GraphQL endpoint that fails
+// after the given number of consecutive failures
+func defaultProbe(failureThreshold int32) *corev1.Probe {
+	return &corev1.Probe{
+		ProbeHandler: corev1.ProbeHandler{
+			HTTPGet: &corev1.HTTPGetAction{
+				Path: GraphQLPath,
+				Port: intstr.FromString(HTTPPortName),
+			},
+		},
+		PeriodSeconds:    10,
+		TimeoutSeconds:   1,
+		SuccessThreshold: 1,
+		FailureThreshold: failureThreshold,
+	}
+}
+
+func probeOrDefault(probe, defaultProbe *corev1.Probe) *corev1.Probe {
+	if probe != nil {
+		return probe
+	}
+	return defaultProbe
+}
@@ -0,0 +1,57 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package builder
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+const (
+	// HTTPPortName is the name of the bss-api HTTP port, referenced by the
+	// Services, probes and any other object that targets bss-api
+	HTTPPortName = "http"
+
+	// HTTPPort is the port bss-api listens on
+	HTTPPort = 8880
+
+	// DefaultServicePort is the port the Service publishes by default
+	DefaultServicePort = 80
+
+	// GraphQLPath is the path of the bss-api GraphQL endpoint, also used for probes
+	GraphQLPath = "/graphql"
+)
+
+// HTTPContainerPort returns the HTTP port of the bss-api container
+func HTTPContainerPort() corev1.ContainerPort {
+	return corev1.ContainerPort{
+		Name:          HTTPPortName,
+		ContainerPort: HTTPPort,
+		Protocol:      corev1.ProtocolTCP,
+	}
+}
+
+// HTTPServicePort returns a Service port publishing the bss-api HTTP port on
+// the given port
+func HTTPServicePort(port int32) corev1.ServicePort {
+	return corev1.ServicePort{
+		Name:       HTTPPortName,
+		Port:       port,
+		TargetPort: intstr.FromString(HTTPPortName),
+		Protocol:   corev1.ProtocolTCP,
+	}
+}
@@ -0,0 +1,102 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package builder
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	bssv1alpha1 "github.com/brmorris/bss-operator/api/v1alpha1"
+)
+
+// QueryOutputName returns the name of the ConfigMap or Secret the fields of
+// a BSSQuery are written to
+func QueryOutputName(bssQuery *bssv1alpha1.BSSQuery) string {
+	output := bssQuery.Spec.Output
+	switch {
+	case output.ConfigMap != nil && output.ConfigMap.Name != "":
+		return output.ConfigMap.Name
+	case output.Secret != nil && output.Secret.Name != "":
+		return output.Secret.Name
+	}
+	return bssQuery.Name
+}
+
+// QueryOutputLabels generates the labels of the ConfigMaps and Secrets
+// written by a BSSQuery, which select them when its output moves
+func QueryOutputLabels(bssQuery *bssv1alpha1.BSSQuery) map[string]string {
+	return map[string]string{
+		LabelApp:       "bss-query",
+		LabelInstance:  bssQuery.Name,
+		LabelPartOf:    "bss-operator",
+		LabelManagedBy: "bss-operator",
+	}
+}
+
+// QueryOutputAnnotations returns the annotations a BSSQuery writes on its
+// target, keyed by the prefix and the name of each field
+func QueryOutputAnnotations(bssQuery *bssv1alpha1.BSSQuery, values map[string]string) map[string]string {
+	annotations := make(map[string]string, len(values))
+	for name, value := range values {
+		annotations[bssQuery.Spec.Output.Annotations.Prefix+name] = value
+	}
+	return annotations
+}
+
+// QueryOutputBuilder builds the ConfigMap or Secret holding the fields
+// extracted from the result of a BSSQuery
+type QueryOutputBuilder struct {
+	bssQuery *bssv1alpha1.BSSQuery
+	values   map[string]string
+}
+
+// NewQueryOutputBuilder creates a new QueryOutputBuilder
+func NewQueryOutputBuilder(bssQuery *bssv1alpha1.BSSQuery, values map[string]string) *QueryOutputBuilder {
+	return &QueryOutputBuilder{
+		bssQuery: bssQuery,
+		values:   values,
+	}
+}
+
+// BuildConfigMap constructs the ConfigMap
+func (b *QueryOutputBuilder) BuildConfigMap() *corev1.ConfigMap {
+	return &corev1.ConfigMap{
+		ObjectMeta: b.objectMeta(),
+		Data:       b.values,
+	}
+}
+
+// BuildSecret constructs the Secret
+func (b *QueryOutputBuilder) BuildSecret() *corev1.Secret {
+	data := make(map[string][]byte, len(b.values))
+	for key, value := range b.values {
+		data[key] = []byte(value)
+	}
+	return &corev1.Secret{
+		ObjectMeta: b.objectMeta(),
+		Type:       corev1.SecretTypeOpaque,
+		Data:       data,
+	}
+}
+
+func (b *QueryOutputBuilder) objectMeta() metav1.ObjectMeta {
+	return metav1.ObjectMeta{
+		Name:      QueryOutputName(b.bssQuery),
+		Namespace: b.bssQuery.Namespace,
+		Labels:    QueryOutputLabels(b.bssQuery),
+	}
+}
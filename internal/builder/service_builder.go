@@ -17,6 +17,8 @@ limitations under the License.
 package builder
 
 import (
+	"fmt"
+
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
@@ -35,27 +37,94 @@ func NewServiceBuilder(bssCluster *bssv1alpha1.BssCluster) *ServiceBuilder {
 	}
 }
 
-// Build constructs the Service
+// Build constructs the Service that clients use to reach bss-api
 func (b *ServiceBuilder) Build() *corev1.Service {
 	labels := CommonLabels(b.bssCluster)
 	selectorLabels := SelectorLabels(b.bssCluster)
+	serviceSpec := b.bssCluster.Spec.Service
+	if serviceSpec == nil {
+		serviceSpec = &bssv1alpha1.BssClusterServiceSpec{}
+	}
+
+	serviceType := serviceSpec.Type
+	if serviceType == "" {
+		serviceType = corev1.ServiceTypeClusterIP
+	}
+	service := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        b.bssCluster.Name,
+			Namespace:   b.bssCluster.Namespace,
+			Labels:      labels,
+			Annotations: serviceSpec.Annotations,
+		},
+		Spec: corev1.ServiceSpec{
+			Type:            serviceType,
+			Selector:        selectorLabels,
+			Ports:           append([]corev1.ServicePort{HTTPServicePort(ServicePort(b.bssCluster))}, serviceSpec.ExtraPorts...),
+			SessionAffinity: serviceSpec.SessionAffinity,
+		},
+	}
+
+	// These fields are rejected by the API server for other Service types
+	if serviceType == corev1.ServiceTypeLoadBalancer {
+		service.Spec.LoadBalancerSourceRanges = serviceSpec.LoadBalancerSourceRanges
+	}
+	if serviceType != corev1.ServiceTypeClusterIP {
+		service.Spec.ExternalTrafficPolicy = serviceSpec.ExternalTrafficPolicy
+	}
+
+	return service
+}
+
+// ServiceURL returns the in-cluster URL of the GraphQL endpoint published by
+// the Service of a BssCluster
+func ServiceURL(bssCluster *bssv1alpha1.BssCluster) string {
+	return fmt.Sprintf("http://%s.%s.svc:%d%s", bssCluster.Name, bssCluster.Namespace,
+		ServicePort(bssCluster), GraphQLPath)
+}
+
+// ServicePort returns the port the Service of a BssCluster publishes the
+// GraphQL endpoint on
+func ServicePort(bssCluster *bssv1alpha1.BssCluster) int32 {
+	if bssCluster.Spec.Service == nil || bssCluster.Spec.Service.Port == 0 {
+		return DefaultServicePort
+	}
+	return bssCluster.Spec.Service.Port
+}
+
+// HeadlessServiceName returns the name of the headless Service that governs
+// the StatefulSet of a BssCluster
+func HeadlessServiceName(bssCluster *bssv1alpha1.BssCluster) string {
+	return bssCluster.Name + "-headless"
+}
+
+// HeadlessServiceBuilder builds the headless Service that gives every
+// StatefulSet replica a stable DNS name
+type HeadlessServiceBuilder struct {
+	bssCluster *bssv1alpha1.BssCluster
+}
+
+// NewHeadlessServiceBuilder creates a new HeadlessServiceBuilder
+func NewHeadlessServiceBuilder(bssCluster *bssv1alpha1.BssCluster) *HeadlessServiceBuilder {
+	return &HeadlessServiceBuilder{
+		bssCluster: bssCluster,
+	}
+}
 
+// Build constructs the headless Service. Replicas are published before they
+// are ready so peers can find each other while starting up.
+func (b *HeadlessServiceBuilder) Build() *corev1.Service {
 	return &corev1.Service{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      b.bssCluster.Name,
+			Name:      HeadlessServiceName(b.bssCluster),
 			Namespace: b.bssCluster.Namespace,
-			Labels:    labels,
+			Labels:    CommonLabels(b.bssCluster),
 		},
 		Spec: corev1.ServiceSpec{
-			Type:     corev1.ServiceTypeClusterIP,
-			Selector: selectorLabels,
-			Ports: []corev1.ServicePort{
-				{
-					Name:     "http",
-					Port:     80,
-					Protocol: corev1.ProtocolTCP,
-				},
-			},
+			ClusterIP:                corev1.ClusterIPNone,
+			Selector:                 SelectorLabels(b.bssCluster),
+			PublishNotReadyAddresses: true,
+			Ports:                    []corev1.ServicePort{HTTPServicePort(HTTPPort)},
 		},
 	}
 }
@@ -24,9 +24,18 @@ import (
 	bssv1alpha1 "github.com/brmorris/bss-operator/api/v1alpha1"
 )
 
+const (
+	// DataVolumeName is the name of the volume claim template backing each replica
+	DataVolumeName = "data"
+
+	// DataMountPath is where the data volume is mounted in the bss-api container
+	DataMountPath = "/var/lib/bss-api"
+)
+
 // StatefulSetBuilder builds a StatefulSet for a BssCluster
 type StatefulSetBuilder struct {
-	bssCluster *bssv1alpha1.BssCluster
+	bssCluster    *bssv1alpha1.BssCluster
+	imageResolver ImageResolver
 }
 
 // NewStatefulSetBuilder creates a new StatefulSetBuilder
@@ -36,11 +45,18 @@ func NewStatefulSetBuilder(bssCluster *bssv1alpha1.BssCluster) *StatefulSetBuild
 	}
 }
 
+// WithImageResolver sets the resolver of the bss-api image reference
+func (b *StatefulSetBuilder) WithImageResolver(imageResolver ImageResolver) *StatefulSetBuilder {
+	b.imageResolver = imageResolver
+	return b
+}
+
 // Build constructs the StatefulSet
 func (b *StatefulSetBuilder) Build() *appsv1.StatefulSet {
-	replicas := b.getReplicas()
+	replicas := Replicas(b.bssCluster)
 	labels := CommonLabels(b.bssCluster)
 	selectorLabels := SelectorLabels(b.bssCluster)
+	template := b.buildPodTemplate()
 
 	return &appsv1.StatefulSet{
 		ObjectMeta: metav1.ObjectMeta{
@@ -50,44 +66,80 @@ func (b *StatefulSetBuilder) Build() *appsv1.StatefulSet {
 		},
 		Spec: appsv1.StatefulSetSpec{
 			Replicas:    &replicas,
-			ServiceName: b.bssCluster.Name,
+			ServiceName: HeadlessServiceName(b.bssCluster),
 			Selector: &metav1.LabelSelector{
 				MatchLabels: selectorLabels,
 			},
-			Template: corev1.PodTemplateSpec{
-				ObjectMeta: metav1.ObjectMeta{
-					Labels: labels,
-				},
-				Spec: b.buildPodSpec(),
-			},
-			// VolumeClaimTemplates can be added here when you add PVC support
+			Template:                             template,
+			VolumeClaimTemplates:                 b.buildVolumeClaimTemplates(),
+			PersistentVolumeClaimRetentionPolicy: b.buildRetentionPolicy(),
 		},
 	}
 }
 
-func (b *StatefulSetBuilder) buildPodSpec() corev1.PodSpec {
-	return corev1.PodSpec{
-		Containers: []corev1.Container{
-			{
-				Name:  "bss",
-				Image: "bss-api:" + b.bssCluster.Spec.Version,
-				Ports: []corev1.ContainerPort{
-					{
-						Name:          "http",
-						ContainerPort: 8080,
-						Protocol:      corev1.ProtocolTCP,
+// buildPodTemplate mounts the data volume into the shared pod template when
+// storage is configured
+func (b *StatefulSetBuilder) buildPodTemplate() corev1.PodTemplateSpec {
+	podTemplateBuilder := NewPodTemplateBuilder(b.bssCluster).WithImageResolver(b.imageResolver)
+	if b.bssCluster.Spec.Storage != nil {
+		podTemplateBuilder.WithVolumeMounts(corev1.VolumeMount{
+			Name:      DataVolumeName,
+			MountPath: DataMountPath,
+		})
+	}
+	return podTemplateBuilder.Build()
+}
+
+// buildVolumeClaimTemplates turns spec.storage into the claim template for
+// the data volume. The claims carry the selector labels so they can be found
+// again when the BssCluster is deleted.
+func (b *StatefulSetBuilder) buildVolumeClaimTemplates() []corev1.PersistentVolumeClaim {
+	storage := b.bssCluster.Spec.Storage
+	if storage == nil {
+		return nil
+	}
+
+	accessModes := storage.AccessModes
+	if len(accessModes) == 0 {
+		accessModes = []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce}
+	}
+
+	return []corev1.PersistentVolumeClaim{
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   DataVolumeName,
+				Labels: SelectorLabels(b.bssCluster),
+			},
+			Spec: corev1.PersistentVolumeClaimSpec{
+				AccessModes:      accessModes,
+				StorageClassName: storage.StorageClassName,
+				Resources: corev1.VolumeResourceRequirements{
+					Requests: corev1.ResourceList{
+						corev1.ResourceStorage: storage.Size,
 					},
 				},
-				// Resources, env vars, volume mounts can be added here
 			},
 		},
-		// Volumes can be added here when you add ConfigMap/Secret support
 	}
 }
 
-func (b *StatefulSetBuilder) getReplicas() int32 {
-	if b.bssCluster.Spec.Replicas != nil {
-		return *b.bssCluster.Spec.Replicas
+// buildRetentionPolicy maps spec.storage.retentionPolicy onto the StatefulSet,
+// retaining volumes by default
+func (b *StatefulSetBuilder) buildRetentionPolicy() *appsv1.StatefulSetPersistentVolumeClaimRetentionPolicy {
+	policy := &appsv1.StatefulSetPersistentVolumeClaimRetentionPolicy{
+		WhenDeleted: appsv1.RetainPersistentVolumeClaimRetentionPolicyType,
+		WhenScaled:  appsv1.RetainPersistentVolumeClaimRetentionPolicyType,
+	}
+	if b.bssCluster.Spec.Storage == nil || b.bssCluster.Spec.Storage.RetentionPolicy == nil {
+		return policy
+	}
+
+	retention := b.bssCluster.Spec.Storage.RetentionPolicy
+	if retention.WhenDeleted != "" {
+		policy.WhenDeleted = retention.WhenDeleted
+	}
+	if retention.WhenScaled != "" {
+		policy.WhenScaled = retention.WhenScaled
 	}
-	return 1
+	return policy
 }
@@ -0,0 +1,153 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package builder
+
+import (
+	"time"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+
+	bssv1alpha1 "github.com/brmorris/bss-operator/api/v1alpha1"
+)
+
+// UpgradeHook names a Job run during an upgrade of bss-api
+type UpgradeHook string
+
+const (
+	// PreUpgradeHook runs before the new version is rolled out
+	PreUpgradeHook UpgradeHook = "pre-upgrade"
+
+	// PostUpgradeHook runs once the new version is rolled out
+	PostUpgradeHook UpgradeHook = "post-upgrade"
+
+	// DefaultUpgradeTimeout is how long the new version may take to become
+	// ready when spec.upgrade.timeout is not set
+	DefaultUpgradeTimeout = 10 * time.Minute
+
+	// Environment variables telling upgrade Jobs which versions they run between
+	upgradeFromVersionEnvVar = "BSS_UPGRADE_FROM_VERSION"
+	upgradeToVersionEnvVar   = "BSS_UPGRADE_TO_VERSION"
+)
+
+// UpgradeJobName returns the name of the Job run for the given upgrade hook
+func UpgradeJobName(bssCluster *bssv1alpha1.BssCluster, hook UpgradeHook) string {
+	return bssCluster.Name + "-" + string(hook)
+}
+
+// UpgradeJob returns the Job configured for the given upgrade hook, or nil
+// if none is
+func UpgradeJob(bssCluster *bssv1alpha1.BssCluster, hook UpgradeHook) *bssv1alpha1.UpgradeJobSpec {
+	upgrade := bssCluster.Spec.Upgrade
+	if upgrade == nil {
+		return nil
+	}
+	if hook == PreUpgradeHook {
+		return upgrade.PreUpgrade
+	}
+	return upgrade.PostUpgrade
+}
+
+// UpgradeTimeout returns how long the new version may take to become ready
+// before an upgrade is rolled back
+func UpgradeTimeout(bssCluster *bssv1alpha1.BssCluster) time.Duration {
+	if upgrade := bssCluster.Spec.Upgrade; upgrade != nil && upgrade.Timeout != nil {
+		return upgrade.Timeout.Duration
+	}
+	return DefaultUpgradeTimeout
+}
+
+// UpgradeJobBuilder builds the Job run for an upgrade hook of a BssCluster.
+// It must only be used while status.upgrade is set.
+type UpgradeJobBuilder struct {
+	bssCluster    *bssv1alpha1.BssCluster
+	hook          UpgradeHook
+	imageResolver ImageResolver
+}
+
+// NewUpgradeJobBuilder creates a new UpgradeJobBuilder
+func NewUpgradeJobBuilder(bssCluster *bssv1alpha1.BssCluster, hook UpgradeHook) *UpgradeJobBuilder {
+	return &UpgradeJobBuilder{
+		bssCluster: bssCluster,
+		hook:       hook,
+	}
+}
+
+// WithImageResolver sets the resolver of the bss-api image reference
+func (b *UpgradeJobBuilder) WithImageResolver(imageResolver ImageResolver) *UpgradeJobBuilder {
+	b.imageResolver = imageResolver
+	return b
+}
+
+// Build constructs the Job. It runs the bss-api pod template of the version
+// being upgraded to without probes or ports, and is stamped with the start
+// time of the upgrade so a Job left over from an earlier upgrade is told apart.
+func (b *UpgradeJobBuilder) Build() *batchv1.Job {
+	upgrade := b.bssCluster.Status.Upgrade
+	jobSpec := UpgradeJob(b.bssCluster, b.hook)
+	if jobSpec == nil {
+		jobSpec = &bssv1alpha1.UpgradeJobSpec{}
+	}
+
+	labels := MergeLabels(CommonLabels(b.bssCluster), UpgradeJobSelectorLabels(b.bssCluster),
+		map[string]string{LabelComponent: string(b.hook)})
+
+	template := NewPodTemplateBuilder(b.bssCluster).
+		WithImageResolver(b.imageResolver).
+		WithVersion(upgrade.ToVersion).
+		Build()
+	template.Labels = labels
+	template.Spec.RestartPolicy = corev1.RestartPolicyNever
+
+	container := &template.Spec.Containers[0]
+	if jobSpec.Image != "" {
+		container.Image = jobSpec.Image
+	}
+	container.Command = jobSpec.Command
+	container.Args = jobSpec.Args
+	container.Ports = nil
+	container.LivenessProbe = nil
+	container.ReadinessProbe = nil
+	container.StartupProbe = nil
+	container.Env = append([]corev1.EnvVar{
+		{Name: upgradeFromVersionEnvVar, Value: upgrade.FromVersion},
+		{Name: upgradeToVersionEnvVar, Value: upgrade.ToVersion},
+	}, container.Env...)
+
+	backoffLimit := jobSpec.BackoffLimit
+	if backoffLimit == nil {
+		backoffLimit = ptr.To[int32](0)
+	}
+
+	return &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      UpgradeJobName(b.bssCluster, b.hook),
+			Namespace: b.bssCluster.Namespace,
+			Labels:    labels,
+			Annotations: map[string]string{
+				bssv1alpha1.AnnotationUpgradeStartTime: upgrade.StartTime.UTC().Format(time.RFC3339),
+			},
+		},
+		Spec: batchv1.JobSpec{
+			BackoffLimit:          backoffLimit,
+			ActiveDeadlineSeconds: ptr.To(int64(UpgradeTimeout(b.bssCluster).Seconds())),
+			Template:              template,
+		},
+	}
+}
@@ -0,0 +1,68 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package builder
+
+import (
+	bssv1alpha1 "github.com/brmorris/bss-operator/api/v1alpha1"
+)
+
+// Workload returns the kind of workload requested by a BssCluster, which is a
+// Deployment unless spec.workload says otherwise
+func Workload(bssCluster *bssv1alpha1.BssCluster) bssv1alpha1.WorkloadType {
+	if bssCluster.Spec.Workload == "" {
+		return bssv1alpha1.WorkloadTypeDeployment
+	}
+	return bssCluster.Spec.Workload
+}
+
+// Autoscaled reports whether the replicas of a BssCluster are managed by a
+// HorizontalPodAutoscaler
+func Autoscaled(bssCluster *bssv1alpha1.BssCluster) bool {
+	return bssCluster.Spec.Autoscaling != nil
+}
+
+// Replicas returns the replica count of the bss-api workload. While the
+// BssCluster is autoscaled this is the autoscaler's minimum, which only
+// applies when the workload is created.
+func Replicas(bssCluster *bssv1alpha1.BssCluster) int32 {
+	if autoscaling := bssCluster.Spec.Autoscaling; autoscaling != nil {
+		if autoscaling.MinReplicas != nil {
+			return *autoscaling.MinReplicas
+		}
+		return 1
+	}
+	if bssCluster.Spec.Replicas != nil {
+		return *bssCluster.Spec.Replicas
+	}
+	return 1
+}
+
+// DeployedVersion returns the bss-api version the workload runs. During an
+// upgrade this is the target version from the moment it is rolled out until
+// it succeeds or is rolled back, and otherwise the last version rolled out
+// successfully. spec.version is only deployed directly on first install.
+func DeployedVersion(bssCluster *bssv1alpha1.BssCluster) string {
+	status := bssCluster.Status
+	if upgrade := status.Upgrade; upgrade != nil && status.TargetVersion != "" &&
+		(upgrade.Phase == bssv1alpha1.UpgradePhaseRollingOut || upgrade.Phase == bssv1alpha1.UpgradePhasePostUpgrade) {
+		return status.TargetVersion
+	}
+	if status.CurrentVersion != "" {
+		return status.CurrentVersion
+	}
+	return bssCluster.Spec.Version
+}
@@ -0,0 +1,96 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"fmt"
+)
+
+// ClientOption configures a GraphQLClient
+type ClientOption func(*GraphQLClient)
+
+// WithBearerToken sends the token in the Authorization header
+func WithBearerToken(token string) ClientOption {
+	return func(c *GraphQLClient) {
+		c.headers.Set("Authorization", "Bearer "+token)
+	}
+}
+
+// WithBasicAuth authenticates with a username and password
+func WithBasicAuth(username, password string) ClientOption {
+	return func(c *GraphQLClient) {
+		credentials := base64.StdEncoding.EncodeToString([]byte(username + ":" + password))
+		c.headers.Set("Authorization", "Basic "+credentials)
+	}
+}
+
+// WithHeaders sends the headers with every request
+func WithHeaders(headers map[string]string) ClientOption {
+	return func(c *GraphQLClient) {
+		for name, value := range headers {
+			c.headers.Set(name, value)
+		}
+	}
+}
+
+// WithTLSConfig secures the connection to the endpoint with the given
+// configuration
+func WithTLSConfig(config *tls.Config) ClientOption {
+	return func(c *GraphQLClient) {
+		c.transport.TLSClientConfig = config
+	}
+}
+
+// TLSOptions are the PEM-encoded certificates of a TLS connection
+type TLSOptions struct {
+	// CABundle verifies the server instead of the system roots when set
+	CABundle []byte
+
+	// ClientCert and ClientKey are presented to servers requiring mutual TLS
+	ClientCert []byte
+	ClientKey  []byte
+
+	// ServerName overrides the name the server certificate is verified against
+	ServerName string
+}
+
+// NewTLSConfig builds the TLS configuration of a client from PEM-encoded
+// certificates
+func NewTLSConfig(opts TLSOptions) (*tls.Config, error) {
+	config := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		ServerName: opts.ServerName,
+	}
+	if len(opts.CABundle) > 0 {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(opts.CABundle) {
+			return nil, fmt.Errorf("CA bundle contains no PEM certificate")
+		}
+		config.RootCAs = pool
+	}
+	if len(opts.ClientCert) > 0 || len(opts.ClientKey) > 0 {
+		certificate, err := tls.X509KeyPair(opts.ClientCert, opts.ClientKey)
+		if err != nil {
+			return nil, fmt.Errorf("invalid client certificate: %w", err)
+		}
+		config.Certificates = []tls.Certificate{certificate}
+	}
+	return config, nil
+}
@@ -0,0 +1,172 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// newTLSServer serves the clusters query over TLS when the request is
+// authorized, and records the headers of the last request
+func newTLSServer(authorized func(r *http.Request) bool) (*httptest.Server, *http.Header) {
+	last := &http.Header{}
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*last = r.Header.Clone()
+		if !authorized(r) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_, _ = fmt.Fprint(w, `{"data":{"clusters":[{"id":"a","name":"alpha"}]}}`)
+	}))
+	return server, last
+}
+
+// serverCA returns the PEM certificate of a TLS test server, which is its
+// own authority
+func serverCA(server *httptest.Server) []byte {
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+}
+
+// newClientCertificate generates a self-signed client certificate and key
+func newClientCertificate() (certPEM, keyPEM []byte, cert *x509.Certificate) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	Expect(err).NotTo(HaveOccurred())
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "bss-operator"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	Expect(err).NotTo(HaveOccurred())
+	cert, err = x509.ParseCertificate(der)
+	Expect(err).NotTo(HaveOccurred())
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	Expect(err).NotTo(HaveOccurred())
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), cert
+}
+
+var _ = Describe("Authentication and TLS", func() {
+	allowAll := func(*http.Request) bool { return true }
+
+	It("should only trust a server whose CA is configured", func() {
+		server, _ := newTLSServer(allowAll)
+		server.StartTLS()
+		defer server.Close()
+
+		_, err := NewGraphQLClient(server.URL).ListClusters(context.Background())
+		Expect(err).To(MatchError(ContainSubstring("certificate")))
+
+		config, err := NewTLSConfig(TLSOptions{CABundle: serverCA(server)})
+		Expect(err).NotTo(HaveOccurred())
+		clusters, err := NewGraphQLClient(server.URL, WithTLSConfig(config)).ListClusters(context.Background())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(clusters).To(HaveLen(1))
+
+		_, err = NewTLSConfig(TLSOptions{CABundle: []byte("not a certificate")})
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should send a bearer token", func() {
+		server, _ := newTLSServer(func(r *http.Request) bool {
+			return r.Header.Get("Authorization") == "Bearer s3cret"
+		})
+		server.StartTLS()
+		defer server.Close()
+		config, err := NewTLSConfig(TLSOptions{CABundle: serverCA(server)})
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = NewGraphQLClient(server.URL, WithTLSConfig(config)).ListClusters(context.Background())
+		Expect(err).To(MatchError(ContainSubstring("401")))
+		_, err = NewGraphQLClient(server.URL, WithTLSConfig(config), WithBearerToken("s3cret")).ListClusters(context.Background())
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should authenticate with basic auth", func() {
+		server, _ := newTLSServer(func(r *http.Request) bool {
+			username, password, ok := r.BasicAuth()
+			return ok && username == "admin" && password == "s3cret"
+		})
+		server.StartTLS()
+		defer server.Close()
+		config, err := NewTLSConfig(TLSOptions{CABundle: serverCA(server)})
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = NewGraphQLClient(server.URL, WithTLSConfig(config), WithBasicAuth("admin", "wrong")).ListClusters(context.Background())
+		Expect(err).To(MatchError(ContainSubstring("401")))
+		_, err = NewGraphQLClient(server.URL, WithTLSConfig(config), WithBasicAuth("admin", "s3cret")).ListClusters(context.Background())
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should send static headers", func() {
+		server, last := newTLSServer(allowAll)
+		server.StartTLS()
+		defer server.Close()
+		config, err := NewTLSConfig(TLSOptions{CABundle: serverCA(server)})
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = NewGraphQLClient(server.URL, WithTLSConfig(config),
+			WithHeaders(map[string]string{"X-Tenant": "blue"})).ListClusters(context.Background())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(last.Get("X-Tenant")).To(Equal("blue"))
+		Expect(last.Get("Content-Type")).To(Equal("application/json"))
+	})
+
+	It("should present a client certificate to a server requiring mutual TLS", func() {
+		certPEM, keyPEM, cert := newClientCertificate()
+		clientCAs := x509.NewCertPool()
+		clientCAs.AddCert(cert)
+
+		server, _ := newTLSServer(func(r *http.Request) bool {
+			return len(r.TLS.PeerCertificates) == 1 && r.TLS.PeerCertificates[0].Subject.CommonName == "bss-operator"
+		})
+		server.TLS = &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: clientCAs}
+		server.StartTLS()
+		defer server.Close()
+
+		config, err := NewTLSConfig(TLSOptions{CABundle: serverCA(server)})
+		Expect(err).NotTo(HaveOccurred())
+		_, err = NewGraphQLClient(server.URL, WithTLSConfig(config)).ListClusters(context.Background())
+		Expect(err).To(HaveOccurred())
+
+		config, err = NewTLSConfig(TLSOptions{CABundle: serverCA(server), ClientCert: certPEM, ClientKey: keyPEM})
+		Expect(err).NotTo(HaveOccurred())
+		clusters, err := NewGraphQLClient(server.URL, WithTLSConfig(config)).ListClusters(context.Background())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(clusters).To(HaveLen(1))
+	})
+})
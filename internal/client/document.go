@@ -0,0 +1,93 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"fmt"
+
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/graphql-go/graphql/language/parser"
+)
+
+// Document is a parsed GraphQL document with the operation selected to run
+type Document struct {
+	source    string
+	operation *ast.OperationDefinition
+	fragments map[string]*ast.FragmentDefinition
+}
+
+// ParseDocument parses a GraphQL document and selects the operation named
+// operationName, or its only operation if operationName is empty. Only query
+// operations are accepted, so documents cannot change state in the BSS API.
+func ParseDocument(source, operationName string) (*Document, error) {
+	parsed, err := parser.Parse(parser.ParseParams{Source: source})
+	if err != nil {
+		return nil, err
+	}
+
+	document := &Document{
+		source:    source,
+		fragments: map[string]*ast.FragmentDefinition{},
+	}
+	var operations []*ast.OperationDefinition
+	for _, definition := range parsed.Definitions {
+		switch definition := definition.(type) {
+		case *ast.OperationDefinition:
+			operations = append(operations, definition)
+		case *ast.FragmentDefinition:
+			document.fragments[definition.Name.Value] = definition
+		default:
+			return nil, fmt.Errorf("unsupported definition %s", definition.GetKind())
+		}
+	}
+
+	switch {
+	case len(operations) == 0:
+		return nil, fmt.Errorf("document has no operation")
+	case operationName == "" && len(operations) > 1:
+		return nil, fmt.Errorf("document has %d operations, an operation name is required", len(operations))
+	case operationName == "":
+		document.operation = operations[0]
+	default:
+		for _, operation := range operations {
+			if operation.Name != nil && operation.Name.Value == operationName {
+				document.operation = operation
+			}
+		}
+		if document.operation == nil {
+			return nil, fmt.Errorf("document has no operation named %q", operationName)
+		}
+	}
+
+	if document.operation.Operation != ast.OperationTypeQuery {
+		return nil, fmt.Errorf("operation is a %s, only queries can be run", document.operation.Operation)
+	}
+	return document, nil
+}
+
+// Request returns the request running the selected operation of the
+// document with the given variables
+func (d *Document) Request(variables map[string]interface{}) *GraphQLRequest {
+	request := &GraphQLRequest{
+		Query:     d.source,
+		Variables: variables,
+	}
+	if d.operation.Name != nil {
+		request.OperationName = d.operation.Name.Value
+	}
+	return request
+}
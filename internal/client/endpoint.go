@@ -0,0 +1,177 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// EndpointLimits protects an endpoint shared by many callers: a token bucket
+// bounds the rate of requests sent to it, and a circuit breaker stops
+// sending them for a while after consecutive failures
+type EndpointLimits struct {
+	// QPS is the sustained rate of requests to the endpoint. Zero disables
+	// rate limiting.
+	QPS float64
+
+	// Burst is the number of requests sent at once before QPS applies
+	Burst int
+
+	// FailureThreshold is the number of consecutive transient failures
+	// opening the breaker. Zero disables the breaker.
+	FailureThreshold int
+
+	// OpenTimeout is how long the breaker first stays open. It doubles every
+	// time the request probing the endpoint afterwards fails, up to
+	// MaxOpenTimeout.
+	OpenTimeout    time.Duration
+	MaxOpenTimeout time.Duration
+}
+
+// DefaultEndpointLimits are the limits of a Registry created without others
+var DefaultEndpointLimits = EndpointLimits{
+	QPS:              10,
+	Burst:            20,
+	FailureThreshold: 5,
+	OpenTimeout:      30 * time.Second,
+	MaxOpenTimeout:   10 * time.Minute,
+}
+
+// EndpointUnavailableError is returned without sending the request while the
+// breaker of an endpoint is open
+type EndpointUnavailableError struct {
+	Endpoint string
+
+	// RetryAfter is how long until the breaker lets a request through
+	RetryAfter time.Duration
+}
+
+func (e *EndpointUnavailableError) Error() string {
+	return fmt.Sprintf("endpoint %s is unavailable after repeated failures, retrying in %s",
+		e.Endpoint, e.RetryAfter.Round(time.Second))
+}
+
+// endpointGuard applies the limits of an endpoint to every client sending
+// requests to it
+type endpointGuard struct {
+	endpoint string
+	limits   EndpointLimits
+	limiter  *rate.Limiter
+
+	// now is replaced by tests
+	now func() time.Time
+
+	mu sync.Mutex
+	// Consecutive transient failures, and consecutive times the breaker
+	// opened since the last success
+	failures int
+	trips    int
+	// openUntil is when the breaker lets a request probe the endpoint
+	openUntil time.Time
+	probing   bool
+}
+
+func newEndpointGuard(endpoint string, limits EndpointLimits) *endpointGuard {
+	limiter := rate.NewLimiter(rate.Inf, 0)
+	if limits.QPS > 0 {
+		limiter = rate.NewLimiter(rate.Limit(limits.QPS), max(limits.Burst, 1))
+	}
+	return &endpointGuard{
+		endpoint: endpoint,
+		limits:   limits,
+		limiter:  limiter,
+		now:      time.Now,
+	}
+}
+
+// acquire waits for the rate limiter to allow a request, or returns an
+// EndpointUnavailableError while the breaker is open. Once it has been open
+// for its timeout, a single request at a time probes the endpoint, and
+// acquire reports whether the request is that probe.
+func (g *endpointGuard) acquire(ctx context.Context) (bool, error) {
+	probe, err := g.allow()
+	if err != nil {
+		return false, err
+	}
+	if err := g.limiter.Wait(ctx); err != nil {
+		// The request is not sent, so another one may probe the endpoint
+		g.mu.Lock()
+		g.probing = g.probing && !probe
+		g.mu.Unlock()
+		return false, err
+	}
+	return probe, nil
+}
+
+// allow checks the breaker lets a request through, and whether it probes
+// the endpoint
+func (g *endpointGuard) allow() (bool, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.limits.FailureThreshold <= 0 || g.trips == 0 {
+		return false, nil
+	}
+	now := g.now()
+	switch {
+	case now.Before(g.openUntil):
+		return false, &EndpointUnavailableError{Endpoint: g.endpoint, RetryAfter: g.openUntil.Sub(now)}
+	case g.probing:
+		return false, &EndpointUnavailableError{Endpoint: g.endpoint, RetryAfter: g.limits.OpenTimeout}
+	}
+	g.probing = true
+	return true, nil
+}
+
+// record counts the outcome of a request allowed by acquire. Only transient
+// failures count against the endpoint: rejected requests show it is up,
+// while cancelled ones tell nothing.
+func (g *endpointGuard) record(probe bool, err error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if probe {
+		g.probing = false
+	}
+	switch {
+	case errors.Is(err, context.Canceled):
+		return
+	case err == nil || !IsTransient(err):
+		g.failures, g.trips = 0, 0
+		return
+	}
+
+	g.failures++
+	// Requests sent before the breaker opened do not open it again
+	opens := probe || (g.trips == 0 && g.failures >= g.limits.FailureThreshold)
+	if g.limits.FailureThreshold <= 0 || !opens {
+		return
+	}
+	g.trips++
+	timeout := g.limits.OpenTimeout
+	for i := 1; i < g.trips && timeout < g.limits.MaxOpenTimeout; i++ {
+		timeout *= 2
+	}
+	if g.limits.MaxOpenTimeout > 0 {
+		timeout = min(timeout, g.limits.MaxOpenTimeout)
+	}
+	g.openUntil = g.now().Add(timeout)
+}
@@ -0,0 +1,162 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Endpoint limits", func() {
+	var (
+		ctx      context.Context
+		requests atomic.Int32
+		status   atomic.Int32
+		server   *httptest.Server
+		limits   EndpointLimits
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		requests.Store(0)
+		status.Store(http.StatusServiceUnavailable)
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			requests.Add(1)
+			if code := int(status.Load()); code != http.StatusOK {
+				w.WriteHeader(code)
+				return
+			}
+			_, _ = fmt.Fprint(w, `{"data":{"clusters":[]}}`)
+		}))
+		DeferCleanup(server.Close)
+		limits = EndpointLimits{FailureThreshold: 2, OpenTimeout: time.Minute, MaxOpenTimeout: 3 * time.Minute}
+	})
+
+	// newClient returns a client of the endpoint from a new registry, whose
+	// clock is returned too
+	newClient := func(config Config) (*GraphQLClient, *time.Time) {
+		gqlClient, err := NewRegistry(time.Minute, limits).Client(server.URL, config)
+		Expect(err).NotTo(HaveOccurred())
+		now := time.Now()
+		gqlClient.guard.now = func() time.Time { return now }
+		return gqlClient, &now
+	}
+
+	unavailableFor := func(err error) time.Duration {
+		var unavailable *EndpointUnavailableError
+		Expect(errors.As(err, &unavailable)).To(BeTrue(), "%v", err)
+		Expect(unavailable.Endpoint).To(Equal(server.URL))
+		return unavailable.RetryAfter
+	}
+
+	It("should stop sending requests after consecutive failures", func() {
+		gqlClient, now := newClient(Config{})
+		gqlClient.retry = RetryPolicy{MaxAttempts: 1}
+
+		for range 2 {
+			_, err := gqlClient.ListClusters(ctx)
+			Expect(IsTransient(err)).To(BeTrue())
+		}
+		_, err := gqlClient.ListClusters(ctx)
+		Expect(unavailableFor(err)).To(Equal(time.Minute))
+		Expect(IsTransient(err)).To(BeFalse())
+		Expect(requests.Load()).To(BeEquivalentTo(2))
+
+		// A single request probes the endpoint once the breaker times out,
+		// and a failed probe doubles the timeout up to its maximum
+		for _, timeout := range []time.Duration{2 * time.Minute, 3 * time.Minute, 3 * time.Minute} {
+			*now = now.Add(3 * time.Minute)
+			_, err = gqlClient.ListClusters(ctx)
+			Expect(IsTransient(err)).To(BeTrue())
+			_, err = gqlClient.ListClusters(ctx)
+			Expect(unavailableFor(err)).To(Equal(timeout))
+		}
+		Expect(requests.Load()).To(BeEquivalentTo(5))
+
+		// A successful probe closes the breaker
+		status.Store(http.StatusOK)
+		*now = now.Add(3 * time.Minute)
+		_, err = gqlClient.ListClusters(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		_, err = gqlClient.ListClusters(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(requests.Load()).To(BeEquivalentTo(7))
+	})
+
+	It("should count the retries of a request", func() {
+		gqlClient, _ := newClient(Config{})
+		gqlClient.retry = RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond, MaxBackoff: time.Millisecond}
+
+		_, err := gqlClient.ListClusters(ctx)
+		unavailableFor(err)
+		Expect(requests.Load()).To(BeEquivalentTo(2))
+	})
+
+	It("should not count rejected requests against the endpoint", func() {
+		status.Store(http.StatusBadRequest)
+		gqlClient, _ := newClient(Config{})
+
+		for range 3 {
+			_, err := gqlClient.ListClusters(ctx)
+			var statusErr *HTTPStatusError
+			Expect(errors.As(err, &statusErr)).To(BeTrue())
+		}
+		Expect(requests.Load()).To(BeEquivalentTo(3))
+	})
+
+	It("should share the limits of an endpoint between configurations", func() {
+		registry := NewRegistry(time.Minute, limits)
+		first, err := registry.Client(server.URL, Config{BearerToken: "first"})
+		Expect(err).NotTo(HaveOccurred())
+		second, err := registry.Client(server.URL, Config{BearerToken: "second"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(second).NotTo(BeIdenticalTo(first))
+		first.retry = RetryPolicy{MaxAttempts: 1}
+
+		for range 2 {
+			_, err = first.ListClusters(ctx)
+			Expect(IsTransient(err)).To(BeTrue())
+		}
+		_, err = second.ListClusters(ctx)
+		unavailableFor(err)
+	})
+
+	It("should rate limit the requests to an endpoint", func() {
+		status.Store(http.StatusOK)
+		limits = EndpointLimits{QPS: 1, Burst: 2}
+		gqlClient, _ := newClient(Config{})
+
+		for range 2 {
+			_, err := gqlClient.ListClusters(ctx)
+			Expect(err).NotTo(HaveOccurred())
+		}
+		// The next token is a second away
+		deadline, cancel := context.WithTimeout(ctx, 100*time.Millisecond)
+		defer cancel()
+		_, err := gqlClient.ListClusters(deadline)
+		Expect(err).To(HaveOccurred())
+		Expect(requests.Load()).To(BeEquivalentTo(2))
+	})
+})
@@ -0,0 +1,123 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"syscall"
+)
+
+// ErrNotFound is returned when the object asked for does not exist in the
+// BSS API
+var ErrNotFound = errors.New("not found")
+
+// HTTPStatusError is returned when the endpoint answers with a status other
+// than 200 OK
+type HTTPStatusError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *HTTPStatusError) Error() string {
+	return fmt.Sprintf("unexpected status code %d: %s", e.StatusCode, e.Body)
+}
+
+// Transient reports whether the status may succeed when retried
+func (e *HTTPStatusError) Transient() bool {
+	switch e.StatusCode {
+	case http.StatusRequestTimeout, http.StatusTooManyRequests, http.StatusInternalServerError,
+		http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// Unauthorized reports whether the endpoint rejected the credentials
+func (e *HTTPStatusError) Unauthorized() bool {
+	return e.StatusCode == http.StatusUnauthorized || e.StatusCode == http.StatusForbidden
+}
+
+// GraphQLErrors is returned when the response of a GraphQL request carries
+// errors. The response is returned with it, since it may hold partial data.
+type GraphQLErrors struct {
+	Errors []GraphQLError
+}
+
+func (e *GraphQLErrors) Error() string {
+	messages := make([]string, 0, len(e.Errors))
+	for _, graphQLError := range e.Errors {
+		messages = append(messages, graphQLError.String())
+	}
+	return "graphql errors: " + strings.Join(messages, "; ")
+}
+
+// String returns the message of the error, prefixed with its path if any
+func (e GraphQLError) String() string {
+	if len(e.Path) == 0 {
+		return e.Message
+	}
+	path := make([]string, 0, len(e.Path))
+	for _, element := range e.Path {
+		path = append(path, fmt.Sprint(element))
+	}
+	return strings.Join(path, ".") + ": " + e.Message
+}
+
+// IsTransient reports whether a request failed for a reason that may go away
+// when it is retried: the endpoint could not be reached, timed out or
+// answered with a status such as 503. Errors of the request itself,
+// rejected credentials or certificates and cancelled contexts are not.
+func IsTransient(err error) bool {
+	var statusErr *HTTPStatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.Transient()
+	}
+
+	var (
+		unknownAuthority x509.UnknownAuthorityError
+		hostname         x509.HostnameError
+		invalid          x509.CertificateInvalidError
+		verification     *tls.CertificateVerificationError
+		alert            tls.AlertError
+		recordHeader     tls.RecordHeaderError
+	)
+	switch {
+	case errors.Is(err, context.Canceled),
+		errors.As(err, &unknownAuthority), errors.As(err, &hostname), errors.As(err, &invalid),
+		errors.As(err, &verification), errors.As(err, &alert), errors.As(err, &recordHeader):
+		return false
+	case errors.Is(err, context.DeadlineExceeded),
+		errors.Is(err, io.ErrUnexpectedEOF), errors.Is(err, io.EOF),
+		errors.Is(err, syscall.ECONNREFUSED), errors.Is(err, syscall.ECONNRESET):
+		return true
+	}
+
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
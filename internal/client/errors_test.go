@@ -0,0 +1,133 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Errors and retries", func() {
+	var (
+		ctx      context.Context
+		requests atomic.Int32
+		policy   = RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond, MaxBackoff: 5 * time.Millisecond}
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		requests.Store(0)
+	})
+
+	// newServer answers with the given status until the request numbered
+	// succeedAt, and with body afterwards
+	newServer := func(status, succeedAt int, body string) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			if int(requests.Add(1)) < succeedAt {
+				w.WriteHeader(status)
+				return
+			}
+			_, _ = fmt.Fprint(w, body)
+		}))
+	}
+
+	It("should retry transient failures with backoff", func() {
+		server := newServer(http.StatusServiceUnavailable, 3, `{"data":{"clusters":[]}}`)
+		defer server.Close()
+
+		_, err := NewGraphQLClient(server.URL, WithRetryPolicy(policy)).ListClusters(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(requests.Load()).To(BeEquivalentTo(3))
+	})
+
+	It("should give up on transient failures after the last attempt", func() {
+		server := newServer(http.StatusServiceUnavailable, 10, `{"data":{"clusters":[]}}`)
+		defer server.Close()
+
+		_, err := NewGraphQLClient(server.URL, WithRetryPolicy(policy)).ListClusters(ctx)
+		var statusErr *HTTPStatusError
+		Expect(errors.As(err, &statusErr)).To(BeTrue())
+		Expect(statusErr.StatusCode).To(Equal(http.StatusServiceUnavailable))
+		Expect(err).To(MatchError(ContainSubstring("failed after 3 attempts")))
+		Expect(IsTransient(err)).To(BeTrue())
+		Expect(requests.Load()).To(BeEquivalentTo(3))
+	})
+
+	It("should not retry permanent failures", func() {
+		server := newServer(http.StatusBadRequest, 10, "")
+		defer server.Close()
+
+		_, err := NewGraphQLClient(server.URL, WithRetryPolicy(policy)).ListClusters(ctx)
+		var statusErr *HTTPStatusError
+		Expect(err).To(BeAssignableToTypeOf(statusErr))
+		Expect(err.(*HTTPStatusError).StatusCode).To(Equal(http.StatusBadRequest))
+		Expect(IsTransient(err)).To(BeFalse())
+		Expect(requests.Load()).To(BeEquivalentTo(1))
+	})
+
+	It("should not retry creating a cluster", func() {
+		server := newServer(http.StatusServiceUnavailable, 10, "")
+		defer server.Close()
+
+		_, err := NewGraphQLClient(server.URL, WithRetryPolicy(policy)).CreateCluster(ctx, "demo", 1, "1.0.0")
+		Expect(err).To(HaveOccurred())
+		Expect(requests.Load()).To(BeEquivalentTo(1))
+	})
+
+	It("should report GraphQL errors with their path and extensions", func() {
+		server := newServer(http.StatusOK, 0, `{"data":null,"errors":[`+
+			`{"message":"boom","path":["clusters",0,"name"],"extensions":{"code":"INTERNAL"}}]}`)
+		defer server.Close()
+
+		_, err := NewGraphQLClient(server.URL, WithRetryPolicy(policy)).ListClusters(ctx)
+		var graphQLErr *GraphQLErrors
+		Expect(err).To(BeAssignableToTypeOf(graphQLErr))
+		graphQLErr = err.(*GraphQLErrors)
+		Expect(graphQLErr.Errors).To(HaveLen(1))
+		Expect(graphQLErr.Errors[0].Extensions).To(HaveKeyWithValue("code", "INTERNAL"))
+		Expect(err).To(MatchError("graphql errors: clusters.0.name: boom"))
+		Expect(requests.Load()).To(BeEquivalentTo(1))
+	})
+
+	It("should return ErrNotFound for a missing cluster", func() {
+		server := newServer(http.StatusOK, 0, `{"data":{"cluster":null}}`)
+		defer server.Close()
+
+		_, err := NewGraphQLClient(server.URL).GetCluster(ctx, "missing")
+		Expect(err).To(MatchError(ErrNotFound))
+	})
+
+	It("should stop retrying when the context is cancelled", func() {
+		server := newServer(http.StatusServiceUnavailable, 10, "")
+		defer server.Close()
+
+		cancelled, cancel := context.WithCancel(ctx)
+		cancel()
+		_, err := NewGraphQLClient(server.URL, WithRetryPolicy(policy)).ListClusters(cancelled)
+		Expect(err).To(MatchError(context.Canceled))
+		Expect(IsTransient(err)).To(BeFalse())
+		Expect(requests.Load()).To(BeZero())
+	})
+})
@@ -0,0 +1,96 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"sync"
+)
+
+// flightGroup coalesces identical requests: callers asking for a key that is
+// already in flight wait for its response instead of sending their own. The
+// request runs detached from the caller that started it, and is cancelled
+// once every caller waiting for it has given up.
+type flightGroup struct {
+	mu    sync.Mutex
+	calls map[string]*flight
+}
+
+// flight is a request in flight and the callers waiting for it
+type flight struct {
+	done    chan struct{}
+	resp    *GraphQLResponse
+	err     error
+	waiters int
+	cancel  context.CancelFunc
+}
+
+// do returns the response of the request with the given key, running fn to
+// send it unless it is already in flight
+func (g *flightGroup) do(ctx context.Context, key string,
+	fn func(context.Context) (*GraphQLResponse, error)) (*GraphQLResponse, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	g.mu.Lock()
+	if g.calls == nil {
+		g.calls = map[string]*flight{}
+	}
+	f, ok := g.calls[key]
+	if !ok {
+		flightCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+		f = &flight{done: make(chan struct{}), cancel: cancel}
+		g.calls[key] = f
+		go func() {
+			f.resp, f.err = fn(flightCtx)
+			cancel()
+			g.forget(key, f)
+			close(f.done)
+		}()
+	}
+	f.waiters++
+	g.mu.Unlock()
+
+	select {
+	case <-f.done:
+		return f.resp, f.err
+	case <-ctx.Done():
+		g.mu.Lock()
+		f.waiters--
+		if f.waiters == 0 {
+			// Later callers must not join a cancelled request
+			f.cancel()
+			g.forgetLocked(key, f)
+		}
+		g.mu.Unlock()
+		return nil, ctx.Err()
+	}
+}
+
+// forget removes a request from the group unless it was already replaced
+func (g *flightGroup) forget(key string, f *flight) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.forgetLocked(key, f)
+}
+
+func (g *flightGroup) forgetLocked(key string, f *flight) {
+	if g.calls[key] == f {
+		delete(g.calls, key)
+	}
+}
@@ -18,6 +18,7 @@ package client
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -29,22 +30,52 @@ import (
 type GraphQLClient struct {
 	endpoint   string
 	httpClient *http.Client
+
+	// transport pools the connections of the client to its endpoint
+	transport *http.Transport
+
+	// Headers sent with every request, including credentials
+	headers http.Header
+
+	retry RetryPolicy
+
+	// Identical requests in flight share one response
+	inflight flightGroup
+
+	// guard applies the limits of the endpoint, shared with the other
+	// clients of a Registry sending requests to it
+	guard *endpointGuard
 }
 
+// maxIdleConnsPerHost bounds the keep-alive connections a client pools to
+// its endpoint, which is shared by every query polling it
+const maxIdleConnsPerHost = 32
+
 // NewGraphQLClient creates a new GraphQL client
-func NewGraphQLClient(endpoint string) *GraphQLClient {
-	return &GraphQLClient{
+func NewGraphQLClient(endpoint string, opts ...ClientOption) *GraphQLClient {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.MaxIdleConnsPerHost = maxIdleConnsPerHost
+	c := &GraphQLClient{
 		endpoint: endpoint,
 		httpClient: &http.Client{
-			Timeout: 30 * time.Second,
+			Timeout:   30 * time.Second,
+			Transport: transport,
 		},
+		transport: transport,
+		headers:   http.Header{},
+		retry:     DefaultRetryPolicy,
+	}
+	for _, opt := range opts {
+		opt(c)
 	}
+	return c
 }
 
 // GraphQLRequest represents a GraphQL request
 type GraphQLRequest struct {
-	Query     string                 `json:"query"`
-	Variables map[string]interface{} `json:"variables,omitempty"`
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName,omitempty"`
+	Variables     map[string]interface{} `json:"variables,omitempty"`
 }
 
 // GraphQLResponse represents a GraphQL response
@@ -55,27 +86,80 @@ type GraphQLResponse struct {
 
 // GraphQLError represents a GraphQL error
 type GraphQLError struct {
-	Message string        `json:"message"`
-	Path    []interface{} `json:"path,omitempty"`
+	Message    string                 `json:"message"`
+	Path       []interface{}          `json:"path,omitempty"`
+	Extensions map[string]interface{} `json:"extensions,omitempty"`
 }
 
 // Execute executes a GraphQL query and returns the response
-func (c *GraphQLClient) Execute(query string, variables map[string]interface{}) (*GraphQLResponse, error) {
-	req := GraphQLRequest{
+func (c *GraphQLClient) Execute(ctx context.Context, query string, variables map[string]interface{}) (*GraphQLResponse, error) {
+	return c.ExecuteRequest(ctx, &GraphQLRequest{
 		Query:     query,
 		Variables: variables,
-	}
+	})
+}
 
+// ExecuteRequest executes a GraphQL request, which may select one of several
+// operations of its document by name, and returns the response. Transient
+// failures are retried according to the retry policy of the client.
+// Identical requests sent while one is in flight wait for its response
+// instead of sending their own, so the response must not be modified.
+func (c *GraphQLClient) ExecuteRequest(ctx context.Context, req *GraphQLRequest) (*GraphQLResponse, error) {
 	jsonData, err := json.Marshal(req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal request: %w", err)
 	}
+	return c.inflight.do(ctx, string(jsonData), func(ctx context.Context) (*GraphQLResponse, error) {
+		return c.execute(ctx, jsonData, c.retry.MaxAttempts)
+	})
+}
+
+// CloseIdleConnections closes the pooled connections of the client that are
+// not in use
+func (c *GraphQLClient) CloseIdleConnections() {
+	c.transport.CloseIdleConnections()
+}
+
+// execute sends a request up to the given number of attempts while it fails
+// transiently, within the limits of the endpoint
+func (c *GraphQLClient) execute(ctx context.Context, jsonData []byte, attempts int) (*GraphQLResponse, error) {
+	for attempt := 1; ; attempt++ {
+		var probe bool
+		if c.guard != nil {
+			var err error
+			if probe, err = c.guard.acquire(ctx); err != nil {
+				return nil, err
+			}
+		}
+		resp, err := c.do(ctx, jsonData)
+		if c.guard != nil {
+			c.guard.record(probe, err)
+		}
+		switch {
+		case err == nil || !IsTransient(err):
+			return resp, err
+		case attempt >= attempts:
+			if attempt > 1 {
+				err = fmt.Errorf("failed after %d attempts: %w", attempt, err)
+			}
+			return resp, err
+		}
+		if waitErr := c.retry.wait(ctx, attempt); waitErr != nil {
+			return nil, fmt.Errorf("%w after %d attempts: %w", waitErr, attempt, err)
+		}
+	}
+}
 
-	httpReq, err := http.NewRequest("POST", c.endpoint, bytes.NewBuffer(jsonData))
+// do sends a request once
+func (c *GraphQLClient) do(ctx context.Context, jsonData []byte) (*GraphQLResponse, error) {
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, c.endpoint, bytes.NewReader(jsonData))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
+	for name, values := range c.headers {
+		httpReq.Header[name] = values
+	}
 	httpReq.Header.Set("Content-Type", "application/json")
 
 	resp, err := c.httpClient.Do(httpReq)
@@ -88,7 +172,7 @@ func (c *GraphQLClient) Execute(query string, variables map[string]interface{})
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("unexpected status code %d: %s", resp.StatusCode, string(body))
+		return nil, &HTTPStatusError{StatusCode: resp.StatusCode, Body: string(body)}
 	}
 
 	var graphqlResp GraphQLResponse
@@ -97,7 +181,7 @@ func (c *GraphQLClient) Execute(query string, variables map[string]interface{})
 	}
 
 	if len(graphqlResp.Errors) > 0 {
-		return &graphqlResp, fmt.Errorf("graphql errors: %v", graphqlResp.Errors)
+		return &graphqlResp, &GraphQLErrors{Errors: graphqlResp.Errors}
 	}
 
 	return &graphqlResp, nil
@@ -115,8 +199,9 @@ type ClusterData struct {
 	LastUpdateTime time.Time `json:"lastUpdateTime"`
 }
 
-// GetCluster retrieves a single cluster by ID
-func (c *GraphQLClient) GetCluster(id string) (*ClusterData, error) {
+// GetCluster retrieves a single cluster by ID. It returns ErrNotFound if the
+// cluster does not exist.
+func (c *GraphQLClient) GetCluster(ctx context.Context, id string) (*ClusterData, error) {
 	query := `
 		query GetCluster($id: String!) {
 			cluster(id: $id) {
@@ -136,7 +221,7 @@ func (c *GraphQLClient) GetCluster(id string) (*ClusterData, error) {
 		"id": id,
 	}
 
-	resp, err := c.Execute(query, variables)
+	resp, err := c.Execute(ctx, query, variables)
 	if err != nil {
 		return nil, err
 	}
@@ -149,11 +234,14 @@ func (c *GraphQLClient) GetCluster(id string) (*ClusterData, error) {
 		return nil, fmt.Errorf("failed to unmarshal cluster data: %w", err)
 	}
 
+	if result.Cluster == nil {
+		return nil, fmt.Errorf("cluster %s: %w", id, ErrNotFound)
+	}
 	return result.Cluster, nil
 }
 
 // ListClusters retrieves all clusters
-func (c *GraphQLClient) ListClusters() ([]*ClusterData, error) {
+func (c *GraphQLClient) ListClusters(ctx context.Context) ([]*ClusterData, error) {
 	query := `
 		query ListClusters {
 			clusters {
@@ -169,7 +257,7 @@ func (c *GraphQLClient) ListClusters() ([]*ClusterData, error) {
 		}
 	`
 
-	resp, err := c.Execute(query, nil)
+	resp, err := c.Execute(ctx, query, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -185,8 +273,9 @@ func (c *GraphQLClient) ListClusters() ([]*ClusterData, error) {
 	return result.Clusters, nil
 }
 
-// CreateCluster creates a new cluster
-func (c *GraphQLClient) CreateCluster(name string, replicas int32, version string) (*ClusterData, error) {
+// CreateCluster creates a new cluster. It is not retried, since a failed
+// attempt may have created the cluster anyway.
+func (c *GraphQLClient) CreateCluster(ctx context.Context, name string, replicas int32, version string) (*ClusterData, error) {
 	query := `
 		mutation CreateCluster($name: String!, $replicas: Int!, $version: String!) {
 			createCluster(name: $name, replicas: $replicas, version: $version) {
@@ -208,7 +297,11 @@ func (c *GraphQLClient) CreateCluster(name string, replicas int32, version strin
 		"version":  version,
 	}
 
-	resp, err := c.Execute(query, variables)
+	jsonData, err := json.Marshal(&GraphQLRequest{Query: query, Variables: variables})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+	resp, err := c.execute(ctx, jsonData, 1)
 	if err != nil {
 		return nil, err
 	}
@@ -224,8 +317,9 @@ func (c *GraphQLClient) CreateCluster(name string, replicas int32, version strin
 	return result.CreateCluster, nil
 }
 
-// DeleteCluster deletes a cluster by ID
-func (c *GraphQLClient) DeleteCluster(id string) (bool, error) {
+// DeleteCluster deletes a cluster by ID. It returns false if the cluster did
+// not exist, so it is safe to retry.
+func (c *GraphQLClient) DeleteCluster(ctx context.Context, id string) (bool, error) {
 	query := `
 		mutation DeleteCluster($id: String!) {
 			deleteCluster(id: $id)
@@ -236,7 +330,7 @@ func (c *GraphQLClient) DeleteCluster(id string) (bool, error) {
 		"id": id,
 	}
 
-	resp, err := c.Execute(query, variables)
+	resp, err := c.Execute(ctx, query, variables)
 	if err != nil {
 		return false, err
 	}
@@ -0,0 +1,190 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sync"
+	"time"
+)
+
+// DefaultIdleTimeout is how long a client of a Registry is kept unused
+// before it is evicted
+const DefaultIdleTimeout = 5 * time.Minute
+
+// Config is the authentication and TLS configuration of a client
+type Config struct {
+	// BearerToken is sent in the Authorization header when set
+	BearerToken string
+
+	// BasicAuth authenticates with a username and password when set
+	BasicAuth *BasicAuth
+
+	// Headers are sent with every request
+	Headers map[string]string
+
+	// TLS secures the connection when set
+	TLS *TLSOptions
+}
+
+// BasicAuth is a username and password
+type BasicAuth struct {
+	Username string
+	Password string
+}
+
+// Options returns the options configuring a client with c
+func (c Config) Options() ([]ClientOption, error) {
+	// Static headers go first so credentials take precedence
+	opts := []ClientOption{WithHeaders(c.Headers)}
+	switch {
+	case c.BearerToken != "":
+		opts = append(opts, WithBearerToken(c.BearerToken))
+	case c.BasicAuth != nil:
+		opts = append(opts, WithBasicAuth(c.BasicAuth.Username, c.BasicAuth.Password))
+	}
+	if c.TLS != nil {
+		config, err := NewTLSConfig(*c.TLS)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, WithTLSConfig(config))
+	}
+	return opts, nil
+}
+
+// Registry shares clients between callers polling the same endpoint with
+// the same configuration, so they reuse pooled connections and coalesce
+// identical requests. The clients of an endpoint share its limits, whatever
+// their configuration. Clients unused for the idle timeout are evicted, which
+// also drops those of rotated credentials. It is a manager.Runnable that
+// evicts clients until the manager stops.
+type Registry struct {
+	idleTimeout time.Duration
+	limits      EndpointLimits
+
+	mu      sync.Mutex
+	clients map[string]*registeredClient
+	guards  map[string]*endpointGuard
+}
+
+type registeredClient struct {
+	client   *GraphQLClient
+	lastUsed time.Time
+}
+
+// NewRegistry creates a Registry evicting clients unused for idleTimeout and
+// applying limits to every endpoint
+func NewRegistry(idleTimeout time.Duration, limits EndpointLimits) *Registry {
+	return &Registry{
+		idleTimeout: idleTimeout,
+		limits:      limits,
+		clients:     map[string]*registeredClient{},
+		guards:      map[string]*endpointGuard{},
+	}
+}
+
+// Client returns the client of an endpoint and configuration, creating it
+// on first use
+func (r *Registry) Client(endpoint string, config Config) (*GraphQLClient, error) {
+	key, err := registryKey(endpoint, config)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	registered, ok := r.clients[key]
+	if !ok {
+		opts, err := config.Options()
+		if err != nil {
+			return nil, err
+		}
+		guard, ok := r.guards[endpoint]
+		if !ok {
+			guard = newEndpointGuard(endpoint, r.limits)
+			r.guards[endpoint] = guard
+		}
+		registered = &registeredClient{client: NewGraphQLClient(endpoint, opts...)}
+		registered.client.guard = guard
+		r.clients[key] = registered
+	}
+	registered.lastUsed = time.Now()
+	return registered.client, nil
+}
+
+// Len returns the number of clients in the registry
+func (r *Registry) Len() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.clients)
+}
+
+// EvictIdle evicts the clients unused since before now minus the idle
+// timeout and closes their connections, then forgets the limits of endpoints
+// left without clients. Requests in flight on an evicted client complete.
+func (r *Registry) EvictIdle(now time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	used := map[string]bool{}
+	for key, registered := range r.clients {
+		if now.Sub(registered.lastUsed) >= r.idleTimeout {
+			registered.client.CloseIdleConnections()
+			delete(r.clients, key)
+			continue
+		}
+		used[registered.client.endpoint] = true
+	}
+	for endpoint := range r.guards {
+		if !used[endpoint] {
+			delete(r.guards, endpoint)
+		}
+	}
+}
+
+// Start evicts idle clients until ctx is done, then closes every client
+func (r *Registry) Start(ctx context.Context) error {
+	ticker := time.NewTicker(r.idleTimeout / 2)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			r.EvictIdle(time.Now().Add(r.idleTimeout))
+			return nil
+		case now := <-ticker.C:
+			r.EvictIdle(now)
+		}
+	}
+}
+
+// registryKey identifies an endpoint and configuration without keeping the
+// credentials of the configuration
+func registryKey(endpoint string, config Config) (string, error) {
+	// Maps are marshalled with sorted keys, so equal configurations match
+	encoded, err := json.Marshal(struct {
+		Endpoint string
+		Config   Config
+	}{endpoint, config})
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(encoded)
+	return hex.EncodeToString(sum[:]), nil
+}
@@ -0,0 +1,135 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Registry", func() {
+	var (
+		ctx      context.Context
+		requests atomic.Int32
+		release  chan struct{}
+		server   *httptest.Server
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		requests.Store(0)
+		release = make(chan struct{})
+		// Responses are held until released, so concurrent requests overlap
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			requests.Add(1)
+			<-release
+			_, _ = fmt.Fprint(w, `{"data":{"clusters":[]}}`)
+		}))
+		DeferCleanup(server.Close)
+	})
+
+	It("should share clients of the same endpoint and configuration", func() {
+		registry := NewRegistry(time.Minute, EndpointLimits{})
+		config := Config{BearerToken: "token", Headers: map[string]string{"X-Tenant": "a"}}
+
+		first, err := registry.Client(server.URL, config)
+		Expect(err).NotTo(HaveOccurred())
+		second, err := registry.Client(server.URL, Config{BearerToken: "token", Headers: map[string]string{"X-Tenant": "a"}})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(second).To(BeIdenticalTo(first))
+
+		rotated, err := registry.Client(server.URL, Config{BearerToken: "rotated", Headers: config.Headers})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(rotated).NotTo(BeIdenticalTo(first))
+		other, err := registry.Client(server.URL+"/other", config)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(other).NotTo(BeIdenticalTo(first))
+		Expect(registry.Len()).To(Equal(3))
+	})
+
+	It("should evict clients unused for the idle timeout", func() {
+		registry := NewRegistry(time.Minute, EndpointLimits{})
+		first, err := registry.Client(server.URL, Config{})
+		Expect(err).NotTo(HaveOccurred())
+
+		registry.EvictIdle(time.Now())
+		Expect(registry.Len()).To(Equal(1))
+
+		registry.EvictIdle(time.Now().Add(time.Minute))
+		Expect(registry.Len()).To(BeZero())
+		second, err := registry.Client(server.URL, Config{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(second).NotTo(BeIdenticalTo(first))
+	})
+
+	It("should reject invalid TLS configurations", func() {
+		_, err := NewRegistry(time.Minute, EndpointLimits{}).Client(server.URL, Config{TLS: &TLSOptions{CABundle: []byte("not a certificate")}})
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should coalesce identical requests in flight", func() {
+		gqlClient := NewGraphQLClient(server.URL)
+
+		var wg sync.WaitGroup
+		for range 5 {
+			wg.Add(1)
+			go func() {
+				defer GinkgoRecover()
+				defer wg.Done()
+				_, err := gqlClient.ListClusters(ctx)
+				Expect(err).NotTo(HaveOccurred())
+			}()
+		}
+		Eventually(requests.Load).Should(BeEquivalentTo(1))
+		// Give the other callers time to join the request in flight
+		time.Sleep(50 * time.Millisecond)
+		close(release)
+		wg.Wait()
+		Expect(requests.Load()).To(BeEquivalentTo(1))
+	})
+
+	It("should cancel a coalesced request once every caller gave up", func() {
+		gqlClient := NewGraphQLClient(server.URL, WithRetryPolicy(RetryPolicy{MaxAttempts: 1}))
+		cancelCtx, cancel := context.WithCancel(ctx)
+
+		errs := make(chan error, 2)
+		for range 2 {
+			go func() {
+				_, err := gqlClient.ListClusters(cancelCtx)
+				errs <- err
+			}()
+		}
+		Eventually(requests.Load).Should(BeEquivalentTo(1))
+		cancel()
+		Expect(<-errs).To(MatchError(context.Canceled))
+		Expect(<-errs).To(MatchError(context.Canceled))
+
+		// A later caller sends its own request
+		close(release)
+		_, err := gqlClient.ListClusters(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(requests.Load()).To(BeEquivalentTo(2))
+	})
+})
@@ -0,0 +1,75 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"math/rand/v2"
+	"time"
+)
+
+// RetryPolicy defines how requests that fail transiently are retried. The
+// delay before each retry doubles from InitialBackoff up to MaxBackoff and
+// is jittered, so clients failing together do not retry together.
+type RetryPolicy struct {
+	// MaxAttempts is the number of attempts of a request, including the
+	// first one. One disables retries.
+	MaxAttempts int
+
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+}
+
+// DefaultRetryPolicy is the retry policy of clients created without
+// WithRetryPolicy
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts:    3,
+	InitialBackoff: 200 * time.Millisecond,
+	MaxBackoff:     2 * time.Second,
+}
+
+// WithRetryPolicy sets how requests that fail transiently are retried
+func WithRetryPolicy(policy RetryPolicy) ClientOption {
+	return func(c *GraphQLClient) {
+		c.retry = policy
+	}
+}
+
+// backoff returns the jittered delay before the given retry, counted from 1
+func (p RetryPolicy) backoff(retry int) time.Duration {
+	ceiling := p.InitialBackoff << (retry - 1)
+	if ceiling > p.MaxBackoff || ceiling <= 0 {
+		ceiling = p.MaxBackoff
+	}
+	if ceiling <= 0 {
+		return 0
+	}
+	// Half of the delay is fixed so retries never come back to back
+	return ceiling/2 + rand.N(ceiling/2+1)
+}
+
+// wait sleeps for the delay before the given retry, or until ctx is done
+func (p RetryPolicy) wait(ctx context.Context, retry int) error {
+	timer := time.NewTimer(p.backoff(retry))
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,316 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/graphql-go/graphql/language/ast"
+)
+
+// Kinds of introspected types and type references
+const (
+	kindObject      = "OBJECT"
+	kindInterface   = "INTERFACE"
+	kindUnion       = "UNION"
+	kindScalar      = "SCALAR"
+	kindEnum        = "ENUM"
+	kindInputObject = "INPUT_OBJECT"
+	kindNonNull     = "NON_NULL"
+)
+
+// introspectionQuery asks for the types, fields and arguments that documents
+// are validated against
+const introspectionQuery = `
+	query IntrospectSchema {
+		__schema {
+			queryType { name }
+			types {
+				kind
+				name
+				fields(includeDeprecated: true) {
+					name
+					args { name defaultValue type { ...TypeRef } }
+					type { ...TypeRef }
+				}
+			}
+		}
+	}
+
+	fragment TypeRef on __Type {
+		kind name ofType { kind name ofType { kind name ofType { kind name ofType { kind name ofType { kind name } } } } }
+	}
+`
+
+// Schema is the schema of a GraphQL server as reported by introspection
+type Schema struct {
+	queryType string
+	types     map[string]*schemaType
+}
+
+type schemaType struct {
+	Kind   string        `json:"kind"`
+	Name   string        `json:"name"`
+	Fields []schemaField `json:"fields"`
+}
+
+type schemaField struct {
+	Name string        `json:"name"`
+	Args []schemaInput `json:"args"`
+	Type typeRef       `json:"type"`
+}
+
+type schemaInput struct {
+	Name         string  `json:"name"`
+	DefaultValue *string `json:"defaultValue"`
+	Type         typeRef `json:"type"`
+}
+
+type typeRef struct {
+	Kind   string   `json:"kind"`
+	Name   string   `json:"name"`
+	OfType *typeRef `json:"ofType"`
+}
+
+// namedType returns the name of the type wrapped by lists and non-nulls
+func (t *typeRef) namedType() string {
+	for t.OfType != nil {
+		t = t.OfType
+	}
+	return t.Name
+}
+
+// field returns the field of a type with the given name, or nil
+func (t *schemaType) field(name string) *schemaField {
+	for i := range t.Fields {
+		if t.Fields[i].Name == name {
+			return &t.Fields[i]
+		}
+	}
+	return nil
+}
+
+// Introspect retrieves the schema of the GraphQL server
+func (c *GraphQLClient) Introspect(ctx context.Context) (*Schema, error) {
+	resp, err := c.Execute(ctx, introspectionQuery, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to introspect schema: %w", err)
+	}
+
+	var result struct {
+		Schema struct {
+			QueryType struct {
+				Name string `json:"name"`
+			} `json:"queryType"`
+			Types []*schemaType `json:"types"`
+		} `json:"__schema"`
+	}
+	if err := json.Unmarshal(resp.Data, &result); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal schema: %w", err)
+	}
+
+	schema := &Schema{
+		queryType: result.Schema.QueryType.Name,
+		types:     make(map[string]*schemaType, len(result.Schema.Types)),
+	}
+	for _, t := range result.Schema.Types {
+		schema.types[t.Name] = t
+	}
+	if schema.types[schema.queryType] == nil {
+		return nil, fmt.Errorf("schema has no query type")
+	}
+	return schema, nil
+}
+
+// Validate checks that the operation of a document only selects fields and
+// passes arguments that exist in the schema, and that the variables match
+// the ones it declares
+func (s *Schema) Validate(document *Document, variables map[string]interface{}) error {
+	v := &documentValidator{
+		schema:    s,
+		document:  document,
+		fragments: map[string]bool{},
+	}
+	v.validateVariables(variables)
+	v.validateSelectionSet(document.operation.SelectionSet, s.types[s.queryType], "")
+	return errors.Join(v.errs...)
+}
+
+// documentValidator collects the errors of a document against a schema
+type documentValidator struct {
+	schema   *Schema
+	document *Document
+
+	// Fragments already validated, which also stops cycles
+	fragments map[string]bool
+
+	errs []error
+}
+
+func (v *documentValidator) errorf(format string, args ...interface{}) {
+	v.errs = append(v.errs, fmt.Errorf(format, args...))
+}
+
+// validateVariables checks the declared variable types exist, required
+// variables are given and no undeclared variable is
+func (v *documentValidator) validateVariables(variables map[string]interface{}) {
+	declared := map[string]bool{}
+	for _, definition := range v.document.operation.VariableDefinitions {
+		name := definition.Variable.Name.Value
+		declared[name] = true
+
+		typeName := astNamedType(definition.Type)
+		if t := v.schema.types[typeName]; t == nil {
+			v.errorf("variable $%s: unknown type %s", name, typeName)
+		} else if t.Kind != kindScalar && t.Kind != kindEnum && t.Kind != kindInputObject {
+			v.errorf("variable $%s: type %s is not an input type", name, typeName)
+		}
+
+		_, required := definition.Type.(*ast.NonNull)
+		if _, given := variables[name]; required && definition.DefaultValue == nil && !given {
+			v.errorf("variable $%s of required type %s was not provided", name, typeName)
+		}
+	}
+	for name := range variables {
+		if !declared[name] {
+			v.errorf("variable $%s is not declared by the operation", name)
+		}
+	}
+}
+
+// validateSelectionSet validates the selections made on a value of the given type
+func (v *documentValidator) validateSelectionSet(set *ast.SelectionSet, parent *schemaType, path string) {
+	for _, selection := range set.Selections {
+		switch selection := selection.(type) {
+		case *ast.Field:
+			v.validateField(selection, parent, path)
+		case *ast.InlineFragment:
+			fragmentType := parent
+			if selection.TypeCondition != nil {
+				if fragmentType = v.typeCondition(selection.TypeCondition, path); fragmentType == nil {
+					continue
+				}
+			}
+			v.validateSelectionSet(selection.SelectionSet, fragmentType, path)
+		case *ast.FragmentSpread:
+			name := selection.Name.Value
+			fragment := v.document.fragments[name]
+			if fragment == nil {
+				v.errorf("%s: unknown fragment %s", pathOrRoot(path), name)
+				continue
+			}
+			if v.fragments[name] {
+				continue
+			}
+			v.fragments[name] = true
+			if fragmentType := v.typeCondition(fragment.TypeCondition, path); fragmentType != nil {
+				v.validateSelectionSet(fragment.SelectionSet, fragmentType, path)
+			}
+		}
+	}
+}
+
+// validateField validates a field selected on the given type, its arguments
+// and its own selections
+func (v *documentValidator) validateField(field *ast.Field, parent *schemaType, path string) {
+	name := field.Name.Value
+	responseKey := name
+	if field.Alias != nil {
+		responseKey = field.Alias.Value
+	}
+	fieldPath := responseKey
+	if path != "" {
+		fieldPath = path + "." + responseKey
+	}
+
+	// Introspection fields are answered by every server
+	if strings.HasPrefix(name, "__") {
+		return
+	}
+
+	schemaField := parent.field(name)
+	if schemaField == nil {
+		v.errorf("%s: type %s has no field %s", fieldPath, parent.Name, name)
+		return
+	}
+
+	given := map[string]bool{}
+	for _, argument := range field.Arguments {
+		given[argument.Name.Value] = true
+		found := false
+		for _, arg := range schemaField.Args {
+			found = found || arg.Name == argument.Name.Value
+		}
+		if !found {
+			v.errorf("%s: field %s.%s has no argument %s", fieldPath, parent.Name, name, argument.Name.Value)
+		}
+	}
+	for _, arg := range schemaField.Args {
+		if arg.Type.Kind == kindNonNull && arg.DefaultValue == nil && !given[arg.Name] {
+			v.errorf("%s: required argument %s of field %s.%s is missing", fieldPath, arg.Name, parent.Name, name)
+		}
+	}
+
+	fieldType := v.schema.types[schemaField.Type.namedType()]
+	composite := fieldType != nil &&
+		(fieldType.Kind == kindObject || fieldType.Kind == kindInterface || fieldType.Kind == kindUnion)
+	switch {
+	case composite && field.SelectionSet == nil:
+		v.errorf("%s: fields of type %s must be selected", fieldPath, fieldType.Name)
+	case !composite && field.SelectionSet != nil:
+		v.errorf("%s: type %s has no fields to select", fieldPath, schemaField.Type.namedType())
+	case composite:
+		v.validateSelectionSet(field.SelectionSet, fieldType, fieldPath)
+	}
+}
+
+// typeCondition returns the type of a fragment, or nil if the schema has no
+// such type
+func (v *documentValidator) typeCondition(condition *ast.Named, path string) *schemaType {
+	t := v.schema.types[condition.Name.Value]
+	if t == nil {
+		v.errorf("%s: unknown type %s", pathOrRoot(path), condition.Name.Value)
+	}
+	return t
+}
+
+// astNamedType returns the name of the type wrapped by lists and non-nulls
+func astNamedType(t ast.Type) string {
+	for {
+		switch wrapped := t.(type) {
+		case *ast.NonNull:
+			t = wrapped.Type
+		case *ast.List:
+			t = wrapped.Type
+		case *ast.Named:
+			return wrapped.Name.Value
+		default:
+			return ""
+		}
+	}
+}
+
+func pathOrRoot(path string) string {
+	if path == "" {
+		return "query"
+	}
+	return path
+}
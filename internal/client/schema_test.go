@@ -0,0 +1,154 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/graphql-go/graphql"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// newSchemaServer serves a schema shaped like the one of bss-api
+func newSchemaServer() *httptest.Server {
+	clusterType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Cluster",
+		Fields: graphql.Fields{
+			"id":       &graphql.Field{Type: graphql.String},
+			"name":     &graphql.Field{Type: graphql.String},
+			"replicas": &graphql.Field{Type: graphql.Int},
+		},
+	})
+	schema, err := graphql.NewSchema(graphql.SchemaConfig{
+		Query: graphql.NewObject(graphql.ObjectConfig{
+			Name: "Query",
+			Fields: graphql.Fields{
+				"cluster": &graphql.Field{
+					Type: clusterType,
+					Args: graphql.FieldConfigArgument{
+						"id": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+					},
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						return map[string]interface{}{"id": p.Args["id"], "name": "demo", "replicas": 3}, nil
+					},
+				},
+				"clusters": &graphql.Field{
+					Type: graphql.NewList(clusterType),
+					Resolve: func(graphql.ResolveParams) (interface{}, error) {
+						return []interface{}{}, nil
+					},
+				},
+			},
+		}),
+	})
+	Expect(err).NotTo(HaveOccurred())
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request GraphQLRequest
+		Expect(json.NewDecoder(r.Body).Decode(&request)).To(Succeed())
+		result := graphql.Do(graphql.Params{
+			Schema:         schema,
+			RequestString:  request.Query,
+			OperationName:  request.OperationName,
+			VariableValues: request.Variables,
+		})
+		Expect(json.NewEncoder(w).Encode(result)).To(Succeed())
+	}))
+}
+
+var _ = Describe("GraphQL documents", func() {
+	var (
+		server *httptest.Server
+		client *GraphQLClient
+	)
+
+	BeforeEach(func() {
+		server = newSchemaServer()
+		client = NewGraphQLClient(server.URL)
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	Context("When parsing a document", func() {
+		It("should select the named operation and reject mutations", func() {
+			source := `
+				query One($id: String!) { cluster(id: $id) { name } }
+				query All { clusters { name } }
+				mutation Drop { deleteCluster(id: "1") }
+			`
+			_, err := ParseDocument(source, "")
+			Expect(err).To(MatchError(ContainSubstring("an operation name is required")))
+			_, err = ParseDocument(source, "Drop")
+			Expect(err).To(MatchError(ContainSubstring("only queries can be run")))
+			_, err = ParseDocument(source, "Missing")
+			Expect(err).To(MatchError(ContainSubstring(`no operation named "Missing"`)))
+
+			document, err := ParseDocument(source, "One")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(document.Request(nil).OperationName).To(Equal("One"))
+		})
+	})
+
+	Context("When validating a document against the schema", func() {
+		It("should admit a document selecting known fields and run it", func() {
+			schema, err := client.Introspect(context.Background())
+			Expect(err).NotTo(HaveOccurred())
+
+			document, err := ParseDocument(`
+				query One($id: String!) { cluster(id: $id) { ...Summary size: replicas } }
+				fragment Summary on Cluster { id name __typename }
+			`, "")
+			Expect(err).NotTo(HaveOccurred())
+			variables := map[string]interface{}{"id": "c-1"}
+			Expect(schema.Validate(document, variables)).To(Succeed())
+
+			resp, err := client.ExecuteRequest(context.Background(), document.Request(variables))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(resp.Data).To(MatchJSON(`{"cluster": {"id": "c-1", "name": "demo", "__typename": "Cluster", "size": 3}}`))
+		})
+
+		It("should report unknown fields, arguments and variables", func() {
+			schema, err := client.Introspect(context.Background())
+			Expect(err).NotTo(HaveOccurred())
+
+			document, err := ParseDocument(`
+				query One($id: String!, $limit: Limit) {
+					cluster(region: "eu") { name owner }
+					clusters
+				}
+			`, "")
+			Expect(err).NotTo(HaveOccurred())
+			err = schema.Validate(document, map[string]interface{}{"extra": true})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(And(
+				ContainSubstring("variable $limit: unknown type Limit"),
+				ContainSubstring("variable $id of required type String was not provided"),
+				ContainSubstring("variable $extra is not declared"),
+				ContainSubstring("field Query.cluster has no argument region"),
+				ContainSubstring("required argument id of field Query.cluster is missing"),
+				ContainSubstring("cluster.owner: type Cluster has no field owner"),
+				ContainSubstring("clusters: fields of type Cluster must be selected"),
+			))
+		})
+	})
+})
@@ -0,0 +1,186 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+
+	bssv1alpha1 "github.com/brmorris/bss-operator/api/v1alpha1"
+	"github.com/brmorris/bss-operator/internal/builder"
+	"github.com/brmorris/bss-operator/internal/resources"
+)
+
+// childResource is a registered kind of child resource of a BssCluster
+type childResource struct {
+	reconciler resources.Child
+
+	// predicate filters events from owned children of this kind. Children
+	// without a predicate are not owned by the BssCluster and are not watched.
+	predicate predicate.Predicate
+}
+
+// registerChildren lists the children of a BssCluster. They are reconciled
+// and torn down in the same order:
+//
+//  1. the HTTPRoute and Ingress, cutting off external traffic
+//  2. the Services, cutting off internal traffic
+//  3. the NetworkPolicy
+//  4. the credentials Secret and the ConfigMap
+//  5. the HorizontalPodAutoscaler and PodDisruptionBudget
+//  6. the workload, and finally its volumes if they are not retained
+//
+// The Secret and ConfigMap are applied before the workload so new pods can
+// mount them. HTTPRoutes are only managed when the Gateway API is installed.
+func (r *BssClusterReconciler) registerChildren() {
+	var children []childResource
+	if r.gatewayAPI {
+		children = append(children, childResource{
+			reconciler: resources.NewChildReconciler(r.Client, r.applier,
+				resources.BuilderFunc[*gatewayv1.HTTPRoute](func(bssCluster *bssv1alpha1.BssCluster) *gatewayv1.HTTPRoute {
+					return builder.NewHTTPRouteBuilder(bssCluster).Build()
+				})).
+				WithEnabled(func(bssCluster *bssv1alpha1.BssCluster) bool {
+					return builder.Exposure(bssCluster) == bssv1alpha1.ExposureTypeHTTPRoute
+				}),
+			predicate: specPredicate(),
+		})
+	}
+	r.children = append(children, []childResource{
+		{
+			reconciler: resources.NewChildReconciler(r.Client, r.applier,
+				resources.BuilderFunc[*networkingv1.Ingress](func(bssCluster *bssv1alpha1.BssCluster) *networkingv1.Ingress {
+					return builder.NewIngressBuilder(bssCluster).Build()
+				})).
+				WithEnabled(func(bssCluster *bssv1alpha1.BssCluster) bool {
+					return builder.Exposure(bssCluster) == bssv1alpha1.ExposureTypeIngress
+				}),
+			predicate: specPredicate(),
+		},
+		{
+			reconciler: resources.NewChildReconciler(r.Client, r.applier,
+				resources.BuilderFunc[*corev1.Service](func(bssCluster *bssv1alpha1.BssCluster) *corev1.Service {
+					return builder.NewServiceBuilder(bssCluster).Build()
+				})),
+			predicate: servicePredicate(),
+		},
+		{
+			reconciler: resources.NewChildReconciler(r.Client, r.applier,
+				resources.BuilderFunc[*corev1.Service](func(bssCluster *bssv1alpha1.BssCluster) *corev1.Service {
+					return builder.NewHeadlessServiceBuilder(bssCluster).Build()
+				})).
+				WithEnabled(func(bssCluster *bssv1alpha1.BssCluster) bool {
+					return servesWorkload(bssCluster, bssv1alpha1.WorkloadTypeStatefulSet)
+				}),
+			predicate: servicePredicate(),
+		},
+		{
+			reconciler: resources.NewChildReconciler(r.Client, r.applier,
+				resources.BuilderFunc[*networkingv1.NetworkPolicy](
+					func(bssCluster *bssv1alpha1.BssCluster) *networkingv1.NetworkPolicy {
+						return builder.NewNetworkPolicyBuilder(bssCluster).WithOperatorNamespace(r.operatorNamespace).Build()
+					})).
+				WithEnabled(func(bssCluster *bssv1alpha1.BssCluster) bool {
+					return bssCluster.Spec.NetworkPolicy != nil
+				}),
+			predicate: specPredicate(),
+		},
+		{
+			reconciler: resources.NewChildReconciler(r.Client, r.applier,
+				resources.BuilderFunc[*corev1.Secret](func(bssCluster *bssv1alpha1.BssCluster) *corev1.Secret {
+					return builder.NewCredentialsSecretBuilder(bssCluster).Build()
+				})).
+				WithEnabled(builder.GeneratesCredentials).
+				WithMutate(resources.MutateCredentialsSecret),
+			predicate: secretPredicate(),
+		},
+		{
+			reconciler: resources.NewChildReconciler(r.Client, r.applier,
+				resources.BuilderFunc[*corev1.ConfigMap](func(bssCluster *bssv1alpha1.BssCluster) *corev1.ConfigMap {
+					return builder.NewConfigMapBuilder(bssCluster).Build()
+				})).
+				WithEnabled(func(bssCluster *bssv1alpha1.BssCluster) bool {
+					return bssCluster.Spec.Config != nil
+				}),
+			predicate: configMapPredicate(),
+		},
+		{
+			reconciler: resources.NewChildReconciler(r.Client, r.applier,
+				resources.BuilderFunc[*autoscalingv2.HorizontalPodAutoscaler](
+					func(bssCluster *bssv1alpha1.BssCluster) *autoscalingv2.HorizontalPodAutoscaler {
+						return builder.NewHorizontalPodAutoscalerBuilder(bssCluster).Build()
+					})).
+				WithEnabled(builder.Autoscaled),
+			predicate: horizontalPodAutoscalerPredicate(),
+		},
+		{
+			reconciler: resources.NewChildReconciler(r.Client, r.applier,
+				resources.BuilderFunc[*policyv1.PodDisruptionBudget](
+					func(bssCluster *bssv1alpha1.BssCluster) *policyv1.PodDisruptionBudget {
+						return builder.NewPodDisruptionBudgetBuilder(bssCluster).Build()
+					})).
+				WithEnabled(builder.HasPodDisruptionBudget),
+			predicate: specPredicate(),
+		},
+		{
+			reconciler: resources.NewChildReconciler(r.Client, r.applier,
+				resources.BuilderFunc[*appsv1.Deployment](func(bssCluster *bssv1alpha1.BssCluster) *appsv1.Deployment {
+					return builder.NewDeploymentBuilder(bssCluster).WithImageResolver(r.imageResolver).Build()
+				})).
+				WithEnabled(func(bssCluster *bssv1alpha1.BssCluster) bool {
+					return servesWorkload(bssCluster, bssv1alpha1.WorkloadTypeDeployment)
+				}).
+				WithMutate(resources.MutateDeployment),
+			predicate: deploymentPredicate(),
+		},
+		{
+			reconciler: resources.NewChildReconciler(r.Client, r.applier,
+				resources.BuilderFunc[*appsv1.StatefulSet](func(bssCluster *bssv1alpha1.BssCluster) *appsv1.StatefulSet {
+					return builder.NewStatefulSetBuilder(bssCluster).WithImageResolver(r.imageResolver).Build()
+				})).
+				WithEnabled(func(bssCluster *bssv1alpha1.BssCluster) bool {
+					return servesWorkload(bssCluster, bssv1alpha1.WorkloadTypeStatefulSet)
+				}).
+				WithMutate(resources.MutateStatefulSet),
+			predicate: statefulSetPredicate(),
+		},
+		{
+			reconciler: resources.NewPVCReconciler(r.Client, r.Scheme),
+		},
+	}...)
+}
+
+// child returns the registered child of the given kind
+func (r *BssClusterReconciler) child(kind string) resources.Child {
+	for _, child := range r.children {
+		if child.reconciler.Kind() == kind {
+			return child.reconciler
+		}
+	}
+	return nil
+}
+
+// servesWorkload reports whether a BssCluster needs the given workload: it is
+// either requested, or keeps serving until a migration away from it completes
+func servesWorkload(bssCluster *bssv1alpha1.BssCluster, kind bssv1alpha1.WorkloadType) bool {
+	return builder.Workload(bssCluster) == kind || bssCluster.Status.Workload == kind
+}
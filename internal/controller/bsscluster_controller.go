@@ -18,55 +18,133 @@ package controller
 
 import (
 	"context"
+	"fmt"
+	"time"
 
 	"github.com/go-logr/logr"
+	batchv1 "k8s.io/api/batch/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
+	ctrlbuilder "sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 
 	bssv1alpha1 "github.com/brmorris/bss-operator/api/v1alpha1"
+	"github.com/brmorris/bss-operator/internal/builder"
 	"github.com/brmorris/bss-operator/internal/resources"
 	"github.com/brmorris/bss-operator/internal/validation"
 )
 
-const (
-	// Phase constants for BssCluster status
-	phaseReconciling = "Reconciling"
-	phaseFailed      = "Failed"
-	phaseReady       = "Ready"
-)
-
 // BssClusterReconciler reconciles a BssCluster object
 type BssClusterReconciler struct {
 	client.Client
 	Scheme *runtime.Scheme
 
-	// Resource reconcilers
-	deploymentReconciler *resources.DeploymentReconciler
-	serviceReconciler    *resources.ServiceReconciler
+	// Children of a BssCluster in reconcile and teardown order
+	children []childResource
+
+	// Applier shared by the child reconcilers
+	applier *resources.Applier
+
+	// Resolves the bss-api image of the workloads
+	imageResolver builder.ImageResolver
 
 	// Validator
 	validator *validation.Validator
+
+	// Records Events on BssClusters, may be nil
+	recorder record.EventRecorder
+
+	// Hooks run before the children of a deleted BssCluster are torn down
+	preDeleteHooks []PreDeleteHook
+
+	// Whether the Gateway API is installed, enabling HTTPRoute exposure
+	gatewayAPI bool
+
+	// Namespace the operator runs in, allowed through BssCluster NetworkPolicies
+	operatorNamespace string
+}
+
+// BssClusterOption configures optional behaviour of a BssClusterReconciler
+type BssClusterOption func(*BssClusterReconciler)
+
+// WithPreDeleteHooks registers hooks that run, in order, before the children
+// of a deleted BssCluster are torn down
+func WithPreDeleteHooks(hooks ...PreDeleteHook) BssClusterOption {
+	return func(r *BssClusterReconciler) {
+		r.preDeleteHooks = append(r.preDeleteHooks, hooks...)
+	}
+}
+
+// WithImageRegistry sets the registry that bss-api images are pulled from
+// when a BssCluster does not set spec.image.repository
+func WithImageRegistry(registry string) BssClusterOption {
+	return func(r *BssClusterReconciler) {
+		r.imageResolver = builder.ImageResolver{Registry: registry}
+	}
+}
+
+// WithEventRecorder records an Event on the BssCluster whenever drift on one
+// of its children is corrected or its credentials are rotated
+func WithEventRecorder(recorder record.EventRecorder) BssClusterOption {
+	return func(r *BssClusterReconciler) {
+		r.recorder = recorder
+		r.applier.Recorder = recorder
+	}
+}
+
+// WithGatewayAPI manages HTTPRoutes for BssClusters exposed through the
+// Gateway API. Only set it when the Gateway API CRDs are installed.
+func WithGatewayAPI() BssClusterOption {
+	return func(r *BssClusterReconciler) {
+		r.gatewayAPI = true
+	}
+}
+
+// WithOperatorNamespace sets the namespace the operator runs in. The
+// NetworkPolicies of BssClusters allow ingress from it so BSSQuery can poll
+// bss-api.
+func WithOperatorNamespace(namespace string) BssClusterOption {
+	return func(r *BssClusterReconciler) {
+		r.operatorNamespace = namespace
+	}
 }
 
 // NewBssClusterReconciler creates a new BssClusterReconciler with all dependencies
-func NewBssClusterReconciler(c client.Client, scheme *runtime.Scheme) *BssClusterReconciler {
-	return &BssClusterReconciler{
-		Client:               c,
-		Scheme:               scheme,
-		deploymentReconciler: resources.NewDeploymentReconciler(c, scheme),
-		serviceReconciler:    resources.NewServiceReconciler(c, scheme),
-		validator:            validation.NewValidator(),
+func NewBssClusterReconciler(c client.Client, scheme *runtime.Scheme, opts ...BssClusterOption) *BssClusterReconciler {
+	r := &BssClusterReconciler{
+		Client:    c,
+		Scheme:    scheme,
+		applier:   resources.NewApplier(c, scheme),
+		validator: validation.NewValidator(),
+	}
+	for _, opt := range opts {
+		opt(r)
 	}
+	r.registerChildren()
+	return r
 }
 
 // +kubebuilder:rbac:groups=bss.localhost,resources=bssclusters,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=bss.localhost,resources=bssclusters/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=bss.localhost,resources=bssclusters/finalizers,verbs=update
 // +kubebuilder:rbac:groups=apps,resources=deployments,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=apps,resources=statefulsets,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=batch,resources=jobs,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=autoscaling,resources=horizontalpodautoscalers,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=policy,resources=poddisruptionbudgets,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=core,resources=pods,verbs=get;list;watch
+// +kubebuilder:rbac:groups=core,resources=configmaps,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=core,resources=events,verbs=create;patch
+// +kubebuilder:rbac:groups=core,resources=secrets,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=core,resources=persistentvolumeclaims,verbs=get;list;watch;update;patch;delete
 // +kubebuilder:rbac:groups=core,resources=services,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=networking.k8s.io,resources=ingresses,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=networking.k8s.io,resources=networkpolicies,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=gateway.networking.k8s.io,resources=httproutes,verbs=get;list;watch;create;update;patch;delete
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
@@ -89,64 +167,145 @@ func (r *BssClusterReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 
 	log.Info("Reconciling BssCluster", "name", bssCluster.Name, "namespace", bssCluster.Namespace)
 
+	// Tear down children in order once the BssCluster is being deleted
+	if !bssCluster.DeletionTimestamp.IsZero() {
+		return r.finalize(ctx, &bssCluster, log)
+	}
+
+	// A paused BssCluster is observed, but neither it nor its children are
+	// changed
+	if reason, _ := clusterPauseReason(&bssCluster); reason != "" {
+		return r.reconcilePaused(ctx, &bssCluster, log)
+	}
+	r.observePause(&bssCluster, log)
+
+	if err := r.ensureFinalizer(ctx, &bssCluster, log); err != nil {
+		log.Error(err, "Failed to add finalizer")
+		return ctrl.Result{}, err
+	}
+
 	// Validate the spec
-	if err := r.validator.Validate(&bssCluster); err != nil {
+	if err := r.validate(&bssCluster); err != nil {
 		log.Error(err, "BssCluster validation failed")
-		if statusErr := r.updateStatus(ctx, &bssCluster, phaseFailed); statusErr != nil {
+		setReconcileError(&bssCluster, ReasonInvalidSpec, err)
+		if statusErr := r.updateStatus(ctx, &bssCluster); statusErr != nil {
 			log.Error(statusErr, "Failed to update BssCluster status")
 			return ctrl.Result{}, statusErr
 		}
 		return ctrl.Result{}, err
 	}
 
-	// Update status to Reconciling
-	if err := r.updateStatus(ctx, &bssCluster, phaseReconciling); err != nil {
-		log.Error(err, "Failed to update BssCluster status to Reconciling")
-		return ctrl.Result{}, err
+	// Resolve the workload currently serving before children are enabled by it
+	if bssCluster.Status.Workload == "" {
+		active, err := r.activeWorkload(ctx, &bssCluster)
+		if err != nil {
+			log.Error(err, "Failed to determine the active workload")
+			return ctrl.Result{}, err
+		}
+		bssCluster.Status.Workload = active
 	}
 
-	// Reconcile all resources
-	if err := r.reconcileResources(ctx, &bssCluster, log); err != nil {
+	// Decide on a credentials rotation and the version to roll out before the
+	// children are stamped with them, then reconcile all resources
+	var upgradeAfter time.Duration
+	rotateAfter, err := r.reconcileCredentials(ctx, &bssCluster, log)
+	if err == nil {
+		upgradeAfter, err = r.reconcileUpgrade(ctx, &bssCluster, log)
+	}
+	if err == nil {
+		err = r.reconcileResources(ctx, &bssCluster, log)
+	}
+	if err != nil {
 		log.Error(err, "Failed to reconcile resources")
-		_ = r.updateStatus(ctx, &bssCluster, phaseFailed)
+		setReconcileError(&bssCluster, ReasonReconcileFailed, err)
+		if statusErr := r.updateStatus(ctx, &bssCluster); statusErr != nil {
+			log.Error(statusErr, "Failed to update BssCluster status")
+		}
 		return ctrl.Result{}, err
 	}
+	clearReconcileError(&bssCluster)
+	bssCluster.Status.URL = builder.GraphQLURL(&bssCluster)
 
-	// Update status to Ready
-	if err := r.updateStatus(ctx, &bssCluster, phaseReady); err != nil {
-		log.Error(err, "Failed to update BssCluster status to Ready")
+	// Derive availability and rollout progress from the owned workload
+	migrateAfter, err := r.observeWorkloads(ctx, &bssCluster, log)
+	if err != nil {
+		log.Error(err, "Failed to observe workload")
 		return ctrl.Result{}, err
 	}
 
+	if err := r.updateStatus(ctx, &bssCluster); err != nil {
+		log.Error(err, "Failed to update BssCluster status")
+		return ctrl.Result{}, err
+	}
+
+	// Roll out a held pod template change once the maintenance window opens
+	var windowAfter time.Duration
+	if progressing := meta.FindStatusCondition(bssCluster.Status.Conditions, TypeProgressing); progressing != nil &&
+		progressing.Reason == ReasonMaintenanceWindowPending {
+		windowAfter = max(builder.MaintenanceWindowWait(&bssCluster, time.Now()), time.Second)
+	}
+
 	log.Info("Successfully reconciled BssCluster", "name", bssCluster.Name)
-	return ctrl.Result{}, nil
+	return ctrl.Result{RequeueAfter: soonest(rotateAfter, upgradeAfter, windowAfter, migrateAfter)}, nil
 }
 
-// reconcileResources reconciles all child resources
-func (r *BssClusterReconciler) reconcileResources(ctx context.Context, bssCluster *bssv1alpha1.BssCluster, log logr.Logger) error {
-	// Reconcile Service first
-	if err := r.serviceReconciler.Reconcile(ctx, bssCluster, log); err != nil {
-		return err
+// soonest returns the shortest of the given requeue delays, ignoring zero
+// delays, or zero if every delay is
+func soonest(delays ...time.Duration) time.Duration {
+	var result time.Duration
+	for _, delay := range delays {
+		if delay > 0 && (result == 0 || delay < result) {
+			result = delay
+		}
 	}
+	return result
+}
 
-	// Reconcile Deployment
-	if err := r.deploymentReconciler.Reconcile(ctx, bssCluster, log); err != nil {
+// validate checks the spec, and that it can be served by this operator
+func (r *BssClusterReconciler) validate(bssCluster *bssv1alpha1.BssCluster) error {
+	if err := r.validator.Validate(bssCluster); err != nil {
 		return err
 	}
+	if builder.Exposure(bssCluster) == bssv1alpha1.ExposureTypeHTTPRoute && !r.gatewayAPI {
+		return fmt.Errorf("spec.exposure.type %s requires the Gateway API CRDs to be installed",
+			bssv1alpha1.ExposureTypeHTTPRoute)
+	}
+	return nil
+}
 
+// reconcileResources reconciles all child resources in registration order
+func (r *BssClusterReconciler) reconcileResources(ctx context.Context, bssCluster *bssv1alpha1.BssCluster, log logr.Logger) error {
+	for _, child := range r.children {
+		if err := child.reconciler.Reconcile(ctx, bssCluster, log); err != nil {
+			return fmt.Errorf("reconcile %s: %w", child.reconciler.Kind(), err)
+		}
+	}
 	return nil
 }
 
-// updateStatus updates the status of the BssCluster
-func (r *BssClusterReconciler) updateStatus(ctx context.Context, bssCluster *bssv1alpha1.BssCluster, phase string) error {
-	bssCluster.Status.Phase = phase
+// updateStatus writes the status of the BssCluster, stamping the observed generation
+func (r *BssClusterReconciler) updateStatus(ctx context.Context, bssCluster *bssv1alpha1.BssCluster) error {
+	bssCluster.Status.ObservedGeneration = bssCluster.Generation
 	return r.Status().Update(ctx, bssCluster)
 }
 
 // SetupWithManager sets up the controller with the Manager.
+// Owned children are watched so that drift and pod readiness changes
+// trigger a reconcile of the parent BssCluster, and upgrade Jobs so that
+// their completion moves the upgrade along.
 func (r *BssClusterReconciler) SetupWithManager(mgr ctrl.Manager) error {
-	return ctrl.NewControllerManagedBy(mgr).
-		For(&bssv1alpha1.BssCluster{}).
-		Named("bsscluster").
-		Complete(r)
+	b := ctrl.NewControllerManagedBy(mgr).
+		For(&bssv1alpha1.BssCluster{})
+	// Children of the same kind share a watch, filtered by the first predicate
+	owned := map[string]bool{}
+	for _, child := range r.children {
+		if child.predicate == nil || owned[child.reconciler.Kind()] {
+			continue
+		}
+		owned[child.reconciler.Kind()] = true
+		b = b.Owns(child.reconciler.Object(), ctrlbuilder.WithPredicates(child.predicate))
+	}
+	// Upgrade Jobs are created by the upgrade rather than registered as children
+	b = b.Owns(&batchv1.Job{}, ctrlbuilder.WithPredicates(jobPredicate()))
+	return b.Named("bsscluster").Complete(r)
 }
@@ -18,19 +18,35 @@ package controller
 
 import (
 	"context"
+	"fmt"
 	"time"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	policyv1 "k8s.io/api/policy/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	bssv1alpha1 "github.com/brmorris/bss-operator/api/v1alpha1"
+	"github.com/brmorris/bss-operator/internal/builder"
+	"github.com/brmorris/bss-operator/internal/resources"
 )
 
 const (
@@ -38,6 +54,31 @@ const (
 	interval = time.Millisecond * 250
 )
 
+// recordingHook is a PreDeleteHook that records the BssClusters it ran for
+type recordingHook struct {
+	calls []string
+	err   error
+}
+
+func (h *recordingHook) Name() string { return "recording" }
+
+func (h *recordingHook) PreDelete(_ context.Context, bssCluster *bssv1alpha1.BssCluster) error {
+	h.calls = append(h.calls, bssCluster.Name)
+	return h.err
+}
+
+// deleteAndFinalize deletes a BssCluster and drives its finalizer to completion
+func deleteAndFinalize(ctx context.Context, r *BssClusterReconciler, key types.NamespacedName) {
+	resource := &bssv1alpha1.BssCluster{}
+	Expect(k8sClient.Get(ctx, key, resource)).To(Succeed())
+	Expect(k8sClient.Delete(ctx, resource)).To(Succeed())
+
+	Eventually(func() bool {
+		_, _ = r.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		return errors.IsNotFound(k8sClient.Get(ctx, key, &bssv1alpha1.BssCluster{}))
+	}, timeout, interval).Should(BeTrue())
+}
+
 var _ = Describe("BssCluster Controller", func() {
 	Context("When reconciling a resource", func() {
 		const resourceName = "test-resource"
@@ -75,7 +116,7 @@ var _ = Describe("BssCluster Controller", func() {
 			Expect(err).NotTo(HaveOccurred())
 
 			By("Cleanup the specific resource instance BssCluster")
-			Expect(k8sClient.Delete(ctx, resource)).To(Succeed())
+			deleteAndFinalize(ctx, NewBssClusterReconciler(k8sClient, k8sClient.Scheme()), typeNamespacedName)
 		})
 
 		It("should successfully reconcile the resource", func() {
@@ -94,20 +135,1415 @@ var _ = Describe("BssCluster Controller", func() {
 				return k8sClient.Get(ctx, typeNamespacedName, deployment)
 			}, timeout, interval).Should(Succeed())
 
+			By("Checking that the pod template satisfies the restricted Pod Security Standard")
+			podSpec := deployment.Spec.Template.Spec
+			Expect(podSpec.SecurityContext).NotTo(BeNil())
+			Expect(podSpec.SecurityContext.RunAsNonRoot).To(HaveValue(BeTrue()))
+			Expect(podSpec.Containers).To(HaveLen(1))
+			Expect(podSpec.Containers[0].SecurityContext.AllowPrivilegeEscalation).To(HaveValue(BeFalse()))
+			Expect(podSpec.Containers[0].ReadinessProbe.HTTPGet.Path).To(Equal("/graphql"))
+			Expect(podSpec.Containers[0].ReadinessProbe.HTTPGet.Port.StrVal).To(Equal("http"))
+
 			// Verify Service was created
 			By("Checking that Service was created")
 			service := &corev1.Service{}
 			Eventually(func() error {
 				return k8sClient.Get(ctx, typeNamespacedName, service)
 			}, timeout, interval).Should(Succeed())
+			Expect(service.Spec.Type).To(Equal(corev1.ServiceTypeClusterIP))
+			Expect(service.Spec.Ports).To(HaveLen(1))
+			Expect(service.Spec.Ports[0].Port).To(Equal(int32(80)))
+			Expect(service.Spec.Ports[0].TargetPort.StrVal).To(Equal("http"))
 
-			// Verify status was updated
-			By("Checking that status was updated to Ready")
+			// Verify status reflects that no pods are running yet
+			By("Checking that status is not Available before pods are ready")
 			updatedCluster := &bssv1alpha1.BssCluster{}
-			Eventually(func() string {
-				_ = k8sClient.Get(ctx, typeNamespacedName, updatedCluster)
-				return updatedCluster.Status.Phase
-			}, timeout, interval).Should(Equal("Ready"))
+			Expect(k8sClient.Get(ctx, typeNamespacedName, updatedCluster)).To(Succeed())
+			Expect(meta.IsStatusConditionFalse(updatedCluster.Status.Conditions, TypeReconcileError)).To(BeTrue())
+			Expect(meta.IsStatusConditionFalse(updatedCluster.Status.Conditions, TypeAvailable)).To(BeTrue())
+			Expect(meta.IsStatusConditionTrue(updatedCluster.Status.Conditions, TypeProgressing)).To(BeTrue())
+			Expect(updatedCluster.Status.ObservedGeneration).To(Equal(updatedCluster.Generation))
+
+			// envtest runs no Deployment controller, so report the rollout as finished by hand
+			By("Marking the Deployment as rolled out")
+			deployment.Status = appsv1.DeploymentStatus{
+				ObservedGeneration: deployment.Generation,
+				Replicas:           1,
+				UpdatedReplicas:    1,
+				ReadyReplicas:      1,
+				AvailableReplicas:  1,
+				Conditions: []appsv1.DeploymentCondition{
+					{
+						Type:   appsv1.DeploymentAvailable,
+						Status: corev1.ConditionTrue,
+						Reason: ReasonMinimumReplicasAvailable,
+					},
+				},
+			}
+			Expect(k8sClient.Status().Update(ctx, deployment)).To(Succeed())
+
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{
+				NamespacedName: typeNamespacedName,
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			By("Checking that status was updated to Available")
+			Expect(k8sClient.Get(ctx, typeNamespacedName, updatedCluster)).To(Succeed())
+			Expect(meta.IsStatusConditionTrue(updatedCluster.Status.Conditions, TypeAvailable)).To(BeTrue())
+			Expect(meta.IsStatusConditionFalse(updatedCluster.Status.Conditions, TypeProgressing)).To(BeTrue())
+			Expect(meta.IsStatusConditionFalse(updatedCluster.Status.Conditions, TypeDegraded)).To(BeTrue())
+			Expect(updatedCluster.Status.ReadyReplicas).To(Equal(int32(1)))
+			Expect(updatedCluster.Status.UpdatedReplicas).To(Equal(int32(1)))
+			Expect(updatedCluster.Status.Image).To(Equal("bss-api:1.0.0"))
+		})
+
+		It("should revert drift on owned resources and record it", func() {
+			recorder := record.NewFakeRecorder(10)
+			controllerReconciler := NewBssClusterReconciler(k8sClient, k8sClient.Scheme(), WithEventRecorder(recorder))
+
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+
+			By("changing the Deployment outside the operator")
+			deployment := &appsv1.Deployment{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, deployment)).To(Succeed())
+			replicas := int32(5)
+			deployment.Spec.Replicas = &replicas
+			deployment.Labels["app.kubernetes.io/part-of"] = "something-else"
+			Expect(k8sClient.Update(ctx, deployment)).To(Succeed())
+
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+
+			By("checking that the changes were reverted")
+			Expect(k8sClient.Get(ctx, typeNamespacedName, deployment)).To(Succeed())
+			Expect(deployment.Spec.Replicas).To(HaveValue(Equal(int32(1))))
+			Expect(deployment.Labels).To(HaveKeyWithValue("app.kubernetes.io/part-of", "bss-operator"))
+
+			By("checking that the correction was recorded")
+			updatedCluster := &bssv1alpha1.BssCluster{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, updatedCluster)).To(Succeed())
+			Expect(updatedCluster.Status.LastDriftCorrection).NotTo(BeNil())
+			Expect(updatedCluster.Status.LastDriftCorrection.Kind).To(Equal("Deployment"))
+			Expect(updatedCluster.Status.LastDriftCorrection.Fields).To(ConsistOf(
+				"metadata.labels.app.kubernetes.io/part-of", "spec.replicas"))
+			Expect(recorder.Events).To(Receive(ContainSubstring(resources.EventReasonDriftCorrected)))
+
+			By("checking that an up-to-date Deployment is left alone")
+			resourceVersion := deployment.ResourceVersion
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(ctx, typeNamespacedName, deployment)).To(Succeed())
+			Expect(deployment.ResourceVersion).To(Equal(resourceVersion))
+			Expect(recorder.Events).NotTo(Receive())
+		})
+	})
+
+	Context("When configuring the image", func() {
+		const resourceName = "test-image"
+		const digest = "sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+
+		ctx := context.Background()
+		key := types.NamespacedName{Name: resourceName, Namespace: "default"}
+
+		BeforeEach(func() {
+			resource := &bssv1alpha1.BssCluster{
+				ObjectMeta: metav1.ObjectMeta{Name: resourceName, Namespace: "default"},
+				Spec: bssv1alpha1.BssClusterSpec{
+					Name:    "test-image",
+					Version: "1.0.0",
+					Image: &bssv1alpha1.ImageSpec{
+						PullPolicy:  corev1.PullAlways,
+						PullSecrets: []corev1.LocalObjectReference{{Name: "registry-credentials"}},
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, resource)).To(Succeed())
+		})
+
+		AfterEach(func() {
+			deleteAndFinalize(ctx, NewBssClusterReconciler(k8sClient, k8sClient.Scheme()), key)
+		})
+
+		It("should pull from the operator registry and report the running image", func() {
+			controllerReconciler := NewBssClusterReconciler(k8sClient, k8sClient.Scheme(),
+				WithImageRegistry("registry.example.com/bss/"))
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+			Expect(err).NotTo(HaveOccurred())
+
+			deployment := &appsv1.Deployment{}
+			Expect(k8sClient.Get(ctx, key, deployment)).To(Succeed())
+			podSpec := deployment.Spec.Template.Spec
+			Expect(podSpec.Containers[0].Image).To(Equal("registry.example.com/bss/bss-api:1.0.0"))
+			Expect(podSpec.Containers[0].ImagePullPolicy).To(Equal(corev1.PullAlways))
+			Expect(podSpec.ImagePullSecrets).To(ConsistOf(corev1.LocalObjectReference{Name: "registry-credentials"}))
+
+			By("pinning the image by digest")
+			bssCluster := &bssv1alpha1.BssCluster{}
+			Expect(k8sClient.Get(ctx, key, bssCluster)).To(Succeed())
+			bssCluster.Spec.Image.Repository = "mirror.example.com/bss-api"
+			bssCluster.Spec.Image.Digest = digest
+			Expect(k8sClient.Update(ctx, bssCluster)).To(Succeed())
+
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(ctx, key, deployment)).To(Succeed())
+			image := "mirror.example.com/bss-api@" + digest
+			Expect(deployment.Spec.Template.Spec.Containers[0].Image).To(Equal(image))
+
+			// envtest runs no Deployment controller or kubelet, so fake a finished rollout
+			By("marking the Deployment as rolled out with a running pod")
+			deployment.Status = appsv1.DeploymentStatus{
+				ObservedGeneration: deployment.Generation,
+				Replicas:           1,
+				UpdatedReplicas:    1,
+				ReadyReplicas:      1,
+				AvailableReplicas:  1,
+			}
+			Expect(k8sClient.Status().Update(ctx, deployment)).To(Succeed())
+
+			pod := &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      resourceName + "-0",
+					Namespace: "default",
+					Labels:    deployment.Spec.Template.Labels,
+				},
+				Spec: deployment.Spec.Template.Spec,
+			}
+			Expect(k8sClient.Create(ctx, pod)).To(Succeed())
+			DeferCleanup(func() {
+				Expect(k8sClient.Delete(ctx, pod)).To(Succeed())
+			})
+			pod.Status.ContainerStatuses = []corev1.ContainerStatus{{
+				Name:    "bss-api",
+				Image:   image,
+				ImageID: image,
+				Ready:   true,
+			}}
+			Expect(k8sClient.Status().Update(ctx, pod)).To(Succeed())
+
+			Eventually(func(g Gomega) {
+				_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+				g.Expect(err).NotTo(HaveOccurred())
+				g.Expect(k8sClient.Get(ctx, key, bssCluster)).To(Succeed())
+				g.Expect(bssCluster.Status.Image).To(Equal(image))
+				g.Expect(bssCluster.Status.ImageID).To(Equal(image))
+			}, timeout, interval).Should(Succeed())
+		})
+	})
+
+	Context("When configuring bss-api", func() {
+		const resourceName = "test-config"
+
+		ctx := context.Background()
+		key := types.NamespacedName{Name: resourceName, Namespace: "default"}
+		configMapKey := types.NamespacedName{Name: resourceName + "-config", Namespace: "default"}
+
+		BeforeEach(func() {
+			resource := &bssv1alpha1.BssCluster{
+				ObjectMeta: metav1.ObjectMeta{Name: resourceName, Namespace: "default"},
+				Spec: bssv1alpha1.BssClusterSpec{
+					Name:    "test-config",
+					Version: "1.0.0",
+					Config: &bssv1alpha1.ConfigSpec{
+						Settings: map[string]string{"logLevel": "info", "cacheSize": "128"},
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, resource)).To(Succeed())
+		})
+
+		AfterEach(func() {
+			deleteAndFinalize(ctx, NewBssClusterReconciler(k8sClient, k8sClient.Scheme()), key)
+		})
+
+		It("should mount the rendered config and roll the pods when it changes", func() {
+			controllerReconciler := NewBssClusterReconciler(k8sClient, k8sClient.Scheme())
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+			Expect(err).NotTo(HaveOccurred())
+
+			configMap := &corev1.ConfigMap{}
+			Expect(k8sClient.Get(ctx, configMapKey, configMap)).To(Succeed())
+			Expect(configMap.Data).To(HaveKeyWithValue("config.yaml", "cacheSize: \"128\"\nlogLevel: info\n"))
+
+			deployment := &appsv1.Deployment{}
+			Expect(k8sClient.Get(ctx, key, deployment)).To(Succeed())
+			template := deployment.Spec.Template
+			firstHash := template.Annotations[bssv1alpha1.AnnotationConfigHash]
+			Expect(firstHash).NotTo(BeEmpty())
+			Expect(template.Spec.Volumes).To(HaveLen(1))
+			Expect(template.Spec.Volumes[0].ConfigMap.Name).To(Equal(configMapKey.Name))
+			Expect(template.Spec.Containers[0].VolumeMounts).To(ContainElement(
+				HaveField("MountPath", "/etc/bss-api")))
+			Expect(template.Spec.Containers[0].Env).To(ContainElement(
+				corev1.EnvVar{Name: "BSS_API_CONFIG", Value: "/etc/bss-api/config.yaml"}))
+
+			By("replacing the settings with a raw config file")
+			bssCluster := &bssv1alpha1.BssCluster{}
+			Expect(k8sClient.Get(ctx, key, bssCluster)).To(Succeed())
+			bssCluster.Spec.Config = &bssv1alpha1.ConfigSpec{Raw: "logLevel: debug\n"}
+			Expect(k8sClient.Update(ctx, bssCluster)).To(Succeed())
+
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(ctx, configMapKey, configMap)).To(Succeed())
+			Expect(configMap.Data).To(HaveKeyWithValue("config.yaml", "logLevel: debug\n"))
+			Expect(k8sClient.Get(ctx, key, deployment)).To(Succeed())
+			Expect(deployment.Spec.Template.Annotations[bssv1alpha1.AnnotationConfigHash]).NotTo(Equal(firstHash))
+
+			By("removing the config")
+			Expect(k8sClient.Get(ctx, key, bssCluster)).To(Succeed())
+			bssCluster.Spec.Config = nil
+			Expect(k8sClient.Update(ctx, bssCluster)).To(Succeed())
+
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(errors.IsNotFound(k8sClient.Get(ctx, configMapKey, &corev1.ConfigMap{}))).To(BeTrue())
+			Expect(k8sClient.Get(ctx, key, deployment)).To(Succeed())
+			Expect(deployment.Spec.Template.Annotations).NotTo(HaveKey(bssv1alpha1.AnnotationConfigHash))
+			Expect(deployment.Spec.Template.Spec.Volumes).To(BeEmpty())
+		})
+	})
+
+	Context("When scaling through the scale subresource", func() {
+		const resourceName = "test-scale"
+
+		ctx := context.Background()
+		key := types.NamespacedName{Name: resourceName, Namespace: "default"}
+
+		BeforeEach(func() {
+			resource := &bssv1alpha1.BssCluster{
+				ObjectMeta: metav1.ObjectMeta{Name: resourceName, Namespace: "default"},
+				Spec: bssv1alpha1.BssClusterSpec{
+					Name:    "test-scale",
+					Version: "1.0.0",
+				},
+			}
+			Expect(k8sClient.Create(ctx, resource)).To(Succeed())
+		})
+
+		AfterEach(func() {
+			deleteAndFinalize(ctx, NewBssClusterReconciler(k8sClient, k8sClient.Scheme()), key)
+		})
+
+		It("should scale the Deployment and report replicas and selector", func() {
+			controllerReconciler := NewBssClusterReconciler(k8sClient, k8sClient.Scheme())
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+			Expect(err).NotTo(HaveOccurred())
+
+			bssCluster := &bssv1alpha1.BssCluster{}
+			Expect(k8sClient.Get(ctx, key, bssCluster)).To(Succeed())
+			scale := &autoscalingv1.Scale{}
+			Expect(k8sClient.SubResource("scale").Get(ctx, bssCluster, scale)).To(Succeed())
+			Expect(scale.Spec.Replicas).To(Equal(int32(1)))
+			Expect(scale.Status.Selector).To(Equal(
+				"app.kubernetes.io/instance=test-scale,app.kubernetes.io/name=bss-cluster"))
+
+			By("scaling to three replicas")
+			scale.Spec.Replicas = 3
+			Expect(k8sClient.SubResource("scale").Update(ctx, bssCluster, client.WithSubResourceBody(scale))).To(Succeed())
+			Expect(k8sClient.Get(ctx, key, bssCluster)).To(Succeed())
+			Expect(bssCluster.Spec.Replicas).To(Equal(ptr.To[int32](3)))
+
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+			Expect(err).NotTo(HaveOccurred())
+			deployment := &appsv1.Deployment{}
+			Expect(k8sClient.Get(ctx, key, deployment)).To(Succeed())
+			Expect(deployment.Spec.Replicas).To(Equal(ptr.To[int32](3)))
+
+			// envtest runs no Deployment controller, so report the new pods by hand
+			deployment.Status.Replicas = 3
+			Expect(k8sClient.Status().Update(ctx, deployment)).To(Succeed())
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.SubResource("scale").Get(ctx, bssCluster, scale)).To(Succeed())
+			Expect(scale.Status.Replicas).To(Equal(int32(3)))
+		})
+	})
+
+	Context("When autoscaling the cluster", func() {
+		const resourceName = "test-autoscaling"
+
+		ctx := context.Background()
+		key := types.NamespacedName{Name: resourceName, Namespace: "default"}
+
+		BeforeEach(func() {
+			resource := &bssv1alpha1.BssCluster{
+				ObjectMeta: metav1.ObjectMeta{Name: resourceName, Namespace: "default"},
+				Spec: bssv1alpha1.BssClusterSpec{
+					Name:    "test-autoscaling",
+					Version: "1.0.0",
+					Availability: &bssv1alpha1.AvailabilitySpec{
+						PodDisruptionBudget: &bssv1alpha1.PodDisruptionBudgetSpec{},
+					},
+					Autoscaling: &bssv1alpha1.AutoscalingSpec{
+						MinReplicas:                    ptr.To[int32](2),
+						MaxReplicas:                    5,
+						TargetCPUUtilizationPercentage: ptr.To[int32](70),
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, resource)).To(Succeed())
+		})
+
+		AfterEach(func() {
+			deleteAndFinalize(ctx, NewBssClusterReconciler(k8sClient, k8sClient.Scheme()), key)
+		})
+
+		It("should leave the replica count to the autoscaler", func() {
+			recorder := record.NewFakeRecorder(10)
+			controllerReconciler := NewBssClusterReconciler(k8sClient, k8sClient.Scheme(), WithEventRecorder(recorder))
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+			Expect(err).NotTo(HaveOccurred())
+
+			pdb := &policyv1.PodDisruptionBudget{}
+			Expect(k8sClient.Get(ctx, key, pdb)).To(Succeed())
+			Expect(pdb.Spec.MaxUnavailable).To(Equal(ptr.To(intstr.FromInt32(1))))
+
+			hpa := &autoscalingv2.HorizontalPodAutoscaler{}
+			Expect(k8sClient.Get(ctx, key, hpa)).To(Succeed())
+			Expect(hpa.Spec.ScaleTargetRef.Kind).To(Equal("Deployment"))
+			Expect(hpa.Spec.MinReplicas).To(Equal(ptr.To[int32](2)))
+			Expect(hpa.Spec.MaxReplicas).To(Equal(int32(5)))
+			Expect(hpa.Spec.Metrics).To(HaveLen(1))
+			Expect(hpa.Spec.Metrics[0].Resource.Target.AverageUtilization).To(Equal(ptr.To[int32](70)))
+
+			deployment := &appsv1.Deployment{}
+			Expect(k8sClient.Get(ctx, key, deployment)).To(Succeed())
+			Expect(deployment.Spec.Replicas).To(Equal(ptr.To[int32](2)))
+
+			By("scaling the Deployment as the autoscaler would")
+			deployment.Spec.Replicas = ptr.To[int32](4)
+			Expect(k8sClient.Update(ctx, deployment)).To(Succeed())
+
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(ctx, key, deployment)).To(Succeed())
+			Expect(deployment.Spec.Replicas).To(Equal(ptr.To[int32](4)))
+			Expect(recorder.Events).NotTo(Receive())
+
+			By("disabling autoscaling")
+			bssCluster := &bssv1alpha1.BssCluster{}
+			Expect(k8sClient.Get(ctx, key, bssCluster)).To(Succeed())
+			bssCluster.Spec.Autoscaling = nil
+			bssCluster.Spec.Replicas = ptr.To[int32](3)
+			Expect(k8sClient.Update(ctx, bssCluster)).To(Succeed())
+
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(errors.IsNotFound(k8sClient.Get(ctx, key, &autoscalingv2.HorizontalPodAutoscaler{}))).To(BeTrue())
+			Expect(k8sClient.Get(ctx, key, deployment)).To(Succeed())
+			Expect(deployment.Spec.Replicas).To(Equal(ptr.To[int32](3)))
+		})
+	})
+
+	Context("When managing credentials", func() {
+		const resourceName = "test-credentials"
+
+		ctx := context.Background()
+		key := types.NamespacedName{Name: resourceName, Namespace: "default"}
+		secretKey := types.NamespacedName{Name: resourceName + "-credentials", Namespace: "default"}
+
+		BeforeEach(func() {
+			resource := &bssv1alpha1.BssCluster{
+				ObjectMeta: metav1.ObjectMeta{Name: resourceName, Namespace: "default"},
+				Spec: bssv1alpha1.BssClusterSpec{
+					Name:        "test-credentials",
+					Version:     "1.0.0",
+					Credentials: &bssv1alpha1.CredentialsSpec{},
+				},
+			}
+			Expect(k8sClient.Create(ctx, resource)).To(Succeed())
+		})
+
+		AfterEach(func() {
+			deleteAndFinalize(ctx, NewBssClusterReconciler(k8sClient, k8sClient.Scheme()), key)
+		})
+
+		It("should generate credentials and rotate them on request", func() {
+			recorder := record.NewFakeRecorder(10)
+			controllerReconciler := NewBssClusterReconciler(k8sClient, k8sClient.Scheme(), WithEventRecorder(recorder))
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+			Expect(err).NotTo(HaveOccurred())
+
+			secret := &corev1.Secret{}
+			Expect(k8sClient.Get(ctx, secretKey, secret)).To(Succeed())
+			Expect(secret.Data).To(HaveKeyWithValue("admin-username", []byte("admin")))
+			password := secret.Data["admin-password"]
+			Expect(password).NotTo(BeEmpty())
+			Expect(secret.Data["api-key"]).NotTo(BeEmpty())
+
+			deployment := &appsv1.Deployment{}
+			Expect(k8sClient.Get(ctx, key, deployment)).To(Succeed())
+			Expect(deployment.Spec.Template.Spec.Containers[0].Env).To(ContainElement(corev1.EnvVar{
+				Name: "BSS_ADMIN_PASSWORD",
+				ValueFrom: &corev1.EnvVarSource{SecretKeyRef: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: secretKey.Name},
+					Key:                  "admin-password",
+				}},
+			}))
+			rotatedAt := deployment.Spec.Template.Annotations[bssv1alpha1.AnnotationCredentialsRotatedAt]
+			Expect(rotatedAt).NotTo(BeEmpty())
+
+			bssCluster := &bssv1alpha1.BssCluster{}
+			Expect(k8sClient.Get(ctx, key, bssCluster)).To(Succeed())
+			Expect(bssCluster.Status.Credentials).NotTo(BeNil())
+			Expect(bssCluster.Status.Credentials.SecretName).To(Equal(secretKey.Name))
+			lastRotation := bssCluster.Status.Credentials.LastRotationTime
+			Expect(lastRotation).NotTo(BeNil())
+
+			By("keeping the credentials across reconciles")
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(ctx, secretKey, secret)).To(Succeed())
+			Expect(secret.Data["admin-password"]).To(Equal(password))
+
+			By("requesting a rotation")
+			// Rotation times have a resolution of one second
+			time.Sleep(time.Second)
+			Expect(k8sClient.Get(ctx, key, bssCluster)).To(Succeed())
+			bssCluster.Annotations = map[string]string{bssv1alpha1.AnnotationRotateCredentials: "1"}
+			Expect(k8sClient.Update(ctx, bssCluster)).To(Succeed())
+
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(ctx, secretKey, secret)).To(Succeed())
+			Expect(secret.Data["admin-password"]).NotTo(Equal(password))
+			Expect(k8sClient.Get(ctx, key, deployment)).To(Succeed())
+			Expect(deployment.Spec.Template.Annotations[bssv1alpha1.AnnotationCredentialsRotatedAt]).NotTo(Equal(rotatedAt))
+			Expect(k8sClient.Get(ctx, key, bssCluster)).To(Succeed())
+			Expect(bssCluster.Status.Credentials.LastRotationTime.After(lastRotation.Time)).To(BeTrue())
+			Expect(bssCluster.Status.Credentials.ObservedRotationRequest).To(Equal("1"))
+
+			var event string
+			Expect(recorder.Events).To(Receive(&event))
+			Expect(event).To(ContainSubstring(EventReasonCredentialsRotated))
+			Expect(event).NotTo(ContainSubstring(string(password)))
+
+			// Rolling the pods for the rotation is not drift
+			Expect(bssCluster.Status.LastDriftCorrection).To(BeNil())
+			Expect(recorder.Events).NotTo(Receive(ContainSubstring(resources.EventReasonDriftCorrected)))
+		})
+
+		It("should not rotate again when the status of a rotation was lost", func() {
+			controllerReconciler := NewBssClusterReconciler(k8sClient, k8sClient.Scheme())
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+			Expect(err).NotTo(HaveOccurred())
+
+			secret := &corev1.Secret{}
+			Expect(k8sClient.Get(ctx, secretKey, secret)).To(Succeed())
+			password := secret.Data["admin-password"]
+			rotatedAt := secret.Annotations[bssv1alpha1.AnnotationCredentialsRotatedAt]
+			Expect(rotatedAt).NotTo(BeEmpty())
+
+			By("losing the credentials status after the Secret was applied")
+			time.Sleep(time.Second)
+			bssCluster := &bssv1alpha1.BssCluster{}
+			Expect(k8sClient.Get(ctx, key, bssCluster)).To(Succeed())
+			bssCluster.Status.Credentials = nil
+			Expect(k8sClient.Status().Update(ctx, bssCluster)).To(Succeed())
+
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(ctx, secretKey, secret)).To(Succeed())
+			Expect(secret.Data["admin-password"]).To(Equal(password))
+			Expect(secret.Annotations[bssv1alpha1.AnnotationCredentialsRotatedAt]).To(Equal(rotatedAt))
+			Expect(k8sClient.Get(ctx, key, bssCluster)).To(Succeed())
+			Expect(bssCluster.Status.Credentials.LastRotationTime.UTC().Format(time.RFC3339)).To(Equal(rotatedAt))
+		})
+
+		It("should mount a referenced Secret as files once it provides every key", func() {
+			bssCluster := &bssv1alpha1.BssCluster{}
+			Expect(k8sClient.Get(ctx, key, bssCluster)).To(Succeed())
+			bssCluster.Spec.Credentials = &bssv1alpha1.CredentialsSpec{
+				SecretName: "external-credentials",
+				Injection:  bssv1alpha1.CredentialsInjectionFile,
+			}
+			Expect(k8sClient.Update(ctx, bssCluster)).To(Succeed())
+
+			controllerReconciler := NewBssClusterReconciler(k8sClient, k8sClient.Scheme())
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+			Expect(err).To(MatchError(ContainSubstring("credentials Secret external-credentials not found")))
+
+			external := &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "external-credentials", Namespace: "default"},
+				Data:       map[string][]byte{"admin-username": []byte("root")},
+			}
+			Expect(k8sClient.Create(ctx, external)).To(Succeed())
+			DeferCleanup(func() {
+				Expect(k8sClient.Delete(ctx, external)).To(Succeed())
+			})
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+			Expect(err).To(MatchError(ContainSubstring("missing keys [admin-password api-key]")))
+
+			external.Data["admin-password"] = []byte("s3cret")
+			external.Data["api-key"] = []byte("key")
+			Expect(k8sClient.Update(ctx, external)).To(Succeed())
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+			Expect(err).NotTo(HaveOccurred())
+
+			deployment := &appsv1.Deployment{}
+			Expect(k8sClient.Get(ctx, key, deployment)).To(Succeed())
+			podSpec := deployment.Spec.Template.Spec
+			Expect(podSpec.Volumes).To(ContainElement(HaveField("Secret.SecretName", "external-credentials")))
+			Expect(podSpec.Containers[0].VolumeMounts).To(ContainElement(HaveField("MountPath", "/var/run/secrets/bss-api")))
+			Expect(podSpec.Containers[0].Env).NotTo(ContainElement(HaveField("Name", "BSS_ADMIN_PASSWORD")))
+			Expect(errors.IsNotFound(k8sClient.Get(ctx, secretKey, &corev1.Secret{}))).To(BeTrue())
+		})
+	})
+
+	Context("When exposing the cluster", func() {
+		const resourceName = "test-exposure"
+
+		ctx := context.Background()
+		key := types.NamespacedName{Name: resourceName, Namespace: "default"}
+
+		BeforeEach(func() {
+			resource := &bssv1alpha1.BssCluster{
+				ObjectMeta: metav1.ObjectMeta{Name: resourceName, Namespace: "default"},
+				Spec: bssv1alpha1.BssClusterSpec{
+					Name:    "test-exposure",
+					Version: "1.0.0",
+					Exposure: &bssv1alpha1.ExposureSpec{
+						Host:             "bss.example.com",
+						IngressClassName: ptr.To("nginx"),
+						TLS:              &bssv1alpha1.ExposureTLSSpec{SecretName: "bss-tls"},
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, resource)).To(Succeed())
+		})
+
+		AfterEach(func() {
+			deleteAndFinalize(ctx, NewBssClusterReconciler(k8sClient, k8sClient.Scheme()), key)
+		})
+
+		It("should route the host to the Service and report the URL", func() {
+			controllerReconciler := NewBssClusterReconciler(k8sClient, k8sClient.Scheme())
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+			Expect(err).NotTo(HaveOccurred())
+
+			ingress := &networkingv1.Ingress{}
+			Expect(k8sClient.Get(ctx, key, ingress)).To(Succeed())
+			Expect(ingress.Spec.IngressClassName).To(Equal(ptr.To("nginx")))
+			Expect(ingress.Spec.TLS).To(ConsistOf(networkingv1.IngressTLS{
+				Hosts: []string{"bss.example.com"}, SecretName: "bss-tls"}))
+			Expect(ingress.Spec.Rules).To(HaveLen(1))
+			path := ingress.Spec.Rules[0].HTTP.Paths[0]
+			Expect(path.Path).To(Equal("/graphql"))
+			Expect(path.Backend.Service.Name).To(Equal(resourceName))
+			Expect(path.Backend.Service.Port.Name).To(Equal("http"))
+
+			bssCluster := &bssv1alpha1.BssCluster{}
+			Expect(k8sClient.Get(ctx, key, bssCluster)).To(Succeed())
+			Expect(bssCluster.Status.URL).To(Equal("https://bss.example.com/graphql"))
+
+			By("switching to an HTTPRoute without the Gateway API installed")
+			bssCluster.Spec.Exposure = &bssv1alpha1.ExposureSpec{
+				Type:       bssv1alpha1.ExposureTypeHTTPRoute,
+				Host:       "bss.example.com",
+				ParentRefs: []gatewayv1.ParentReference{{Name: "public"}},
+			}
+			Expect(k8sClient.Update(ctx, bssCluster)).To(Succeed())
+
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+			Expect(err).To(MatchError(ContainSubstring("Gateway API")))
+			Expect(k8sClient.Get(ctx, key, bssCluster)).To(Succeed())
+			Expect(meta.IsStatusConditionTrue(bssCluster.Status.Conditions, TypeReconcileError)).To(BeTrue())
+
+			By("removing the exposure")
+			bssCluster.Spec.Exposure = nil
+			Expect(k8sClient.Update(ctx, bssCluster)).To(Succeed())
+
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(errors.IsNotFound(k8sClient.Get(ctx, key, &networkingv1.Ingress{}))).To(BeTrue())
+			Expect(k8sClient.Get(ctx, key, bssCluster)).To(Succeed())
+			Expect(bssCluster.Status.URL).To(BeEmpty())
+		})
+	})
+
+	Context("When restricting network traffic", func() {
+		const resourceName = "test-network-policy"
+
+		ctx := context.Background()
+		key := types.NamespacedName{Name: resourceName, Namespace: "default"}
+
+		BeforeEach(func() {
+			resource := &bssv1alpha1.BssCluster{
+				ObjectMeta: metav1.ObjectMeta{Name: resourceName, Namespace: "default"},
+				Spec: bssv1alpha1.BssClusterSpec{
+					Name:    "test-network-policy",
+					Version: "1.0.0",
+					NetworkPolicy: &bssv1alpha1.NetworkPolicySpec{
+						From: []networkingv1.NetworkPolicyPeer{{
+							PodSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "client"}},
+						}},
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, resource)).To(Succeed())
+		})
+
+		AfterEach(func() {
+			deleteAndFinalize(ctx, NewBssClusterReconciler(k8sClient, k8sClient.Scheme()), key)
+		})
+
+		It("should allow ingress from the operator namespace and the listed peers", func() {
+			controllerReconciler := NewBssClusterReconciler(k8sClient, k8sClient.Scheme(),
+				WithOperatorNamespace("bss-operator-system"))
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+			Expect(err).NotTo(HaveOccurred())
+
+			bssCluster := &bssv1alpha1.BssCluster{}
+			Expect(k8sClient.Get(ctx, key, bssCluster)).To(Succeed())
+
+			policy := &networkingv1.NetworkPolicy{}
+			Expect(k8sClient.Get(ctx, key, policy)).To(Succeed())
+			Expect(policy.Spec.PodSelector.MatchLabels).To(Equal(builder.SelectorLabels(bssCluster)))
+			Expect(policy.Spec.PolicyTypes).To(ConsistOf(networkingv1.PolicyTypeIngress))
+			Expect(policy.Spec.Ingress).To(HaveLen(1))
+			Expect(policy.Spec.Ingress[0].From).To(HaveLen(2))
+			Expect(policy.Spec.Ingress[0].From[0].NamespaceSelector.MatchLabels).To(
+				HaveKeyWithValue("kubernetes.io/metadata.name", "bss-operator-system"))
+			Expect(policy.Spec.Ingress[0].From[1].PodSelector.MatchLabels).To(HaveKeyWithValue("app", "client"))
+			Expect(policy.Spec.Ingress[0].Ports).To(HaveLen(1))
+			Expect(policy.Spec.Ingress[0].Ports[0].Port).To(Equal(ptr.To(intstr.FromString("http"))))
+
+			By("restricting egress")
+			bssCluster.Spec.NetworkPolicy.Egress = []networkingv1.NetworkPolicyEgressRule{{
+				Ports: []networkingv1.NetworkPolicyPort{{Port: ptr.To(intstr.FromInt32(53))}},
+			}}
+			Expect(k8sClient.Update(ctx, bssCluster)).To(Succeed())
+
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(ctx, key, policy)).To(Succeed())
+			Expect(policy.Spec.PolicyTypes).To(ConsistOf(networkingv1.PolicyTypeIngress, networkingv1.PolicyTypeEgress))
+			Expect(policy.Spec.Egress).To(HaveLen(1))
+
+			By("removing the NetworkPolicy")
+			Expect(k8sClient.Get(ctx, key, bssCluster)).To(Succeed())
+			bssCluster.Spec.NetworkPolicy = nil
+			Expect(k8sClient.Update(ctx, bssCluster)).To(Succeed())
+
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(errors.IsNotFound(k8sClient.Get(ctx, key, &networkingv1.NetworkPolicy{}))).To(BeTrue())
+		})
+	})
+
+	Context("When switching the workload mode", func() {
+		const resourceName = "test-migration"
+
+		ctx := context.Background()
+		key := types.NamespacedName{Name: resourceName, Namespace: "default"}
+
+		BeforeEach(func() {
+			resource := &bssv1alpha1.BssCluster{
+				ObjectMeta: metav1.ObjectMeta{Name: resourceName, Namespace: "default"},
+				Spec: bssv1alpha1.BssClusterSpec{
+					Name:    "test-migration",
+					Version: "1.0.0",
+				},
+			}
+			Expect(k8sClient.Create(ctx, resource)).To(Succeed())
+		})
+
+		AfterEach(func() {
+			deleteAndFinalize(ctx, NewBssClusterReconciler(k8sClient, k8sClient.Scheme()), key)
+		})
+
+		It("should keep the Deployment until the StatefulSet is ready", func() {
+			controllerReconciler := NewBssClusterReconciler(k8sClient, k8sClient.Scheme())
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+			Expect(err).NotTo(HaveOccurred())
+
+			By("switching the BssCluster to a StatefulSet with storage")
+			bssCluster := &bssv1alpha1.BssCluster{}
+			Expect(k8sClient.Get(ctx, key, bssCluster)).To(Succeed())
+			Expect(bssCluster.Status.Workload).To(Equal(bssv1alpha1.WorkloadTypeDeployment))
+			bssCluster.Spec.Workload = bssv1alpha1.WorkloadTypeStatefulSet
+			bssCluster.Spec.Storage = &bssv1alpha1.StorageSpec{Size: resource.MustParse("2Gi")}
+			bssCluster.Spec.PriorityClassName = "bss-critical"
+			bssCluster.Spec.Env = []corev1.EnvVar{{Name: "BSS_LOG_LEVEL", Value: "debug"}}
+			Expect(k8sClient.Update(ctx, bssCluster)).To(Succeed())
+
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+			Expect(err).NotTo(HaveOccurred())
+
+			statefulSet := &appsv1.StatefulSet{}
+			Expect(k8sClient.Get(ctx, key, statefulSet)).To(Succeed())
+			Expect(statefulSet.Spec.Template.Spec.PriorityClassName).To(Equal("bss-critical"))
+			Expect(statefulSet.Spec.Template.Spec.Containers[0].Env).To(ContainElement(
+				corev1.EnvVar{Name: "BSS_LOG_LEVEL", Value: "debug"}))
+			Expect(statefulSet.Spec.Template.Spec.Containers[0].VolumeMounts).To(HaveLen(1))
+			Expect(statefulSet.Spec.VolumeClaimTemplates).To(HaveLen(1))
+			Expect(statefulSet.Spec.VolumeClaimTemplates[0].Spec.Resources.Requests.Storage().String()).To(Equal("2Gi"))
+			Expect(statefulSet.Spec.ServiceName).To(Equal("test-migration-headless"))
+			headless := &corev1.Service{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: statefulSet.Spec.ServiceName, Namespace: key.Namespace},
+				headless)).To(Succeed())
+			Expect(headless.Spec.ClusterIP).To(Equal(corev1.ClusterIPNone))
+			Expect(k8sClient.Get(ctx, key, &appsv1.Deployment{})).To(Succeed())
+
+			Expect(k8sClient.Get(ctx, key, bssCluster)).To(Succeed())
+			Expect(bssCluster.Status.Workload).To(Equal(bssv1alpha1.WorkloadTypeDeployment))
+			progressing := meta.FindStatusCondition(bssCluster.Status.Conditions, TypeProgressing)
+			Expect(progressing).NotTo(BeNil())
+			Expect(progressing.Reason).To(Equal(ReasonMigratingWorkload))
+
+			// envtest runs no StatefulSet controller, so report the rollout as finished by hand
+			By("marking the StatefulSet as rolled out")
+			statefulSet.Status = appsv1.StatefulSetStatus{
+				ObservedGeneration: statefulSet.Generation,
+				Replicas:           1,
+				UpdatedReplicas:    1,
+				ReadyReplicas:      1,
+				AvailableReplicas:  1,
+				CurrentRevision:    "rev-1",
+				UpdateRevision:     "rev-1",
+			}
+			Expect(k8sClient.Status().Update(ctx, statefulSet)).To(Succeed())
+
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+			Expect(err).NotTo(HaveOccurred())
+
+			By("checking that the Deployment was retired")
+			Eventually(func() bool {
+				return errors.IsNotFound(k8sClient.Get(ctx, key, &appsv1.Deployment{}))
+			}, timeout, interval).Should(BeTrue())
+			Expect(k8sClient.Get(ctx, key, bssCluster)).To(Succeed())
+			Expect(bssCluster.Status.Workload).To(Equal(bssv1alpha1.WorkloadTypeStatefulSet))
+			Expect(meta.IsStatusConditionTrue(bssCluster.Status.Conditions, TypeAvailable)).To(BeTrue())
+		})
+
+		It("should keep the StatefulSet and its volumes until the Deployment is ready", func() {
+			controllerReconciler := NewBssClusterReconciler(k8sClient, k8sClient.Scheme())
+
+			By("running the BssCluster as a StatefulSet whose claims are deleted with it")
+			bssCluster := &bssv1alpha1.BssCluster{}
+			Expect(k8sClient.Get(ctx, key, bssCluster)).To(Succeed())
+			bssCluster.Spec.Workload = bssv1alpha1.WorkloadTypeStatefulSet
+			bssCluster.Spec.Storage = &bssv1alpha1.StorageSpec{
+				Size: resource.MustParse("1Gi"),
+				RetentionPolicy: &bssv1alpha1.StorageRetentionPolicy{
+					WhenDeleted: appsv1.DeletePersistentVolumeClaimRetentionPolicyType,
+				},
+			}
+			Expect(k8sClient.Update(ctx, bssCluster)).To(Succeed())
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+			Expect(err).NotTo(HaveOccurred())
+
+			statefulSet := &appsv1.StatefulSet{}
+			Expect(k8sClient.Get(ctx, key, statefulSet)).To(Succeed())
+			Expect(statefulSet.Spec.VolumeClaimTemplates).To(HaveLen(1))
+			uid := statefulSet.UID
+
+			// envtest runs no StatefulSet controller, so create its claim by hand
+			claim := &corev1.PersistentVolumeClaim{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "data-" + resourceName + "-0",
+					Namespace: key.Namespace,
+					Labels:    builder.SelectorLabels(bssCluster),
+					OwnerReferences: []metav1.OwnerReference{{
+						APIVersion: "apps/v1",
+						Kind:       "StatefulSet",
+						Name:       statefulSet.Name,
+						UID:        statefulSet.UID,
+					}},
+				},
+				Spec: corev1.PersistentVolumeClaimSpec{
+					AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+					Resources: corev1.VolumeResourceRequirements{
+						Requests: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("1Gi")},
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, claim)).To(Succeed())
+			DeferCleanup(func() {
+				Expect(client.IgnoreNotFound(k8sClient.Delete(ctx, claim))).To(Succeed())
+			})
+
+			By("switching the BssCluster to a Deployment without storage")
+			Expect(k8sClient.Get(ctx, key, bssCluster)).To(Succeed())
+			bssCluster.Spec.Workload = bssv1alpha1.WorkloadTypeDeployment
+			bssCluster.Spec.Storage = nil
+			Expect(k8sClient.Update(ctx, bssCluster)).To(Succeed())
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(k8sClient.Get(ctx, key, statefulSet)).To(Succeed())
+			Expect(statefulSet.UID).To(Equal(uid))
+			Expect(statefulSet.Spec.VolumeClaimTemplates).To(HaveLen(1))
+			Expect(statefulSet.Spec.Template.Spec.Containers[0].VolumeMounts).NotTo(BeEmpty())
+			deployment := &appsv1.Deployment{}
+			Expect(k8sClient.Get(ctx, key, deployment)).To(Succeed())
+
+			By("retaining the claims once the Deployment is ready")
+			deployment.Status = appsv1.DeploymentStatus{
+				ObservedGeneration: deployment.Generation,
+				Replicas:           1,
+				UpdatedReplicas:    1,
+				ReadyReplicas:      1,
+				AvailableReplicas:  1,
+				Conditions: []appsv1.DeploymentCondition{{
+					Type:   appsv1.DeploymentAvailable,
+					Status: corev1.ConditionTrue,
+				}},
+			}
+			Expect(k8sClient.Status().Update(ctx, deployment)).To(Succeed())
+			result, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.RequeueAfter).To(Equal(migrationPollInterval))
+			Expect(k8sClient.Get(ctx, key, statefulSet)).To(Succeed())
+			Expect(statefulSet.Spec.PersistentVolumeClaimRetentionPolicy.WhenDeleted).To(
+				Equal(appsv1.RetainPersistentVolumeClaimRetentionPolicyType))
+
+			By("waiting for the StatefulSet controller to release the claims")
+			statefulSet.Status.ObservedGeneration = statefulSet.Generation
+			Expect(k8sClient.Status().Update(ctx, statefulSet)).To(Succeed())
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(ctx, key, statefulSet)).To(Succeed())
+
+			Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(claim), claim)).To(Succeed())
+			claim.OwnerReferences = nil
+			Expect(k8sClient.Update(ctx, claim)).To(Succeed())
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+			Expect(err).NotTo(HaveOccurred())
+
+			By("checking that the StatefulSet was retired and its claim kept")
+			Eventually(func() bool {
+				return errors.IsNotFound(k8sClient.Get(ctx, key, &appsv1.StatefulSet{}))
+			}, timeout, interval).Should(BeTrue())
+			Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(claim), claim)).To(Succeed())
+			Expect(k8sClient.Get(ctx, key, bssCluster)).To(Succeed())
+			Expect(bssCluster.Status.Workload).To(Equal(bssv1alpha1.WorkloadTypeDeployment))
+		})
+	})
+
+	Context("When upgrading bss-api", func() {
+		const resourceName = "test-upgrade"
+
+		ctx := context.Background()
+		key := types.NamespacedName{Name: resourceName, Namespace: "default"}
+		preUpgradeKey := types.NamespacedName{Name: resourceName + "-pre-upgrade", Namespace: "default"}
+		postUpgradeKey := types.NamespacedName{Name: resourceName + "-post-upgrade", Namespace: "default"}
+
+		// envtest runs no Deployment or Job controller, so rollouts and Jobs are finished by hand
+		markRolledOut := func() *appsv1.Deployment {
+			deployment := &appsv1.Deployment{}
+			Expect(k8sClient.Get(ctx, key, deployment)).To(Succeed())
+			deployment.Status = appsv1.DeploymentStatus{
+				ObservedGeneration: deployment.Generation,
+				Replicas:           1,
+				UpdatedReplicas:    1,
+				ReadyReplicas:      1,
+				AvailableReplicas:  1,
+				Conditions: []appsv1.DeploymentCondition{{
+					Type:   appsv1.DeploymentAvailable,
+					Status: corev1.ConditionTrue,
+					Reason: ReasonMinimumReplicasAvailable,
+				}},
+			}
+			Expect(k8sClient.Status().Update(ctx, deployment)).To(Succeed())
+			return deployment
+		}
+		finishJob := func(jobKey types.NamespacedName, succeeded bool) {
+			job := &batchv1.Job{}
+			Expect(k8sClient.Get(ctx, jobKey, job)).To(Succeed())
+			now := metav1.Now()
+			job.Status.StartTime = &now
+			if succeeded {
+				job.Status.Succeeded = 1
+				job.Status.CompletionTime = &now
+				job.Status.Conditions = []batchv1.JobCondition{
+					{Type: batchv1.JobComplete, Status: corev1.ConditionTrue, LastTransitionTime: now},
+				}
+			} else {
+				job.Status.Failed = 1
+				job.Status.Conditions = []batchv1.JobCondition{
+					{Type: batchv1.JobFailureTarget, Status: corev1.ConditionTrue, Reason: "BackoffLimitExceeded",
+						LastTransitionTime: now},
+					{Type: batchv1.JobFailed, Status: corev1.ConditionTrue, Reason: "BackoffLimitExceeded",
+						Message: "Job has reached the specified backoff limit", LastTransitionTime: now},
+				}
+			}
+			Expect(k8sClient.Status().Update(ctx, job)).To(Succeed())
+		}
+
+		BeforeEach(func() {
+			resource := &bssv1alpha1.BssCluster{
+				ObjectMeta: metav1.ObjectMeta{Name: resourceName, Namespace: "default"},
+				Spec: bssv1alpha1.BssClusterSpec{
+					Name:    "test-upgrade",
+					Version: "1.0.0",
+				},
+			}
+			Expect(k8sClient.Create(ctx, resource)).To(Succeed())
+		})
+
+		AfterEach(func() {
+			for _, jobKey := range []types.NamespacedName{preUpgradeKey, postUpgradeKey} {
+				job := &batchv1.Job{ObjectMeta: metav1.ObjectMeta{Name: jobKey.Name, Namespace: jobKey.Namespace}}
+				Expect(client.IgnoreNotFound(k8sClient.Delete(ctx, job,
+					client.PropagationPolicy(metav1.DeletePropagationBackground)))).To(Succeed())
+			}
+			deleteAndFinalize(ctx, NewBssClusterReconciler(k8sClient, k8sClient.Scheme()), key)
+		})
+
+		It("should run the upgrade Jobs and roll back when the post-upgrade Job fails", func() {
+			recorder := record.NewFakeRecorder(20)
+			controllerReconciler := NewBssClusterReconciler(k8sClient, k8sClient.Scheme(), WithEventRecorder(recorder))
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+			Expect(err).NotTo(HaveOccurred())
+			markRolledOut()
+
+			bssCluster := &bssv1alpha1.BssCluster{}
+			Expect(k8sClient.Get(ctx, key, bssCluster)).To(Succeed())
+			Expect(bssCluster.Status.CurrentVersion).To(Equal("1.0.0"))
+
+			By("changing the version with pre- and post-upgrade Jobs")
+			bssCluster.Spec.Version = "1.1.0"
+			bssCluster.Spec.Upgrade = &bssv1alpha1.UpgradeSpec{
+				PreUpgrade:  &bssv1alpha1.UpgradeJobSpec{Args: []string{"migrate"}},
+				PostUpgrade: &bssv1alpha1.UpgradeJobSpec{Args: []string{"smoke-test"}},
+			}
+			Expect(k8sClient.Update(ctx, bssCluster)).To(Succeed())
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(k8sClient.Get(ctx, key, bssCluster)).To(Succeed())
+			Expect(bssCluster.Status.TargetVersion).To(Equal("1.1.0"))
+			Expect(bssCluster.Status.Upgrade.Phase).To(Equal(bssv1alpha1.UpgradePhasePreUpgrade))
+			Expect(meta.IsStatusConditionTrue(bssCluster.Status.Conditions, TypeUpgrading)).To(BeTrue())
+			job := &batchv1.Job{}
+			Expect(k8sClient.Get(ctx, preUpgradeKey, job)).To(Succeed())
+			container := job.Spec.Template.Spec.Containers[0]
+			Expect(container.Image).To(Equal("bss-api:1.1.0"))
+			Expect(container.Args).To(Equal([]string{"migrate"}))
+			Expect(container.Env).To(ContainElements(
+				corev1.EnvVar{Name: "BSS_UPGRADE_FROM_VERSION", Value: "1.0.0"},
+				corev1.EnvVar{Name: "BSS_UPGRADE_TO_VERSION", Value: "1.1.0"}))
+			Expect(job.Spec.Template.Labels).NotTo(HaveKeyWithValue(builder.LabelApp, "bss-cluster"))
+			deployment := &appsv1.Deployment{}
+			Expect(k8sClient.Get(ctx, key, deployment)).To(Succeed())
+			Expect(deployment.Spec.Template.Spec.Containers[0].Image).To(Equal("bss-api:1.0.0"))
+
+			By("rolling out the new version once the pre-upgrade Job succeeds")
+			finishJob(preUpgradeKey, true)
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(ctx, key, bssCluster)).To(Succeed())
+			Expect(bssCluster.Status.Upgrade.Phase).To(Equal(bssv1alpha1.UpgradePhaseRollingOut))
+			Expect(k8sClient.Get(ctx, key, deployment)).To(Succeed())
+			Expect(deployment.Spec.Template.Spec.Containers[0].Image).To(Equal("bss-api:1.1.0"))
+			Expect(deployment.Spec.Template.Annotations).To(HaveKeyWithValue(bssv1alpha1.AnnotationVersion, "1.1.0"))
+
+			By("running the post-upgrade Job once the new version is ready")
+			markRolledOut()
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(ctx, key, bssCluster)).To(Succeed())
+			Expect(bssCluster.Status.Upgrade.Phase).To(Equal(bssv1alpha1.UpgradePhasePostUpgrade))
+			Expect(k8sClient.Get(ctx, postUpgradeKey, job)).To(Succeed())
+
+			By("rolling back when the post-upgrade Job fails")
+			finishJob(postUpgradeKey, false)
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(ctx, key, bssCluster)).To(Succeed())
+			Expect(bssCluster.Status.Upgrade.Phase).To(Equal(bssv1alpha1.UpgradePhaseRollingBack))
+			Expect(k8sClient.Get(ctx, key, deployment)).To(Succeed())
+			Expect(deployment.Spec.Template.Spec.Containers[0].Image).To(Equal("bss-api:1.0.0"))
+
+			markRolledOut()
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(ctx, key, bssCluster)).To(Succeed())
+			Expect(bssCluster.Status.Upgrade.Phase).To(Equal(bssv1alpha1.UpgradePhaseRolledBack))
+			Expect(bssCluster.Status.Upgrade.Message).To(ContainSubstring("backoff limit"))
+			Expect(bssCluster.Status.CurrentVersion).To(Equal("1.0.0"))
+			Expect(bssCluster.Status.TargetVersion).To(BeEmpty())
+			upgrading := meta.FindStatusCondition(bssCluster.Status.Conditions, TypeUpgrading)
+			Expect(upgrading).NotTo(BeNil())
+			Expect(upgrading.Status).To(Equal(metav1.ConditionFalse))
+			Expect(upgrading.Reason).To(Equal(ReasonUpgradeRolledBack))
+
+			By("not retrying the rolled back version")
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(ctx, key, bssCluster)).To(Succeed())
+			Expect(bssCluster.Status.Upgrade.Phase).To(Equal(bssv1alpha1.UpgradePhaseRolledBack))
+
+			var events []string
+			for len(recorder.Events) > 0 {
+				events = append(events, <-recorder.Events)
+			}
+			Expect(events).To(ContainElements(
+				ContainSubstring(ReasonPreUpgradeStarted),
+				ContainSubstring(ReasonUpgradeRollingOut),
+				ContainSubstring(ReasonPostUpgradeStarted),
+				ContainSubstring(ReasonUpgradeRollingBack),
+				ContainSubstring(ReasonUpgradeRolledBack)))
+		})
+
+		It("should block skipping a major version unless unsafe upgrades are allowed", func() {
+			controllerReconciler := NewBssClusterReconciler(k8sClient, k8sClient.Scheme())
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+			Expect(err).NotTo(HaveOccurred())
+			markRolledOut()
+
+			// The webhook is not installed in envtest, so the controller checks the path itself
+			bssCluster := &bssv1alpha1.BssCluster{}
+			Expect(k8sClient.Get(ctx, key, bssCluster)).To(Succeed())
+			bssCluster.Spec.Version = "3.0.0"
+			Expect(k8sClient.Update(ctx, bssCluster)).To(Succeed())
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(k8sClient.Get(ctx, key, bssCluster)).To(Succeed())
+			Expect(bssCluster.Status.TargetVersion).To(BeEmpty())
+			upgrading := meta.FindStatusCondition(bssCluster.Status.Conditions, TypeUpgrading)
+			Expect(upgrading).NotTo(BeNil())
+			Expect(upgrading.Reason).To(Equal(ReasonUpgradeBlocked))
+			deployment := &appsv1.Deployment{}
+			Expect(k8sClient.Get(ctx, key, deployment)).To(Succeed())
+			Expect(deployment.Spec.Template.Spec.Containers[0].Image).To(Equal("bss-api:1.0.0"))
+
+			By("allowing the unsafe upgrade")
+			bssCluster.Annotations = map[string]string{bssv1alpha1.AnnotationAllowUnsafeUpgrade: "true"}
+			Expect(k8sClient.Update(ctx, bssCluster)).To(Succeed())
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(ctx, key, deployment)).To(Succeed())
+			Expect(deployment.Spec.Template.Spec.Containers[0].Image).To(Equal("bss-api:3.0.0"))
+
+			markRolledOut()
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(ctx, key, bssCluster)).To(Succeed())
+			Expect(bssCluster.Status.Upgrade.Phase).To(Equal(bssv1alpha1.UpgradePhaseSucceeded))
+			Expect(bssCluster.Status.CurrentVersion).To(Equal("3.0.0"))
+			Expect(meta.IsStatusConditionFalse(bssCluster.Status.Conditions, TypeUpgrading)).To(BeTrue())
+		})
+
+		It("should wait for the maintenance window before starting an upgrade", func() {
+			controllerReconciler := NewBssClusterReconciler(k8sClient, k8sClient.Scheme())
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+			Expect(err).NotTo(HaveOccurred())
+			markRolledOut()
+
+			By("changing the version outside of the maintenance window")
+			now := time.Now().UTC()
+			bssCluster := &bssv1alpha1.BssCluster{}
+			Expect(k8sClient.Get(ctx, key, bssCluster)).To(Succeed())
+			bssCluster.Spec.Version = "1.1.0"
+			bssCluster.Spec.MaintenanceWindow = &bssv1alpha1.MaintenanceWindowSpec{
+				Start:    now.Add(12 * time.Hour).Format("15:04"),
+				Duration: metav1.Duration{Duration: time.Hour},
+			}
+			Expect(k8sClient.Update(ctx, bssCluster)).To(Succeed())
+			result, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.RequeueAfter).To(BeNumerically("~", 12*time.Hour, time.Minute))
+
+			Expect(k8sClient.Get(ctx, key, bssCluster)).To(Succeed())
+			Expect(bssCluster.Status.TargetVersion).To(BeEmpty())
+			upgrading := meta.FindStatusCondition(bssCluster.Status.Conditions, TypeUpgrading)
+			Expect(upgrading).NotTo(BeNil())
+			Expect(upgrading.Reason).To(Equal(ReasonUpgradeScheduled))
+			deployment := &appsv1.Deployment{}
+			Expect(k8sClient.Get(ctx, key, deployment)).To(Succeed())
+			Expect(deployment.Spec.Template.Spec.Containers[0].Image).To(Equal("bss-api:1.0.0"))
+
+			By("starting the upgrade once the window is open")
+			bssCluster.Spec.MaintenanceWindow.Start = now.Add(-30 * time.Minute).Format("15:04")
+			Expect(k8sClient.Update(ctx, bssCluster)).To(Succeed())
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(ctx, key, bssCluster)).To(Succeed())
+			Expect(bssCluster.Status.Upgrade.Phase).To(Equal(bssv1alpha1.UpgradePhaseRollingOut))
+			Expect(k8sClient.Get(ctx, key, deployment)).To(Succeed())
+			Expect(deployment.Spec.Template.Spec.Containers[0].Image).To(Equal("bss-api:1.1.0"))
+		})
+
+		It("should hold pod template changes until the maintenance window opens", func() {
+			controllerReconciler := NewBssClusterReconciler(k8sClient, k8sClient.Scheme())
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+			Expect(err).NotTo(HaveOccurred())
+			markRolledOut()
+
+			By("changing the image tag outside of the maintenance window")
+			now := time.Now().UTC()
+			bssCluster := &bssv1alpha1.BssCluster{}
+			Expect(k8sClient.Get(ctx, key, bssCluster)).To(Succeed())
+			bssCluster.Spec.Image = &bssv1alpha1.ImageSpec{Tag: "1.0.0-patched"}
+			bssCluster.Spec.MaintenanceWindow = &bssv1alpha1.MaintenanceWindowSpec{
+				Start:    now.Add(12 * time.Hour).Format("15:04"),
+				Duration: metav1.Duration{Duration: time.Hour},
+			}
+			Expect(k8sClient.Update(ctx, bssCluster)).To(Succeed())
+			result, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.RequeueAfter).To(BeNumerically("~", 12*time.Hour, time.Minute))
+
+			deployment := &appsv1.Deployment{}
+			Expect(k8sClient.Get(ctx, key, deployment)).To(Succeed())
+			Expect(deployment.Spec.Template.Spec.Containers[0].Image).To(Equal("bss-api:1.0.0"))
+			Expect(deployment.Annotations).To(HaveKey(bssv1alpha1.AnnotationPendingPodTemplateHash))
+			Expect(k8sClient.Get(ctx, key, bssCluster)).To(Succeed())
+			progressing := meta.FindStatusCondition(bssCluster.Status.Conditions, TypeProgressing)
+			Expect(progressing).NotTo(BeNil())
+			Expect(progressing.Status).To(Equal(metav1.ConditionFalse))
+			Expect(progressing.Reason).To(Equal(ReasonMaintenanceWindowPending))
+
+			By("rolling the change out once the window is open")
+			bssCluster.Spec.MaintenanceWindow.Start = now.Add(-30 * time.Minute).Format("15:04")
+			Expect(k8sClient.Update(ctx, bssCluster)).To(Succeed())
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(ctx, key, deployment)).To(Succeed())
+			Expect(deployment.Spec.Template.Spec.Containers[0].Image).To(Equal("bss-api:1.0.0-patched"))
+			Expect(deployment.Annotations).NotTo(HaveKey(bssv1alpha1.AnnotationPendingPodTemplateHash))
+		})
+	})
+
+	Context("When pausing the cluster", func() {
+		const resourceName = "test-pause"
+
+		ctx := context.Background()
+		key := types.NamespacedName{Name: resourceName, Namespace: "default"}
+
+		BeforeEach(func() {
+			resource := &bssv1alpha1.BssCluster{
+				ObjectMeta: metav1.ObjectMeta{Name: resourceName, Namespace: "default"},
+				Spec: bssv1alpha1.BssClusterSpec{
+					Name:    "test-pause",
+					Version: "1.0.0",
+				},
+			}
+			Expect(k8sClient.Create(ctx, resource)).To(Succeed())
+		})
+
+		AfterEach(func() {
+			deleteAndFinalize(ctx, NewBssClusterReconciler(k8sClient, k8sClient.Scheme()), key)
+		})
+
+		It("should leave the children alone until the cluster is resumed", func() {
+			recorder := record.NewFakeRecorder(10)
+			controllerReconciler := NewBssClusterReconciler(k8sClient, k8sClient.Scheme(), WithEventRecorder(recorder))
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+			Expect(err).NotTo(HaveOccurred())
+
+			By("pausing the cluster and scaling it")
+			bssCluster := &bssv1alpha1.BssCluster{}
+			Expect(k8sClient.Get(ctx, key, bssCluster)).To(Succeed())
+			bssCluster.Annotations = map[string]string{bssv1alpha1.AnnotationPaused: "true"}
+			bssCluster.Spec.Replicas = ptr.To[int32](3)
+			Expect(k8sClient.Update(ctx, bssCluster)).To(Succeed())
+			result, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.RequeueAfter).To(BeZero())
+
+			Expect(k8sClient.Get(ctx, key, bssCluster)).To(Succeed())
+			paused := meta.FindStatusCondition(bssCluster.Status.Conditions, TypePaused)
+			Expect(paused).NotTo(BeNil())
+			Expect(paused.Status).To(Equal(metav1.ConditionTrue))
+			Expect(paused.Reason).To(Equal(ReasonAnnotationPaused))
+			Expect(bssCluster.Status.ObservedGeneration).To(BeNumerically("<", bssCluster.Generation))
+			deployment := &appsv1.Deployment{}
+			Expect(k8sClient.Get(ctx, key, deployment)).To(Succeed())
+			Expect(*deployment.Spec.Replicas).To(Equal(int32(1)))
+
+			By("resuming the cluster")
+			bssCluster.Annotations = nil
+			Expect(k8sClient.Update(ctx, bssCluster)).To(Succeed())
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(k8sClient.Get(ctx, key, bssCluster)).To(Succeed())
+			Expect(meta.IsStatusConditionFalse(bssCluster.Status.Conditions, TypePaused)).To(BeTrue())
+			Expect(k8sClient.Get(ctx, key, deployment)).To(Succeed())
+			Expect(*deployment.Spec.Replicas).To(Equal(int32(3)))
+			Expect(recorder.Events).To(Receive(ContainSubstring(EventReasonPaused)))
+			Expect(recorder.Events).To(Receive(ContainSubstring(EventReasonResumed)))
+		})
+
+		It("should not change a cluster created paused", func() {
+			bssCluster := &bssv1alpha1.BssCluster{}
+			Expect(k8sClient.Get(ctx, key, bssCluster)).To(Succeed())
+			bssCluster.Annotations = map[string]string{bssv1alpha1.AnnotationPaused: "true"}
+			Expect(k8sClient.Update(ctx, bssCluster)).To(Succeed())
+
+			controllerReconciler := NewBssClusterReconciler(k8sClient, k8sClient.Scheme())
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(k8sClient.Get(ctx, key, bssCluster)).To(Succeed())
+			Expect(bssCluster.Finalizers).NotTo(ContainElement(FinalizerName))
+			Expect(meta.IsStatusConditionTrue(bssCluster.Status.Conditions, TypePaused)).To(BeTrue())
+			Expect(errors.IsNotFound(k8sClient.Get(ctx, key, &appsv1.Deployment{}))).To(BeTrue())
+		})
+	})
+
+	Context("When filtering events from owned resources", func() {
+		It("should ignore Deployment status heartbeats", func() {
+			oldDeployment := &appsv1.Deployment{
+				Status: appsv1.DeploymentStatus{
+					ReadyReplicas: 1,
+					Conditions: []appsv1.DeploymentCondition{
+						{Type: appsv1.DeploymentProgressing, Status: corev1.ConditionTrue, Reason: "NewReplicaSetAvailable"},
+					},
+				},
+			}
+			newDeployment := oldDeployment.DeepCopy()
+			newDeployment.Status.Conditions[0].LastUpdateTime = metav1.Now()
+
+			Expect(deploymentPredicate().Update(event.UpdateEvent{
+				ObjectOld: oldDeployment,
+				ObjectNew: newDeployment,
+			})).To(BeFalse())
+		})
+
+		It("should pass Deployment readiness changes and spec drift", func() {
+			oldDeployment := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Generation: 1}}
+
+			readyDeployment := oldDeployment.DeepCopy()
+			readyDeployment.Status.ReadyReplicas = 1
+			Expect(deploymentPredicate().Update(event.UpdateEvent{
+				ObjectOld: oldDeployment,
+				ObjectNew: readyDeployment,
+			})).To(BeTrue())
+
+			editedDeployment := oldDeployment.DeepCopy()
+			editedDeployment.Generation = 2
+			Expect(deploymentPredicate().Update(event.UpdateEvent{
+				ObjectOld: oldDeployment,
+				ObjectNew: editedDeployment,
+			})).To(BeTrue())
+		})
+
+		It("should pass Service spec drift but ignore load balancer status", func() {
+			oldService := &corev1.Service{
+				Spec: corev1.ServiceSpec{Ports: []corev1.ServicePort{{Name: "http", Port: 80}}},
+			}
+
+			statusService := oldService.DeepCopy()
+			statusService.Status.LoadBalancer.Ingress = []corev1.LoadBalancerIngress{{IP: "10.0.0.1"}}
+			Expect(servicePredicate().Update(event.UpdateEvent{
+				ObjectOld: oldService,
+				ObjectNew: statusService,
+			})).To(BeFalse())
+
+			editedService := oldService.DeepCopy()
+			editedService.Spec.Ports[0].Port = 8080
+			Expect(servicePredicate().Update(event.UpdateEvent{
+				ObjectOld: oldService,
+				ObjectNew: editedService,
+			})).To(BeTrue())
+		})
+
+		It("should pass BssCluster availability changes to referencing queries", func() {
+			oldCluster := &bssv1alpha1.BssCluster{ObjectMeta: metav1.ObjectMeta{Generation: 1}}
+
+			availableCluster := oldCluster.DeepCopy()
+			availableCluster.Status.Conditions = []metav1.Condition{
+				{Type: TypeAvailable, Status: metav1.ConditionTrue, Reason: ReasonMinimumReplicasAvailable},
+			}
+			Expect(clusterEndpointPredicate().Update(event.UpdateEvent{
+				ObjectOld: oldCluster,
+				ObjectNew: availableCluster,
+			})).To(BeTrue())
+
+			rolledCluster := availableCluster.DeepCopy()
+			rolledCluster.Status.ReadyReplicas = 2
+			Expect(clusterEndpointPredicate().Update(event.UpdateEvent{
+				ObjectOld: availableCluster,
+				ObjectNew: rolledCluster,
+			})).To(BeFalse())
+		})
+	})
+
+	Context("When deleting a resource", func() {
+		const resourceName = "test-teardown"
+
+		ctx := context.Background()
+
+		typeNamespacedName := types.NamespacedName{
+			Name:      resourceName,
+			Namespace: "default",
+		}
+
+		BeforeEach(func() {
+			resource := &bssv1alpha1.BssCluster{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      resourceName,
+					Namespace: "default",
+				},
+				Spec: bssv1alpha1.BssClusterSpec{
+					Name:    "teardown-cluster",
+					Version: "1.0.0",
+				},
+			}
+			Expect(k8sClient.Create(ctx, resource)).To(Succeed())
+		})
+
+		It("should run pre-delete hooks and remove every child before releasing the finalizer", func() {
+			hook := &recordingHook{}
+			controllerReconciler := NewBssClusterReconciler(k8sClient, k8sClient.Scheme(), WithPreDeleteHooks(hook))
+
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{
+				NamespacedName: typeNamespacedName,
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			By("Checking that the finalizer was added")
+			resource := &bssv1alpha1.BssCluster{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+			Expect(resource.Finalizers).To(ContainElement(FinalizerName))
+
+			By("Deleting the BssCluster")
+			deleteAndFinalize(ctx, controllerReconciler, typeNamespacedName)
+			Expect(hook.calls).To(ContainElement(resourceName))
+
+			By("Checking that the children were deleted")
+			Expect(errors.IsNotFound(k8sClient.Get(ctx, typeNamespacedName, &corev1.Service{}))).To(BeTrue())
+			Expect(errors.IsNotFound(k8sClient.Get(ctx, typeNamespacedName, &appsv1.Deployment{}))).To(BeTrue())
+		})
+
+		It("should keep the finalizer while a pre-delete hook fails", func() {
+			hook := &recordingHook{err: fmt.Errorf("bss-api unreachable")}
+			controllerReconciler := NewBssClusterReconciler(k8sClient, k8sClient.Scheme(), WithPreDeleteHooks(hook))
+
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{
+				NamespacedName: typeNamespacedName,
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			resource := &bssv1alpha1.BssCluster{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+			Expect(k8sClient.Delete(ctx, resource)).To(Succeed())
+
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{
+				NamespacedName: typeNamespacedName,
+			})
+			Expect(err).To(HaveOccurred())
+
+			By("Checking that the failure is reported and the children are kept")
+			Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+			terminating := meta.FindStatusCondition(resource.Status.Conditions, TypeTerminating)
+			Expect(terminating).NotTo(BeNil())
+			Expect(terminating.Reason).To(Equal(ReasonPreDeleteHookFailed))
+			Expect(k8sClient.Get(ctx, typeNamespacedName, &corev1.Service{})).To(Succeed())
+
+			By("Releasing the BssCluster once the hook recovers")
+			hook.err = nil
+			Eventually(func() bool {
+				_, _ = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+				return errors.IsNotFound(k8sClient.Get(ctx, typeNamespacedName, &bssv1alpha1.BssCluster{}))
+			}, timeout, interval).Should(BeTrue())
 		})
 	})
 })
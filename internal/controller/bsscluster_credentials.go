@@ -0,0 +1,159 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	bssv1alpha1 "github.com/brmorris/bss-operator/api/v1alpha1"
+	"github.com/brmorris/bss-operator/internal/builder"
+)
+
+// EventReasonCredentialsRotated is the reason of the Event recorded when the
+// credentials of a BssCluster are rotated
+const EventReasonCredentialsRotated = "CredentialsRotated"
+
+// reconcileCredentials records the Secret the bss-api pods read their
+// credentials from and decides whether the credentials are due for rotation.
+// A rotation only moves status.credentials.lastRotationTime; the generated
+// Secret and the pod template are stamped with it when the children are
+// applied. The last rotation of generated credentials is read back from their
+// Secret, and status mirrors it. It returns how long until the next scheduled
+// rotation, or until the maintenance window opens for an overdue one, or zero.
+func (r *BssClusterReconciler) reconcileCredentials(ctx context.Context, bssCluster *bssv1alpha1.BssCluster, log logr.Logger) (time.Duration, error) {
+	credentials := bssCluster.Spec.Credentials
+	if credentials == nil {
+		bssCluster.Status.Credentials = nil
+		return 0, nil
+	}
+
+	secretName := builder.CredentialsSecretName(bssCluster)
+	generated := builder.GeneratesCredentials(bssCluster)
+	if !generated {
+		if err := r.checkCredentialsSecret(ctx, bssCluster.Namespace, secretName); err != nil {
+			return 0, err
+		}
+	}
+
+	// Switching between generated and referenced credentials starts afresh
+	status := bssCluster.Status.Credentials
+	if status == nil || status.SecretName != secretName {
+		status = &bssv1alpha1.CredentialsStatus{SecretName: secretName}
+		bssCluster.Status.Credentials = status
+	}
+	if generated {
+		if err := r.observeCredentialsRotation(ctx, bssCluster.Namespace, secretName, status); err != nil {
+			return 0, err
+		}
+	}
+
+	now := time.Now()
+	request := bssCluster.Annotations[bssv1alpha1.AnnotationRotateCredentials]
+	var reason string
+	switch {
+	case status.LastRotationTime == nil:
+		reason = "initial"
+	case request != status.ObservedRotationRequest:
+		reason = "requested"
+	case generated && credentials.RotateEvery != nil &&
+		!now.Before(status.LastRotationTime.Add(credentials.RotateEvery.Duration)):
+		// Scheduled rotations roll the pods, so they wait for the maintenance
+		// window; requested ones do not
+		if wait := builder.MaintenanceWindowWait(bssCluster, now); wait > 0 {
+			log.V(1).Info("Scheduled credentials rotation waits for the maintenance window", "opensIn", wait)
+			return wait, nil
+		}
+		reason = "scheduled"
+	}
+
+	if reason != "" {
+		if reason != "initial" {
+			log.Info("Rotating credentials", "secret", secretName, "reason", reason)
+			if r.recorder != nil {
+				r.recorder.Eventf(bssCluster, corev1.EventTypeNormal, EventReasonCredentialsRotated,
+					"Rotating credentials in Secret %s (%s)", secretName, reason)
+			}
+		}
+		status.LastRotationTime = &metav1.Time{Time: now.Truncate(time.Second)}
+		status.ObservedRotationRequest = request
+	}
+
+	if !generated || credentials.RotateEvery == nil {
+		return 0, nil
+	}
+	return max(time.Until(status.LastRotationTime.Add(credentials.RotateEvery.Duration)), time.Second), nil
+}
+
+// observeCredentialsRotation copies the last rotation stamped on the
+// generated credentials Secret into status, so a rotation whose status update
+// was lost is not repeated. Status is kept while it is as recent as the
+// Secret, which the cache may not have caught up with yet. A Secret that does
+// not exist yet is ignored.
+func (r *BssClusterReconciler) observeCredentialsRotation(ctx context.Context, namespace, name string,
+	status *bssv1alpha1.CredentialsStatus) error {
+	secret := &corev1.Secret{}
+	if err := r.Get(ctx, types.NamespacedName{Name: name, Namespace: namespace}, secret); err != nil {
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	rotatedAt, err := time.Parse(time.RFC3339, secret.Annotations[bssv1alpha1.AnnotationCredentialsRotatedAt])
+	if err != nil {
+		return nil
+	}
+	if status.LastRotationTime != nil && !rotatedAt.After(status.LastRotationTime.Time) {
+		return nil
+	}
+	status.LastRotationTime = &metav1.Time{Time: rotatedAt}
+	// Secrets stamped before the request was recorded keep the one in status
+	if request, ok := secret.Annotations[bssv1alpha1.AnnotationCredentialsRotationRequest]; ok {
+		status.ObservedRotationRequest = request
+	}
+	return nil
+}
+
+// checkCredentialsSecret verifies that a referenced Secret provides every
+// credentials key, so pods do not fail to start on a missing key. Only key
+// names are reported.
+func (r *BssClusterReconciler) checkCredentialsSecret(ctx context.Context, namespace, name string) error {
+	secret := &corev1.Secret{}
+	if err := r.Get(ctx, types.NamespacedName{Name: name, Namespace: namespace}, secret); err != nil {
+		if errors.IsNotFound(err) {
+			return fmt.Errorf("credentials Secret %s not found", name)
+		}
+		return err
+	}
+	var missing []string
+	for _, key := range builder.CredentialsKeys {
+		if len(secret.Data[key]) == 0 {
+			missing = append(missing, key)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("credentials Secret %s is missing keys %v", name, missing)
+	}
+	return nil
+}
@@ -0,0 +1,144 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	bssv1alpha1 "github.com/brmorris/bss-operator/api/v1alpha1"
+)
+
+const (
+	// FinalizerName is the finalizer that guards the ordered teardown of a BssCluster
+	FinalizerName = "bss.localhost/finalizer"
+
+	// TypeTerminating reports the teardown progress of a deleted BssCluster
+	TypeTerminating = "Terminating"
+
+	// Condition reasons for BssCluster teardown
+	ReasonPreDeleteHooksCompleted = "PreDeleteHooksCompleted"
+	ReasonPreDeleteHookFailed     = "PreDeleteHookFailed"
+	ReasonDeletingChildren        = "DeletingChildren"
+
+	// teardownPollInterval is how often teardown re-checks that a child is gone
+	teardownPollInterval = 2 * time.Second
+)
+
+// PreDeleteHook performs external cleanup before the children of a deleted
+// BssCluster are torn down, such as deregistering the cluster from the bss-api.
+// Hooks are retried until they succeed, so they must be idempotent.
+type PreDeleteHook interface {
+	// Name identifies the hook in logs and status messages
+	Name() string
+
+	// PreDelete runs the cleanup for the given BssCluster
+	PreDelete(ctx context.Context, bssCluster *bssv1alpha1.BssCluster) error
+}
+
+// ensureFinalizer adds the teardown finalizer to a BssCluster that lacks it
+func (r *BssClusterReconciler) ensureFinalizer(ctx context.Context, bssCluster *bssv1alpha1.BssCluster, log logr.Logger) error {
+	if !controllerutil.AddFinalizer(bssCluster, FinalizerName) {
+		return nil
+	}
+	log.Info("Adding finalizer", "finalizer", FinalizerName)
+	return r.Update(ctx, bssCluster)
+}
+
+// finalize runs the pre-delete hooks and deletes the children of a BssCluster
+// one at a time in registration order, waiting for each to disappear before
+// moving on. The finalizer is removed once every child is gone.
+func (r *BssClusterReconciler) finalize(ctx context.Context, bssCluster *bssv1alpha1.BssCluster, log logr.Logger) (ctrl.Result, error) {
+	if !controllerutil.ContainsFinalizer(bssCluster, FinalizerName) {
+		return ctrl.Result{}, nil
+	}
+
+	log.Info("Finalizing BssCluster", "name", bssCluster.Name)
+
+	if err := r.runPreDeleteHooks(ctx, bssCluster, log); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	for _, child := range r.children {
+		exists, err := child.reconciler.Exists(ctx, bssCluster)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		if !exists {
+			continue
+		}
+
+		// The child still exists: delete it and wait for it to disappear
+		// before touching anything further down the teardown order
+		setCondition(bssCluster, TypeTerminating, metav1.ConditionTrue, ReasonDeletingChildren,
+			fmt.Sprintf("Deleting %s %s", child.reconciler.Kind(), bssCluster.Name))
+		if err := r.updateStatus(ctx, bssCluster); err != nil {
+			return ctrl.Result{}, err
+		}
+		if err := child.reconciler.Delete(ctx, bssCluster, log); err != nil {
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{RequeueAfter: teardownPollInterval}, nil
+	}
+
+	log.Info("All children deleted, removing finalizer", "finalizer", FinalizerName)
+	controllerutil.RemoveFinalizer(bssCluster, FinalizerName)
+	if err := r.Update(ctx, bssCluster); err != nil {
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{}, nil
+}
+
+// runPreDeleteHooks runs every registered hook, recording a failure in status
+func (r *BssClusterReconciler) runPreDeleteHooks(ctx context.Context, bssCluster *bssv1alpha1.BssCluster, log logr.Logger) error {
+	if len(r.preDeleteHooks) == 0 {
+		return nil
+	}
+	// Hooks have already succeeded once the children are being deleted
+	if terminating := meta.FindStatusCondition(bssCluster.Status.Conditions, TypeTerminating); terminating != nil &&
+		terminating.Reason == ReasonDeletingChildren {
+		return nil
+	}
+	if bssCluster.Annotations[bssv1alpha1.AnnotationSkipPreDeleteHooks] == "true" {
+		log.Info("Skipping pre-delete hooks", "annotation", bssv1alpha1.AnnotationSkipPreDeleteHooks)
+		return nil
+	}
+
+	for _, hook := range r.preDeleteHooks {
+		log.V(1).Info("Running pre-delete hook", "hook", hook.Name())
+		if err := hook.PreDelete(ctx, bssCluster); err != nil {
+			log.Error(err, "Pre-delete hook failed", "hook", hook.Name())
+			setCondition(bssCluster, TypeTerminating, metav1.ConditionTrue, ReasonPreDeleteHookFailed,
+				fmt.Sprintf("Pre-delete hook %s failed: %v", hook.Name(), err))
+			if statusErr := r.updateStatus(ctx, bssCluster); statusErr != nil {
+				log.Error(statusErr, "Failed to update BssCluster status")
+			}
+			return fmt.Errorf("pre-delete hook %s: %w", hook.Name(), err)
+		}
+	}
+
+	setCondition(bssCluster, TypeTerminating, metav1.ConditionTrue, ReasonPreDeleteHooksCompleted,
+		fmt.Sprintf("Completed %d pre-delete hook(s)", len(r.preDeleteHooks)))
+	return nil
+}
@@ -0,0 +1,294 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"fmt"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+
+	bssv1alpha1 "github.com/brmorris/bss-operator/api/v1alpha1"
+	"github.com/brmorris/bss-operator/internal/builder"
+)
+
+const (
+	// Condition types specific to BssCluster. TypeAvailable, TypeDegraded
+	// and TypePaused are shared with BSSQuery.
+	TypeProgressing    = "Progressing"
+	TypeReconcileError = "ReconcileError"
+
+	// Condition reasons for BssCluster
+	ReasonInvalidSpec                = "InvalidSpec"
+	ReasonReconcileFailed            = "ReconcileFailed"
+	ReasonReconcileSucceeded         = "ReconcileSucceeded"
+	ReasonMinimumReplicasAvailable   = "MinimumReplicasAvailable"
+	ReasonMinimumReplicasUnavailable = "MinimumReplicasUnavailable"
+	ReasonRolloutInProgress          = "RolloutInProgress"
+	ReasonRolloutComplete            = "RolloutComplete"
+	ReasonProgressDeadlineExceeded   = "ProgressDeadlineExceeded"
+	ReasonReplicaFailure             = "ReplicaFailure"
+	ReasonAsExpected                 = "AsExpected"
+	ReasonWorkloadNotFound           = "WorkloadNotFound"
+	ReasonMigratingWorkload          = "MigratingWorkload"
+	ReasonMaintenanceWindowPending   = "MaintenanceWindowPending"
+)
+
+// setCondition sets a condition on the BssCluster stamped with its current generation
+func setCondition(bssCluster *bssv1alpha1.BssCluster, conditionType string, status metav1.ConditionStatus, reason, message string) {
+	meta.SetStatusCondition(&bssCluster.Status.Conditions, metav1.Condition{
+		Type:               conditionType,
+		Status:             status,
+		Reason:             reason,
+		Message:            message,
+		ObservedGeneration: bssCluster.Generation,
+	})
+}
+
+// setReconcileError records a failed reconcile on the BssCluster status
+func setReconcileError(bssCluster *bssv1alpha1.BssCluster, reason string, err error) {
+	setCondition(bssCluster, TypeReconcileError, metav1.ConditionTrue, reason, err.Error())
+}
+
+// clearReconcileError records a successful reconcile on the BssCluster status
+func clearReconcileError(bssCluster *bssv1alpha1.BssCluster) {
+	setCondition(bssCluster, TypeReconcileError, metav1.ConditionFalse, ReasonReconcileSucceeded,
+		"All resources reconciled successfully")
+}
+
+// workloadState is the rollout state of a Deployment or StatefulSet, reduced
+// to the fields used to compute the BssCluster status
+type workloadState struct {
+	kind bssv1alpha1.WorkloadType
+
+	desiredReplicas int32
+	replicas        int32
+	readyReplicas   int32
+	updatedReplicas int32
+
+	// available is true once the workload meets its minimum availability
+	available bool
+
+	// rolloutComplete is true once every replica runs the current pod template
+	rolloutComplete bool
+
+	// stuckMessage is set when the workload controller gave up on a rollout
+	stuckMessage string
+
+	// failureMessage is set when the workload controller failed to create replicas
+	failureMessage string
+
+	// image is the bss-api image of the current pod template
+	image string
+
+	// version is the bss-api version the current pod template is stamped with
+	version string
+
+	// templatePending is true while a pod template change waits for the
+	// maintenance window
+	templatePending bool
+}
+
+// ready reports whether the workload can take over all traffic
+func (s *workloadState) ready() bool {
+	return s.available && s.rolloutComplete && s.readyReplicas >= s.desiredReplicas
+}
+
+// replicaMessage summarises replica counts for condition messages
+func (s *workloadState) replicaMessage() string {
+	return fmt.Sprintf("%d/%d replicas ready, %d updated", s.readyReplicas, s.desiredReplicas, s.updatedReplicas)
+}
+
+// newDeploymentState reduces a Deployment to its workloadState
+func newDeploymentState(deployment *appsv1.Deployment) *workloadState {
+	desired := int32(1)
+	if deployment.Spec.Replicas != nil {
+		desired = *deployment.Spec.Replicas
+	}
+	state := &workloadState{
+		kind:            bssv1alpha1.WorkloadTypeDeployment,
+		desiredReplicas: desired,
+		replicas:        deployment.Status.Replicas,
+		readyReplicas:   deployment.Status.ReadyReplicas,
+		updatedReplicas: deployment.Status.UpdatedReplicas,
+		available:       isDeploymentConditionTrue(deployment, appsv1.DeploymentAvailable),
+		rolloutComplete: deploymentRolloutComplete(deployment, desired),
+		image:           containerImage(&deployment.Spec.Template.Spec),
+		version:         deployment.Spec.Template.Annotations[bssv1alpha1.AnnotationVersion],
+		templatePending: deployment.Annotations[bssv1alpha1.AnnotationPendingPodTemplateHash] != "",
+	}
+	if progressing := getDeploymentCondition(deployment, appsv1.DeploymentProgressing); progressing != nil &&
+		progressing.Reason == ReasonProgressDeadlineExceeded {
+		state.stuckMessage = progressing.Message
+	}
+	if replicaFailure := getDeploymentCondition(deployment, appsv1.DeploymentReplicaFailure); replicaFailure != nil &&
+		replicaFailure.Status == corev1.ConditionTrue {
+		state.failureMessage = replicaFailure.Message
+	}
+	return state
+}
+
+// newStatefulSetState reduces a StatefulSet to its workloadState. StatefulSets
+// have no Available condition and roll one replica at a time, so they count as
+// available while at most one replica is missing.
+func newStatefulSetState(statefulSet *appsv1.StatefulSet) *workloadState {
+	desired := int32(1)
+	if statefulSet.Spec.Replicas != nil {
+		desired = *statefulSet.Spec.Replicas
+	}
+	available := statefulSet.Status.AvailableReplicas
+	return &workloadState{
+		kind:            bssv1alpha1.WorkloadTypeStatefulSet,
+		desiredReplicas: desired,
+		replicas:        statefulSet.Status.Replicas,
+		readyReplicas:   statefulSet.Status.ReadyReplicas,
+		updatedReplicas: statefulSet.Status.UpdatedReplicas,
+		available:       available > 0 && available >= desired-1,
+		rolloutComplete: statefulSetRolloutComplete(statefulSet, desired),
+		image:           containerImage(&statefulSet.Spec.Template.Spec),
+		version:         statefulSet.Spec.Template.Annotations[bssv1alpha1.AnnotationVersion],
+		templatePending: statefulSet.Annotations[bssv1alpha1.AnnotationPendingPodTemplateHash] != "",
+	}
+}
+
+// observeWorkload copies the rollout state of the workload into the BssCluster
+// status. A nil state means the workload does not exist (yet).
+func observeWorkload(bssCluster *bssv1alpha1.BssCluster, kind bssv1alpha1.WorkloadType, state *workloadState) {
+	status := &bssCluster.Status
+	status.Selector = labels.SelectorFromSet(builder.SelectorLabels(bssCluster)).String()
+
+	if state == nil {
+		status.Replicas = 0
+		status.ReadyReplicas = 0
+		status.UpdatedReplicas = 0
+		message := fmt.Sprintf("%s does not exist", kind)
+		setCondition(bssCluster, TypeAvailable, metav1.ConditionUnknown, ReasonWorkloadNotFound, message)
+		setCondition(bssCluster, TypeProgressing, metav1.ConditionUnknown, ReasonWorkloadNotFound, message)
+		setCondition(bssCluster, TypeDegraded, metav1.ConditionUnknown, ReasonWorkloadNotFound, message)
+		return
+	}
+
+	status.Replicas = state.replicas
+	status.ReadyReplicas = state.readyReplicas
+	status.UpdatedReplicas = state.updatedReplicas
+	replicaMessage := state.replicaMessage()
+
+	// Available mirrors the workload's own minimum availability
+	if state.available {
+		setCondition(bssCluster, TypeAvailable, metav1.ConditionTrue, ReasonMinimumReplicasAvailable, replicaMessage)
+	} else {
+		setCondition(bssCluster, TypeAvailable, metav1.ConditionFalse, ReasonMinimumReplicasUnavailable, replicaMessage)
+	}
+
+	// Progressing is true until every replica runs the current pod template,
+	// and reports a change held back until the maintenance window opens
+	switch {
+	case state.stuckMessage != "":
+		setCondition(bssCluster, TypeProgressing, metav1.ConditionFalse, ReasonProgressDeadlineExceeded, state.stuckMessage)
+	case !state.rolloutComplete:
+		setCondition(bssCluster, TypeProgressing, metav1.ConditionTrue, ReasonRolloutInProgress, replicaMessage)
+	case state.templatePending:
+		opens, _ := builder.NextMaintenanceWindow(bssCluster, time.Now())
+		setCondition(bssCluster, TypeProgressing, metav1.ConditionFalse, ReasonMaintenanceWindowPending,
+			fmt.Sprintf("Pod template changes are pending until the maintenance window opens at %s",
+				opens.Format(time.RFC3339)))
+		status.Image = state.image
+	default:
+		setCondition(bssCluster, TypeProgressing, metav1.ConditionFalse, ReasonRolloutComplete, replicaMessage)
+		status.Image = state.image
+	}
+
+	// Degraded surfaces rollouts that the workload controller has given up on
+	switch {
+	case state.stuckMessage != "":
+		setCondition(bssCluster, TypeDegraded, metav1.ConditionTrue, ReasonProgressDeadlineExceeded, state.stuckMessage)
+	case state.failureMessage != "":
+		setCondition(bssCluster, TypeDegraded, metav1.ConditionTrue, ReasonReplicaFailure, state.failureMessage)
+	default:
+		setCondition(bssCluster, TypeDegraded, metav1.ConditionFalse, ReasonAsExpected, replicaMessage)
+	}
+}
+
+// observeMigration overrides the status while traffic moves from the previous
+// workload to the requested one. The cluster stays available as long as either
+// workload is, and is progressing until the new workload is ready.
+func observeMigration(bssCluster *bssv1alpha1.BssCluster, previous, next *workloadState) {
+	if previous != nil && previous.available {
+		setCondition(bssCluster, TypeAvailable, metav1.ConditionTrue, ReasonMinimumReplicasAvailable,
+			fmt.Sprintf("%s serving traffic during migration: %s", previous.kind, previous.replicaMessage()))
+	}
+	nextMessage := "0 replicas ready"
+	if next != nil {
+		nextMessage = next.replicaMessage()
+	}
+	setCondition(bssCluster, TypeProgressing, metav1.ConditionTrue, ReasonMigratingWorkload,
+		fmt.Sprintf("Migrating to %s: %s", builder.Workload(bssCluster), nextMessage))
+}
+
+// deploymentRolloutComplete mirrors the checks done by `kubectl rollout status`
+func deploymentRolloutComplete(deployment *appsv1.Deployment, desired int32) bool {
+	if deployment.Generation > deployment.Status.ObservedGeneration {
+		return false
+	}
+	if deployment.Status.UpdatedReplicas < desired {
+		return false
+	}
+	if deployment.Status.Replicas > deployment.Status.UpdatedReplicas {
+		return false
+	}
+	return deployment.Status.AvailableReplicas >= deployment.Status.UpdatedReplicas
+}
+
+// statefulSetRolloutComplete mirrors the checks done by `kubectl rollout status`
+// for a StatefulSet using the RollingUpdate strategy without a partition
+func statefulSetRolloutComplete(statefulSet *appsv1.StatefulSet, desired int32) bool {
+	if statefulSet.Generation > statefulSet.Status.ObservedGeneration {
+		return false
+	}
+	if statefulSet.Status.ReadyReplicas < desired || statefulSet.Status.UpdatedReplicas < desired {
+		return false
+	}
+	return statefulSet.Status.UpdateRevision == statefulSet.Status.CurrentRevision
+}
+
+func getDeploymentCondition(deployment *appsv1.Deployment, conditionType appsv1.DeploymentConditionType) *appsv1.DeploymentCondition {
+	for i := range deployment.Status.Conditions {
+		if deployment.Status.Conditions[i].Type == conditionType {
+			return &deployment.Status.Conditions[i]
+		}
+	}
+	return nil
+}
+
+func isDeploymentConditionTrue(deployment *appsv1.Deployment, conditionType appsv1.DeploymentConditionType) bool {
+	condition := getDeploymentCondition(deployment, conditionType)
+	return condition != nil && condition.Status == corev1.ConditionTrue
+}
+
+// containerImage returns the image of the bss-api container in a pod spec
+func containerImage(podSpec *corev1.PodSpec) string {
+	for _, container := range podSpec.Containers {
+		if container.Name == builder.ContainerName {
+			return container.Image
+		}
+	}
+	return ""
+}
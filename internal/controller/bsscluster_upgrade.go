@@ -0,0 +1,369 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	bssv1alpha1 "github.com/brmorris/bss-operator/api/v1alpha1"
+	"github.com/brmorris/bss-operator/internal/builder"
+	"github.com/brmorris/bss-operator/internal/validation"
+)
+
+const (
+	// TypeUpgrading reports the progress of an upgrade of bss-api
+	TypeUpgrading = "Upgrading"
+
+	// Reasons of the Upgrading condition and of the Events recorded at each
+	// step of an upgrade
+	ReasonUpgradeBlocked     = "UpgradeBlocked"
+	ReasonUpgradeScheduled   = "UpgradeScheduled"
+	ReasonPreUpgradeStarted  = "PreUpgradeStarted"
+	ReasonUpgradeRollingOut  = "UpgradeRollingOut"
+	ReasonPostUpgradeStarted = "PostUpgradeStarted"
+	ReasonUpgradeRollingBack = "UpgradeRollingBack"
+	ReasonUpgradeSucceeded   = "UpgradeSucceeded"
+	ReasonUpgradeFailed      = "UpgradeFailed"
+	ReasonUpgradeRolledBack  = "UpgradeRolledBack"
+)
+
+// upgradePhaseReasons maps each upgrade phase to the reason of its Upgrading
+// condition and Event, and whether it is recorded as a warning
+var upgradePhaseReasons = map[bssv1alpha1.UpgradePhase]struct {
+	reason  string
+	warning bool
+}{
+	bssv1alpha1.UpgradePhasePreUpgrade:  {reason: ReasonPreUpgradeStarted},
+	bssv1alpha1.UpgradePhaseRollingOut:  {reason: ReasonUpgradeRollingOut},
+	bssv1alpha1.UpgradePhasePostUpgrade: {reason: ReasonPostUpgradeStarted},
+	bssv1alpha1.UpgradePhaseRollingBack: {reason: ReasonUpgradeRollingBack, warning: true},
+	bssv1alpha1.UpgradePhaseSucceeded:   {reason: ReasonUpgradeSucceeded},
+	bssv1alpha1.UpgradePhaseFailed:      {reason: ReasonUpgradeFailed, warning: true},
+	bssv1alpha1.UpgradePhaseRolledBack:  {reason: ReasonUpgradeRolledBack, warning: true},
+}
+
+// reconcileUpgrade drives an upgrade of bss-api to spec.version through its
+// phases. It runs before the children are applied, which roll out the
+// version chosen by builder.DeployedVersion, so each step acts on the
+// workload and Jobs as they were left by the previous reconcile. It returns
+// how long until the rollout of the new version times out or the maintenance
+// window opens for a pending upgrade, or zero.
+func (r *BssClusterReconciler) reconcileUpgrade(ctx context.Context, bssCluster *bssv1alpha1.BssCluster, log logr.Logger) (time.Duration, error) {
+	status := &bssCluster.Status
+
+	// spec.version is installed directly, as it was before upgrades were
+	// orchestrated
+	if status.CurrentVersion == "" {
+		status.CurrentVersion = bssCluster.Spec.Version
+		return 0, nil
+	}
+
+	// Changes to spec.version during an upgrade are picked up once it completes
+	if status.TargetVersion == "" {
+		if started, wait := r.startUpgrade(bssCluster, log); !started {
+			return wait, nil
+		}
+	}
+
+	upgrade := status.Upgrade
+	switch upgrade.Phase {
+	case bssv1alpha1.UpgradePhasePreUpgrade:
+		return r.runPreUpgrade(ctx, bssCluster, log)
+	case bssv1alpha1.UpgradePhaseRollingOut:
+		return r.watchRollout(ctx, bssCluster, log)
+	case bssv1alpha1.UpgradePhasePostUpgrade:
+		return 0, r.runPostUpgrade(ctx, bssCluster, log)
+	case bssv1alpha1.UpgradePhaseRollingBack:
+		return 0, r.watchRollback(ctx, bssCluster, log)
+	}
+	return 0, nil
+}
+
+// startUpgrade starts an upgrade when spec.version differs from the current
+// version, unless the upgrade path is not allowed, an upgrade to the same
+// version already failed or the maintenance window is closed. It reports
+// whether an upgrade was started, and otherwise how long until the
+// maintenance window opens, if that is what the upgrade waits for.
+func (r *BssClusterReconciler) startUpgrade(bssCluster *bssv1alpha1.BssCluster, log logr.Logger) (bool, time.Duration) {
+	status := &bssCluster.Status
+	from, to := status.CurrentVersion, bssCluster.Spec.Version
+	if to == from {
+		// A blocked or scheduled upgrade was given up on
+		if upgrading := meta.FindStatusCondition(status.Conditions, TypeUpgrading); upgrading != nil &&
+			(upgrading.Reason == ReasonUpgradeBlocked || upgrading.Reason == ReasonUpgradeScheduled) {
+			meta.RemoveStatusCondition(&status.Conditions, TypeUpgrading)
+		}
+		return false, 0
+	}
+
+	// A failed or rolled back upgrade is not retried until spec.version changes
+	if upgrade := status.Upgrade; upgrade != nil && upgrade.ToVersion == to &&
+		(upgrade.Phase == bssv1alpha1.UpgradePhaseFailed || upgrade.Phase == bssv1alpha1.UpgradePhaseRolledBack) {
+		return false, 0
+	}
+
+	// The webhook rejects unsafe paths too, but only against the previous spec
+	if bssCluster.Annotations[bssv1alpha1.AnnotationAllowUnsafeUpgrade] != "true" {
+		if err := validation.ValidateUpgradePath(from, to); err != nil {
+			message := fmt.Sprintf("%v; set the %s annotation to allow it", err, bssv1alpha1.AnnotationAllowUnsafeUpgrade)
+			if upgrading := meta.FindStatusCondition(status.Conditions, TypeUpgrading); upgrading == nil ||
+				upgrading.Reason != ReasonUpgradeBlocked || upgrading.Message != message {
+				log.Info("Upgrade blocked", "from", from, "to", to, "reason", err.Error())
+				r.recordEvent(bssCluster, corev1.EventTypeWarning, ReasonUpgradeBlocked, message)
+			}
+			setCondition(bssCluster, TypeUpgrading, metav1.ConditionFalse, ReasonUpgradeBlocked, message)
+			return false, 0
+		}
+	}
+
+	// Upgrades roll every pod, so they only start within the maintenance window
+	if opens, open := builder.NextMaintenanceWindow(bssCluster, time.Now()); !open {
+		message := fmt.Sprintf("Upgrade from %s to %s waits for the maintenance window opening at %s",
+			from, to, opens.UTC().Format(time.RFC3339))
+		if upgrading := meta.FindStatusCondition(status.Conditions, TypeUpgrading); upgrading == nil ||
+			upgrading.Reason != ReasonUpgradeScheduled || upgrading.Message != message {
+			log.Info("Upgrade scheduled for the maintenance window", "from", from, "to", to, "opens", opens)
+			r.recordEvent(bssCluster, corev1.EventTypeNormal, ReasonUpgradeScheduled, message)
+		}
+		setCondition(bssCluster, TypeUpgrading, metav1.ConditionFalse, ReasonUpgradeScheduled, message)
+		return false, time.Until(opens)
+	}
+
+	now := metav1.NewTime(time.Now().Truncate(time.Second))
+	status.TargetVersion = to
+	status.Upgrade = &bssv1alpha1.UpgradeStatus{
+		FromVersion: from,
+		ToVersion:   to,
+		StartTime:   now,
+	}
+	if builder.UpgradeJob(bssCluster, builder.PreUpgradeHook) != nil {
+		r.setUpgradePhase(bssCluster, bssv1alpha1.UpgradePhasePreUpgrade, log,
+			fmt.Sprintf("Running the pre-upgrade Job before upgrading bss-api from %s to %s", from, to))
+	} else {
+		r.startRollout(bssCluster, log, fmt.Sprintf("Upgrading bss-api from %s to %s", from, to))
+	}
+	return true, 0
+}
+
+// runPreUpgrade waits for the pre-upgrade Job and rolls out the new version
+// once it succeeds. A failed Job fails the upgrade before anything is rolled
+// out, so the current version keeps running.
+func (r *BssClusterReconciler) runPreUpgrade(ctx context.Context, bssCluster *bssv1alpha1.BssCluster, log logr.Logger) (time.Duration, error) {
+	upgrade := bssCluster.Status.Upgrade
+	finished, failure, err := r.runUpgradeJob(ctx, bssCluster, builder.PreUpgradeHook, log)
+	if err != nil || !finished {
+		return 0, err
+	}
+	if failure != "" {
+		r.completeUpgrade(bssCluster, bssv1alpha1.UpgradePhaseFailed, log,
+			fmt.Sprintf("Pre-upgrade Job failed, bss-api %s keeps running: %s", upgrade.FromVersion, failure))
+		return 0, nil
+	}
+	r.startRollout(bssCluster, log, fmt.Sprintf("Pre-upgrade Job succeeded, rolling out bss-api %s", upgrade.ToVersion))
+	return builder.UpgradeTimeout(bssCluster), nil
+}
+
+// startRollout moves an upgrade to rolling out the new version, which starts
+// the upgrade timeout
+func (r *BssClusterReconciler) startRollout(bssCluster *bssv1alpha1.BssCluster, log logr.Logger, message string) {
+	bssCluster.Status.Upgrade.RolloutStartTime = &metav1.Time{Time: time.Now().Truncate(time.Second)}
+	r.setUpgradePhase(bssCluster, bssv1alpha1.UpgradePhaseRollingOut, log, message)
+}
+
+// watchRollout waits for every replica to run the new version and be ready,
+// and rolls back if that takes longer than the upgrade timeout or the
+// rollout gets stuck
+func (r *BssClusterReconciler) watchRollout(ctx context.Context, bssCluster *bssv1alpha1.BssCluster, log logr.Logger) (time.Duration, error) {
+	upgrade := bssCluster.Status.Upgrade
+	state, err := r.getWorkloadState(ctx, bssCluster, bssCluster.Status.Workload)
+	if err != nil {
+		return 0, err
+	}
+
+	if state != nil && state.version == upgrade.ToVersion && state.ready() {
+		if builder.UpgradeJob(bssCluster, builder.PostUpgradeHook) != nil {
+			r.setUpgradePhase(bssCluster, bssv1alpha1.UpgradePhasePostUpgrade, log,
+				fmt.Sprintf("bss-api %s is ready, running the post-upgrade Job", upgrade.ToVersion))
+			return 0, r.runPostUpgrade(ctx, bssCluster, log)
+		}
+		r.completeUpgrade(bssCluster, bssv1alpha1.UpgradePhaseSucceeded, log,
+			fmt.Sprintf("Upgraded bss-api from %s to %s", upgrade.FromVersion, upgrade.ToVersion))
+		return 0, nil
+	}
+
+	if state != nil && state.version == upgrade.ToVersion && state.stuckMessage != "" {
+		r.rollBack(bssCluster, log, fmt.Sprintf("Rollout of bss-api %s is stuck: %s", upgrade.ToVersion, state.stuckMessage))
+		return 0, nil
+	}
+
+	timeout := builder.UpgradeTimeout(bssCluster)
+	remaining := time.Until(upgrade.RolloutStartTime.Add(timeout))
+	if remaining <= 0 {
+		r.rollBack(bssCluster, log, fmt.Sprintf("bss-api %s did not become ready within %s", upgrade.ToVersion, timeout))
+		return 0, nil
+	}
+	log.V(1).Info("Waiting for upgraded workload to become ready", "version", upgrade.ToVersion, "timeout", remaining)
+	return remaining, nil
+}
+
+// runPostUpgrade waits for the post-upgrade Job against the new version and
+// completes the upgrade once it succeeds, or rolls back if it fails
+func (r *BssClusterReconciler) runPostUpgrade(ctx context.Context, bssCluster *bssv1alpha1.BssCluster, log logr.Logger) error {
+	upgrade := bssCluster.Status.Upgrade
+	finished, failure, err := r.runUpgradeJob(ctx, bssCluster, builder.PostUpgradeHook, log)
+	if err != nil || !finished {
+		return err
+	}
+	if failure != "" {
+		r.rollBack(bssCluster, log, fmt.Sprintf("Post-upgrade Job failed: %s", failure))
+		return nil
+	}
+	r.completeUpgrade(bssCluster, bssv1alpha1.UpgradePhaseSucceeded, log,
+		fmt.Sprintf("Upgraded bss-api from %s to %s", upgrade.FromVersion, upgrade.ToVersion))
+	return nil
+}
+
+// rollBack moves an upgrade to rolling the previous version back out
+func (r *BssClusterReconciler) rollBack(bssCluster *bssv1alpha1.BssCluster, log logr.Logger, reason string) {
+	upgrade := bssCluster.Status.Upgrade
+	upgrade.Message = reason
+	r.setUpgradePhase(bssCluster, bssv1alpha1.UpgradePhaseRollingBack, log,
+		fmt.Sprintf("Rolling back to bss-api %s: %s", upgrade.FromVersion, reason))
+}
+
+// watchRollback waits for every replica to run the previous version again.
+// A rollback has no timeout: there is no older version to fall back to.
+func (r *BssClusterReconciler) watchRollback(ctx context.Context, bssCluster *bssv1alpha1.BssCluster, log logr.Logger) error {
+	upgrade := bssCluster.Status.Upgrade
+	state, err := r.getWorkloadState(ctx, bssCluster, bssCluster.Status.Workload)
+	if err != nil {
+		return err
+	}
+	if state == nil || state.version != upgrade.FromVersion || !state.ready() {
+		log.V(1).Info("Waiting for rolled back workload to become ready", "version", upgrade.FromVersion)
+		return nil
+	}
+	r.completeUpgrade(bssCluster, bssv1alpha1.UpgradePhaseRolledBack, log,
+		fmt.Sprintf("Rolled back to bss-api %s: %s", upgrade.FromVersion, upgrade.Message))
+	return nil
+}
+
+// completeUpgrade ends an upgrade in the given phase. Only a successful
+// upgrade moves the current version.
+func (r *BssClusterReconciler) completeUpgrade(bssCluster *bssv1alpha1.BssCluster, phase bssv1alpha1.UpgradePhase, log logr.Logger, message string) {
+	status := &bssCluster.Status
+	if phase == bssv1alpha1.UpgradePhaseSucceeded {
+		status.CurrentVersion = status.TargetVersion
+	} else {
+		status.Upgrade.Message = message
+	}
+	status.TargetVersion = ""
+	status.Upgrade.CompletionTime = &metav1.Time{Time: time.Now().Truncate(time.Second)}
+	r.setUpgradePhase(bssCluster, phase, log, message)
+}
+
+// setUpgradePhase moves an upgrade to the given phase, recording it in the
+// Upgrading condition and as an Event
+func (r *BssClusterReconciler) setUpgradePhase(bssCluster *bssv1alpha1.BssCluster, phase bssv1alpha1.UpgradePhase, log logr.Logger, message string) {
+	bssCluster.Status.Upgrade.Phase = phase
+	phaseReason := upgradePhaseReasons[phase]
+	conditionStatus := metav1.ConditionTrue
+	if bssCluster.Status.TargetVersion == "" {
+		conditionStatus = metav1.ConditionFalse
+	}
+	setCondition(bssCluster, TypeUpgrading, conditionStatus, phaseReason.reason, message)
+
+	log.Info("Upgrade "+string(phase), "message", message)
+	eventType := corev1.EventTypeNormal
+	if phaseReason.warning {
+		eventType = corev1.EventTypeWarning
+	}
+	r.recordEvent(bssCluster, eventType, phaseReason.reason, message)
+}
+
+// runUpgradeJob creates the Job of an upgrade hook and reports whether it
+// has finished, and why it failed if it did. A Job left over from an earlier
+// upgrade is deleted first. A hook removed from the spec during its phase
+// counts as succeeded.
+func (r *BssClusterReconciler) runUpgradeJob(ctx context.Context, bssCluster *bssv1alpha1.BssCluster, hook builder.UpgradeHook, log logr.Logger) (bool, string, error) {
+	if builder.UpgradeJob(bssCluster, hook) == nil {
+		return true, "", nil
+	}
+
+	desired := builder.NewUpgradeJobBuilder(bssCluster, hook).WithImageResolver(r.imageResolver).Build()
+	job := &batchv1.Job{}
+	if err := r.Get(ctx, client.ObjectKeyFromObject(desired), job); err != nil {
+		if !errors.IsNotFound(err) {
+			return false, "", err
+		}
+		// The pod template of a Job is immutable, so it is only ever created
+		return false, "", r.applier.Apply(ctx, bssCluster, desired, log)
+	}
+
+	if !metav1.IsControlledBy(job, bssCluster) {
+		return false, "", fmt.Errorf("job %s exists and is not owned by the BssCluster", job.Name)
+	}
+	if !job.DeletionTimestamp.IsZero() {
+		log.V(1).Info("Waiting for previous upgrade Job to be deleted", "name", job.Name)
+		return false, "", nil
+	}
+	startTime := desired.Annotations[bssv1alpha1.AnnotationUpgradeStartTime]
+	if job.Annotations[bssv1alpha1.AnnotationUpgradeStartTime] != startTime {
+		log.Info("Deleting upgrade Job of a previous upgrade", "name", job.Name)
+		return false, "", client.IgnoreNotFound(r.Delete(ctx, job, client.PropagationPolicy(metav1.DeletePropagationBackground)))
+	}
+
+	finished, failure := jobFinished(job)
+	return finished, failure, nil
+}
+
+// jobFinished reports whether a Job has completed or failed, and the
+// reason it failed
+func jobFinished(job *batchv1.Job) (bool, string) {
+	for _, condition := range job.Status.Conditions {
+		if condition.Status != corev1.ConditionTrue {
+			continue
+		}
+		switch condition.Type {
+		case batchv1.JobComplete:
+			return true, ""
+		case batchv1.JobFailed:
+			if condition.Message == "" {
+				return true, condition.Reason
+			}
+			return true, condition.Message
+		}
+	}
+	return false, ""
+}
+
+// recordEvent records an Event on the BssCluster if a recorder is configured
+func (r *BssClusterReconciler) recordEvent(bssCluster *bssv1alpha1.BssCluster, eventType, reason, message string) {
+	if r.recorder != nil {
+		r.recorder.Event(bssCluster, eventType, reason, message)
+	}
+}
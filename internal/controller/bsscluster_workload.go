@@ -0,0 +1,221 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-logr/logr"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	bssv1alpha1 "github.com/brmorris/bss-operator/api/v1alpha1"
+	"github.com/brmorris/bss-operator/internal/builder"
+)
+
+// migrationPollInterval is how often a migration re-checks that the volume
+// claims of the previous StatefulSet are retained
+const migrationPollInterval = 2 * time.Second
+
+// activeWorkload returns the kind of workload currently serving the BssCluster.
+// Clusters created before workload modes existed ran a Deployment.
+func (r *BssClusterReconciler) activeWorkload(ctx context.Context, bssCluster *bssv1alpha1.BssCluster) (bssv1alpha1.WorkloadType, error) {
+	if bssCluster.Status.Workload != "" {
+		return bssCluster.Status.Workload, nil
+	}
+	state, err := r.getWorkloadState(ctx, bssCluster, bssv1alpha1.WorkloadTypeDeployment)
+	if err != nil {
+		return "", err
+	}
+	if state != nil {
+		return bssv1alpha1.WorkloadTypeDeployment, nil
+	}
+	return builder.Workload(bssCluster), nil
+}
+
+// getWorkloadState returns the rollout state of the given workload of a
+// BssCluster, or nil if it does not exist
+func (r *BssClusterReconciler) getWorkloadState(ctx context.Context, bssCluster *bssv1alpha1.BssCluster, kind bssv1alpha1.WorkloadType) (*workloadState, error) {
+	key := types.NamespacedName{Name: bssCluster.Name, Namespace: bssCluster.Namespace}
+
+	if kind == bssv1alpha1.WorkloadTypeStatefulSet {
+		statefulSet := &appsv1.StatefulSet{}
+		if err := r.Get(ctx, key, statefulSet); err != nil {
+			if errors.IsNotFound(err) {
+				return nil, nil
+			}
+			return nil, err
+		}
+		return newStatefulSetState(statefulSet), nil
+	}
+
+	deployment := &appsv1.Deployment{}
+	if err := r.Get(ctx, key, deployment); err != nil {
+		if errors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return newDeploymentState(deployment), nil
+}
+
+// observeWorkloads derives availability and rollout progress from the
+// workloads of a BssCluster and drives a pending migration between workload
+// kinds: the previous workload is only deleted once the requested one is ready.
+// It returns how long until a migration waiting on the previous workload is
+// checked again, or zero.
+func (r *BssClusterReconciler) observeWorkloads(ctx context.Context, bssCluster *bssv1alpha1.BssCluster, log logr.Logger) (time.Duration, error) {
+	desired := builder.Workload(bssCluster)
+	active, err := r.activeWorkload(ctx, bssCluster)
+	if err != nil {
+		return 0, err
+	}
+
+	next, err := r.getWorkloadState(ctx, bssCluster, desired)
+	if err != nil {
+		return 0, err
+	}
+	observeWorkload(bssCluster, desired, next)
+
+	if active == desired {
+		bssCluster.Status.Workload = desired
+		return 0, r.observeRunningImage(ctx, bssCluster, log)
+	}
+
+	previous, err := r.getWorkloadState(ctx, bssCluster, active)
+	if err != nil {
+		return 0, err
+	}
+	if next == nil || !next.ready() {
+		log.Info("Waiting for new workload before migrating", "from", active, "to", desired)
+		observeMigration(bssCluster, previous, next)
+		return 0, nil
+	}
+
+	if active == bssv1alpha1.WorkloadTypeStatefulSet {
+		retained, err := r.retainClaims(ctx, bssCluster, log)
+		if err != nil {
+			return 0, err
+		}
+		if !retained {
+			log.Info("Waiting for volume claims to be retained before migrating", "from", active, "to", desired)
+			observeMigration(bssCluster, previous, next)
+			return migrationPollInterval, nil
+		}
+	}
+
+	log.Info("New workload is ready, removing previous workload", "from", active, "to", desired)
+	if err := r.deleteWorkload(ctx, bssCluster, active, log); err != nil {
+		return 0, err
+	}
+	bssCluster.Status.Workload = desired
+	return 0, nil
+}
+
+// deleteWorkload deletes the given workload of a BssCluster. A StatefulSet
+// is only deleted once retainClaims reports its volume claims retained, so
+// that migrating back does not lose data. The retained claims are no longer
+// deleted with the BssCluster.
+func (r *BssClusterReconciler) deleteWorkload(ctx context.Context, bssCluster *bssv1alpha1.BssCluster, kind bssv1alpha1.WorkloadType, log logr.Logger) error {
+	return r.child(string(kind)).Delete(ctx, bssCluster, log)
+}
+
+// retainClaims sets the claim retention policy of the StatefulSet of a
+// BssCluster to Retain, whatever spec.storage asked for, and reports whether
+// deleting the StatefulSet now leaves its volume claims in place: the
+// StatefulSet controller has observed the policy and no claim is owned by the
+// StatefulSet any more.
+func (r *BssClusterReconciler) retainClaims(ctx context.Context, bssCluster *bssv1alpha1.BssCluster, log logr.Logger) (bool, error) {
+	statefulSet := &appsv1.StatefulSet{}
+	key := types.NamespacedName{Name: bssCluster.Name, Namespace: bssCluster.Namespace}
+	if err := r.Get(ctx, key, statefulSet); err != nil {
+		if errors.IsNotFound(err) {
+			return true, nil
+		}
+		return false, err
+	}
+
+	if policy := statefulSet.Spec.PersistentVolumeClaimRetentionPolicy; policy != nil &&
+		policy.WhenDeleted != appsv1.RetainPersistentVolumeClaimRetentionPolicyType {
+		log.Info("Retaining volume claims of the previous workload", "name", statefulSet.Name)
+		patch := client.MergeFrom(statefulSet.DeepCopy())
+		statefulSet.Spec.PersistentVolumeClaimRetentionPolicy.WhenDeleted = appsv1.RetainPersistentVolumeClaimRetentionPolicyType
+		return false, r.Patch(ctx, statefulSet, patch)
+	}
+	if statefulSet.Status.ObservedGeneration < statefulSet.Generation {
+		return false, nil
+	}
+
+	var claims corev1.PersistentVolumeClaimList
+	if err := r.List(ctx, &claims,
+		client.InNamespace(bssCluster.Namespace),
+		client.MatchingLabels(builder.SelectorLabels(bssCluster)),
+	); err != nil {
+		return false, err
+	}
+	for i := range claims.Items {
+		for _, owner := range claims.Items[i].OwnerReferences {
+			if owner.UID == statefulSet.UID {
+				return false, nil
+			}
+		}
+	}
+	return true, nil
+}
+
+// observeRunningImage records the image ID reported by the kubelet once every
+// ready bss-api container runs the rolled out image. The ID is left unchanged
+// while pods disagree, for example in the middle of a rollout.
+func (r *BssClusterReconciler) observeRunningImage(ctx context.Context, bssCluster *bssv1alpha1.BssCluster, log logr.Logger) error {
+	if bssCluster.Status.Image == "" {
+		return nil
+	}
+
+	var pods corev1.PodList
+	if err := r.List(ctx, &pods,
+		client.InNamespace(bssCluster.Namespace),
+		client.MatchingLabels(builder.SelectorLabels(bssCluster)),
+	); err != nil {
+		return err
+	}
+
+	imageID := ""
+	for i := range pods.Items {
+		for _, containerStatus := range pods.Items[i].Status.ContainerStatuses {
+			if containerStatus.Name != builder.ContainerName || !containerStatus.Ready {
+				continue
+			}
+			if containerImage(&pods.Items[i].Spec) != bssCluster.Status.Image {
+				return nil
+			}
+			if imageID != "" && imageID != containerStatus.ImageID {
+				return nil
+			}
+			imageID = containerStatus.ImageID
+		}
+	}
+
+	if imageID != "" && imageID != bssCluster.Status.ImageID {
+		log.Info("bss-api image is running", "image", bssCluster.Status.Image, "imageID", imageID)
+		bssCluster.Status.ImageID = imageID
+	}
+	return nil
+}
@@ -0,0 +1,187 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	bssv1alpha1 "github.com/brmorris/bss-operator/api/v1alpha1"
+	bssclient "github.com/brmorris/bss-operator/internal/client"
+)
+
+// secretRefIndex indexes BSSQueries by the names of the Secrets they read
+// credentials and certificates from
+const secretRefIndex = "spec.secretRefs"
+
+// newQueryClient returns the GraphQL client of a BSSQuery with the
+// credentials, headers and certificates of spec.auth. They are read on every
+// reconcile, and changes to their Secrets trigger one, so rotated
+// credentials are picked up. Queries of the same endpoint with the same
+// configuration share a client from the registry.
+func (r *BSSQueryReconciler) newQueryClient(ctx context.Context, bssQuery *bssv1alpha1.BSSQuery, endpoint string) (*bssclient.GraphQLClient, error) {
+	config, err := r.clientConfig(ctx, bssQuery)
+	if err != nil {
+		return nil, err
+	}
+	return r.Clients.Client(endpoint, config)
+}
+
+// clientConfig reads the client configuration of spec.auth
+func (r *BSSQueryReconciler) clientConfig(ctx context.Context, bssQuery *bssv1alpha1.BSSQuery) (bssclient.Config, error) {
+	auth := bssQuery.Spec.Auth
+	if auth == nil {
+		return bssclient.Config{}, nil
+	}
+
+	config := bssclient.Config{Headers: auth.Headers}
+	switch {
+	case auth.BearerTokenSecretRef != nil:
+		token, err := r.secretValue(ctx, bssQuery.Namespace, auth.BearerTokenSecretRef.Name, auth.BearerTokenSecretRef.Key)
+		if err != nil {
+			return config, err
+		}
+		config.BearerToken = string(token)
+	case auth.BasicAuthSecretRef != nil:
+		username, err := r.secretValue(ctx, bssQuery.Namespace, auth.BasicAuthSecretRef.Name, corev1.BasicAuthUsernameKey)
+		if err != nil {
+			return config, err
+		}
+		password, err := r.secretValue(ctx, bssQuery.Namespace, auth.BasicAuthSecretRef.Name, corev1.BasicAuthPasswordKey)
+		if err != nil {
+			return config, err
+		}
+		config.BasicAuth = &bssclient.BasicAuth{Username: string(username), Password: string(password)}
+	}
+
+	if auth.TLS != nil {
+		tlsOptions, err := r.tlsOptions(ctx, bssQuery)
+		if err != nil {
+			return config, err
+		}
+		config.TLS = &tlsOptions
+	}
+	return config, nil
+}
+
+// tlsOptions reads the CA bundle and client certificate of a BSSQuery
+func (r *BSSQueryReconciler) tlsOptions(ctx context.Context, bssQuery *bssv1alpha1.BSSQuery) (bssclient.TLSOptions, error) {
+	spec := bssQuery.Spec.Auth.TLS
+	opts := bssclient.TLSOptions{ServerName: spec.ServerName}
+	var err error
+
+	if ca := spec.CA; ca != nil {
+		switch {
+		case ca.ConfigMapKeyRef != nil:
+			opts.CABundle, err = r.configMapValue(ctx, bssQuery.Namespace, ca.ConfigMapKeyRef.Name, ca.ConfigMapKeyRef.Key)
+		case ca.SecretKeyRef != nil:
+			opts.CABundle, err = r.secretValue(ctx, bssQuery.Namespace, ca.SecretKeyRef.Name, ca.SecretKeyRef.Key)
+		}
+		if err != nil {
+			return opts, err
+		}
+	}
+
+	if ref := spec.ClientCertSecretRef; ref != nil {
+		if opts.ClientCert, err = r.secretValue(ctx, bssQuery.Namespace, ref.Name, corev1.TLSCertKey); err != nil {
+			return opts, err
+		}
+		if opts.ClientKey, err = r.secretValue(ctx, bssQuery.Namespace, ref.Name, corev1.TLSPrivateKeyKey); err != nil {
+			return opts, err
+		}
+	}
+	return opts, nil
+}
+
+// secretValue returns the value of a key of a Secret
+func (r *BSSQueryReconciler) secretValue(ctx context.Context, namespace, name, key string) ([]byte, error) {
+	secret := &corev1.Secret{}
+	if err := r.Get(ctx, types.NamespacedName{Name: name, Namespace: namespace}, secret); err != nil {
+		if errors.IsNotFound(err) {
+			return nil, fmt.Errorf("referenced Secret %s not found", name)
+		}
+		return nil, err
+	}
+	value, ok := secret.Data[key]
+	if !ok {
+		return nil, fmt.Errorf("referenced Secret %s has no key %s", name, key)
+	}
+	return value, nil
+}
+
+// configMapValue returns the value of a key of a ConfigMap
+func (r *BSSQueryReconciler) configMapValue(ctx context.Context, namespace, name, key string) ([]byte, error) {
+	configMap := &corev1.ConfigMap{}
+	if err := r.Get(ctx, types.NamespacedName{Name: name, Namespace: namespace}, configMap); err != nil {
+		if errors.IsNotFound(err) {
+			return nil, fmt.Errorf("referenced ConfigMap %s not found", name)
+		}
+		return nil, err
+	}
+	value, ok := configMap.Data[key]
+	if !ok {
+		return nil, fmt.Errorf("referenced ConfigMap %s has no key %s", name, key)
+	}
+	return []byte(value), nil
+}
+
+// referencedSecrets returns the names of the Secrets a BSSQuery reads
+func referencedSecrets(bssQuery *bssv1alpha1.BSSQuery) []string {
+	auth := bssQuery.Spec.Auth
+	if auth == nil {
+		return nil
+	}
+	var names []string
+	if auth.BearerTokenSecretRef != nil {
+		names = append(names, auth.BearerTokenSecretRef.Name)
+	}
+	if auth.BasicAuthSecretRef != nil {
+		names = append(names, auth.BasicAuthSecretRef.Name)
+	}
+	if auth.TLS != nil {
+		if auth.TLS.CA != nil && auth.TLS.CA.SecretKeyRef != nil {
+			names = append(names, auth.TLS.CA.SecretKeyRef.Name)
+		}
+		if auth.TLS.ClientCertSecretRef != nil {
+			names = append(names, auth.TLS.ClientCertSecretRef.Name)
+		}
+	}
+	return names
+}
+
+// queriesForSecret returns a request for every BSSQuery reading a Secret
+func (r *BSSQueryReconciler) queriesForSecret(ctx context.Context, obj client.Object) []reconcile.Request {
+	var bssQueries bssv1alpha1.BSSQueryList
+	if err := r.List(ctx, &bssQueries, client.InNamespace(obj.GetNamespace()),
+		client.MatchingFields{secretRefIndex: obj.GetName()}); err != nil {
+		log.FromContext(ctx).Error(err, "Failed to list BSSQueries reading Secret", "secret", obj.GetName())
+		return nil
+	}
+
+	requests := make([]reconcile.Request, 0, len(bssQueries.Items))
+	for i := range bssQueries.Items {
+		requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&bssQueries.Items[i])})
+	}
+	return requests
+}
@@ -19,42 +19,90 @@ package controller
 import (
 	"context"
 	"encoding/json"
+	stderrors "errors"
 	"fmt"
+	"sync"
 	"time"
 
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
 	ctrl "sigs.k8s.io/controller-runtime"
+	ctrlbuilder "sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	bssv1alpha1 "github.com/brmorris/bss-operator/api/v1alpha1"
+	"github.com/brmorris/bss-operator/internal/builder"
 	bssclient "github.com/brmorris/bss-operator/internal/client"
+	"github.com/brmorris/bss-operator/internal/validation"
 )
 
 const (
 	// Condition types
 	TypeAvailable = "Available"
 	TypeDegraded  = "Degraded"
+	TypePaused    = "Paused"
+
+	// TypeEndpointUnavailable is true while the circuit breaker of the
+	// endpoint of a BSSQuery keeps its requests from being sent
+	TypeEndpointUnavailable = "EndpointUnavailable"
 
 	// Condition reasons
-	ReasonReconciling   = "Reconciling"
-	ReasonQuerySuccess  = "QuerySuccess"
-	ReasonQueryFailed   = "QueryFailed"
-	ReasonInvalidConfig = "InvalidConfig"
+	ReasonReconciling     = "Reconciling"
+	ReasonQuerySuccess    = "QuerySuccess"
+	ReasonQueryFailed     = "QueryFailed"
+	ReasonInvalidConfig   = "InvalidConfig"
+	ReasonClusterNotReady = "ClusterNotReady"
+	ReasonQueryInvalid    = "QueryInvalid"
+	ReasonUnauthorized    = "Unauthorized"
+	ReasonQueryRejected   = "QueryRejected"
+	ReasonClusterNotFound = "ClusterNotFound"
+
+	// ReasonEndpointUnavailable is the reason of the Degraded condition while
+	// the circuit breaker of the endpoint is open
+	ReasonEndpointUnavailable = "EndpointUnavailable"
+
+	// Reasons of the EndpointUnavailable condition
+	ReasonCircuitOpen   = "CircuitOpen"
+	ReasonCircuitClosed = "CircuitClosed"
+
+	// Reasons of the Paused condition
+	ReasonSpecPaused       = "SpecPaused"
+	ReasonAnnotationPaused = "AnnotationPaused"
+	ReasonNotPaused        = "NotPaused"
 )
 
+// clusterRefIndex indexes BSSQueries by the namespace/name of the BssCluster
+// they reference
+const clusterRefIndex = "spec.clusterRef"
+
 // BSSQueryReconciler reconciles a BSSQuery object
 type BSSQueryReconciler struct {
 	client.Client
 	Scheme *runtime.Scheme
+
+	// Clients shares GraphQL clients between queries of the same endpoint,
+	// and limits the requests sent to each endpoint. SetupWithManager
+	// creates one with the default limits if it is nil.
+	Clients *bssclient.Registry
+
+	// Digests of the GraphQL documents validated against the schema of their
+	// endpoint, by BSSQuery
+	validated sync.Map
 }
 
 // +kubebuilder:rbac:groups=bss.localhost,resources=bssqueries,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=bss.localhost,resources=bssqueries/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=bss.localhost,resources=bssqueries/finalizers,verbs=update
+// +kubebuilder:rbac:groups=bss.localhost,resources=bssclusters,verbs=get;list;watch
+// +kubebuilder:rbac:groups=core,resources=configmaps;secrets,verbs=get;list;watch;create;update;patch;delete
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
@@ -66,12 +114,45 @@ func (r *BSSQueryReconciler) Reconcile(ctx context.Context, req ctrl.Request) (c
 	if err := r.Get(ctx, req.NamespacedName, bssQuery); err != nil {
 		if errors.IsNotFound(err) {
 			logger.Info("BSSQuery resource not found. Ignoring since object must be deleted")
+			r.validated.Delete(req.NamespacedName)
 			return ctrl.Result{}, nil
 		}
 		logger.Error(err, "Failed to get BSSQuery")
 		return ctrl.Result{}, err
 	}
 
+	// Annotations written on a target are removed before the query goes away
+	if !bssQuery.DeletionTimestamp.IsZero() {
+		if err := r.finalizeOutput(ctx, bssQuery); err != nil {
+			logger.Error(err, "Failed to remove output annotations")
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{}, nil
+	}
+
+	// A paused query keeps its last result and is neither changed nor polled
+	// until resumed
+	if reason, message := queryPauseReason(bssQuery); reason != "" {
+		meta.SetStatusCondition(&bssQuery.Status.Conditions, metav1.Condition{
+			Type:               TypePaused,
+			Status:             metav1.ConditionTrue,
+			Reason:             reason,
+			LastTransitionTime: metav1.Now(),
+			Message:            message,
+		})
+		if err := r.Status().Update(ctx, bssQuery); err != nil {
+			logger.Error(err, "Failed to update BSSQuery status")
+			return ctrl.Result{}, err
+		}
+		logger.Info("BSSQuery is paused, skipping query")
+		return ctrl.Result{}, nil
+	}
+
+	if err := r.reconcileOutputFinalizer(ctx, bssQuery); err != nil {
+		logger.Error(err, "Failed to update BSSQuery finalizers")
+		return ctrl.Result{}, err
+	}
+
 	// Set the status as Unknown when no status is available
 	if len(bssQuery.Status.Conditions) == 0 {
 		meta.SetStatusCondition(&bssQuery.Status.Conditions, metav1.Condition{
@@ -93,6 +174,14 @@ func (r *BSSQueryReconciler) Reconcile(ctx context.Context, req ctrl.Request) (c
 		}
 	}
 
+	meta.SetStatusCondition(&bssQuery.Status.Conditions, metav1.Condition{
+		Type:               TypePaused,
+		Status:             metav1.ConditionFalse,
+		Reason:             ReasonNotPaused,
+		LastTransitionTime: metav1.Now(),
+		Message:            "Query is polled every refresh interval",
+	})
+
 	// Validate the query configuration
 	if err := r.validateQuery(bssQuery); err != nil {
 		meta.SetStatusCondition(&bssQuery.Status.Conditions, metav1.Condition{
@@ -109,13 +198,96 @@ func (r *BSSQueryReconciler) Reconcile(ctx context.Context, req ctrl.Request) (c
 		return ctrl.Result{}, err
 	}
 
+	// Resolve the endpoint; a BssCluster that is not ready yet triggers a
+	// reconcile through the watch once it is
+	endpoint, notReady, err := r.resolveEndpoint(ctx, bssQuery)
+	if err != nil {
+		logger.Error(err, "Failed to resolve the API endpoint")
+		return ctrl.Result{}, err
+	}
+	bssQuery.Status.Endpoint = endpoint
+	if notReady != "" {
+		logger.Info("Waiting for BssCluster", "reason", notReady)
+		meta.SetStatusCondition(&bssQuery.Status.Conditions, metav1.Condition{
+			Type:               TypeAvailable,
+			Status:             metav1.ConditionFalse,
+			Reason:             ReasonClusterNotReady,
+			LastTransitionTime: metav1.Now(),
+			Message:            notReady,
+		})
+		meta.SetStatusCondition(&bssQuery.Status.Conditions, metav1.Condition{
+			Type:               TypeDegraded,
+			Status:             metav1.ConditionTrue,
+			Reason:             ReasonClusterNotReady,
+			LastTransitionTime: metav1.Now(),
+			Message:            notReady,
+		})
+		if err := r.Status().Update(ctx, bssQuery); err != nil {
+			logger.Error(err, "Failed to update BSSQuery status")
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{}, nil
+	}
+
+	// Credentials are read again on every reconcile; a missing Secret or
+	// ConfigMap triggers one through the watches once it is created
+	gqlClient, err := r.newQueryClient(ctx, bssQuery, endpoint)
+	if err != nil {
+		logger.Error(err, "Failed to configure the GraphQL client")
+		meta.SetStatusCondition(&bssQuery.Status.Conditions, metav1.Condition{
+			Type:               TypeDegraded,
+			Status:             metav1.ConditionTrue,
+			Reason:             ReasonInvalidConfig,
+			LastTransitionTime: metav1.Now(),
+			Message:            fmt.Sprintf("Invalid auth: %v", err),
+		})
+		if err := r.Status().Update(ctx, bssQuery); err != nil {
+			logger.Error(err, "Failed to update BSSQuery status")
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{}, nil
+	}
+
+	// GraphQL documents are validated against the schema of the endpoint
+	// before they first run
+	var request *bssclient.GraphQLRequest
+	if bssQuery.Spec.Query == bssv1alpha1.QueryTypeGraphQL {
+		var reason string
+		if request, reason, err = r.prepareDocument(ctx, bssQuery, gqlClient, endpoint); err != nil {
+			logger.Error(err, "Failed to prepare GraphQL document", "reason", reason)
+			meta.SetStatusCondition(&bssQuery.Status.Conditions, metav1.Condition{
+				Type:               TypeDegraded,
+				Status:             metav1.ConditionTrue,
+				Reason:             reason,
+				LastTransitionTime: metav1.Now(),
+				Message:            err.Error(),
+			})
+			if reason != ReasonInvalidConfig {
+				setEndpointCondition(bssQuery, err)
+			}
+			if err := r.Status().Update(ctx, bssQuery); err != nil {
+				logger.Error(err, "Failed to update BSSQuery status")
+				return ctrl.Result{}, err
+			}
+			// A document that cannot be read is picked up again through the
+			// ConfigMap watch, while the schema may change on the server
+			if reason == ReasonInvalidConfig {
+				return ctrl.Result{}, nil
+			}
+			return ctrl.Result{RequeueAfter: failureRequeueAfter(bssQuery, err)}, nil
+		}
+	}
+
 	// Execute the GraphQL query
-	if err := r.executeQuery(ctx, bssQuery); err != nil {
-		logger.Error(err, "Failed to execute query")
+	result, err := r.executeQuery(ctx, bssQuery, gqlClient, request)
+	setEndpointCondition(bssQuery, err)
+	if err != nil {
+		reason, permanent := queryErrorReason(err)
+		logger.Error(err, "Failed to execute query", "reason", reason)
 		meta.SetStatusCondition(&bssQuery.Status.Conditions, metav1.Condition{
 			Type:               TypeDegraded,
 			Status:             metav1.ConditionTrue,
-			Reason:             ReasonQueryFailed,
+			Reason:             reason,
 			LastTransitionTime: metav1.Now(),
 			Message:            fmt.Sprintf("Query failed: %v", err),
 		})
@@ -123,12 +295,37 @@ func (r *BSSQueryReconciler) Reconcile(ctx context.Context, req ctrl.Request) (c
 			logger.Error(err, "Failed to update BSSQuery status")
 			return ctrl.Result{}, err
 		}
+		// A request the BSS API rejected fails the same way until the spec or
+		// its Secrets change, which trigger a reconcile
+		if permanent {
+			return ctrl.Result{}, nil
+		}
 		// Requeue with a delay
-		refreshInterval := time.Duration(bssQuery.Spec.RefreshInterval) * time.Second
-		if refreshInterval == 0 {
-			refreshInterval = 30 * time.Second
+		return ctrl.Result{RequeueAfter: failureRequeueAfter(bssQuery, err)}, nil
+	}
+
+	// Project the result into spec.output, keeping only its digest in status
+	recordResult(bssQuery, result)
+	if bssQuery.Spec.Output != nil {
+		err = r.writeOutput(ctx, bssQuery, result)
+	}
+	if err == nil {
+		err = r.pruneOutputs(ctx, bssQuery)
+	}
+	if err != nil {
+		logger.Error(err, "Failed to write query output")
+		meta.SetStatusCondition(&bssQuery.Status.Conditions, metav1.Condition{
+			Type:               TypeDegraded,
+			Status:             metav1.ConditionTrue,
+			Reason:             ReasonOutputFailed,
+			LastTransitionTime: metav1.Now(),
+			Message:            fmt.Sprintf("Output failed: %v", err),
+		})
+		if err := r.Status().Update(ctx, bssQuery); err != nil {
+			logger.Error(err, "Failed to update BSSQuery status")
+			return ctrl.Result{}, err
 		}
-		return ctrl.Result{RequeueAfter: refreshInterval}, nil
+		return ctrl.Result{RequeueAfter: refreshInterval(bssQuery)}, nil
 	}
 
 	// Update status with success
@@ -158,80 +355,246 @@ func (r *BSSQueryReconciler) Reconcile(ctx context.Context, req ctrl.Request) (c
 	}
 
 	// Requeue after refresh interval
-	refreshInterval := time.Duration(bssQuery.Spec.RefreshInterval) * time.Second
-	if refreshInterval == 0 {
-		refreshInterval = 30 * time.Second
+	requeueAfter := refreshInterval(bssQuery)
+	logger.Info("Successfully reconciled BSSQuery", "requeueAfter", requeueAfter)
+	return ctrl.Result{RequeueAfter: requeueAfter}, nil
+}
+
+// refreshInterval returns how often a BSSQuery is polled
+func refreshInterval(bssQuery *bssv1alpha1.BSSQuery) time.Duration {
+	if bssQuery.Spec.RefreshInterval == 0 {
+		return 30 * time.Second
 	}
+	return time.Duration(bssQuery.Spec.RefreshInterval) * time.Second
+}
+
+// failureRequeueAfter returns when a BSSQuery whose request failed is polled
+// again. While the circuit breaker of its endpoint is open it waits for the
+// breaker, whose timeout doubles every time the endpoint still fails, and the
+// delays are jittered so the queries of the endpoint do not come back at once.
+func failureRequeueAfter(bssQuery *bssv1alpha1.BSSQuery, err error) time.Duration {
+	var unavailable *bssclient.EndpointUnavailableError
+	if !stderrors.As(err, &unavailable) {
+		return refreshInterval(bssQuery)
+	}
+	return wait.Jitter(max(unavailable.RetryAfter, refreshInterval(bssQuery)), 0.1)
+}
 
-	logger.Info("Successfully reconciled BSSQuery", "requeueAfter", refreshInterval)
-	return ctrl.Result{RequeueAfter: refreshInterval}, nil
+// setEndpointCondition sets the EndpointUnavailable condition of a BSSQuery
+// from the outcome of its request to the BSS API
+func setEndpointCondition(bssQuery *bssv1alpha1.BSSQuery, err error) {
+	var unavailable *bssclient.EndpointUnavailableError
+	if stderrors.As(err, &unavailable) {
+		meta.SetStatusCondition(&bssQuery.Status.Conditions, metav1.Condition{
+			Type:               TypeEndpointUnavailable,
+			Status:             metav1.ConditionTrue,
+			Reason:             ReasonCircuitOpen,
+			LastTransitionTime: metav1.Now(),
+			Message:            unavailable.Error(),
+		})
+		return
+	}
+	meta.SetStatusCondition(&bssQuery.Status.Conditions, metav1.Condition{
+		Type:               TypeEndpointUnavailable,
+		Status:             metav1.ConditionFalse,
+		Reason:             ReasonCircuitClosed,
+		LastTransitionTime: metav1.Now(),
+		Message:            "Requests are sent to the endpoint",
+	})
+}
+
+// queryErrorReason returns the reason of the Degraded condition of a failed
+// request to the BSS API, and whether retrying it cannot succeed until the
+// BSSQuery or its credentials change
+func queryErrorReason(err error) (string, bool) {
+	var (
+		statusErr  *bssclient.HTTPStatusError
+		graphQLErr *bssclient.GraphQLErrors
+	)
+	var unavailable *bssclient.EndpointUnavailableError
+	switch {
+	case stderrors.As(err, &unavailable):
+		return ReasonEndpointUnavailable, false
+	case stderrors.Is(err, bssclient.ErrNotFound):
+		// The cluster may be created later
+		return ReasonClusterNotFound, false
+	case stderrors.As(err, &statusErr) && statusErr.Unauthorized():
+		return ReasonUnauthorized, true
+	case stderrors.As(err, &graphQLErr),
+		stderrors.As(err, &statusErr) && !statusErr.Transient():
+		return ReasonQueryRejected, true
+	}
+	return ReasonQueryFailed, false
+}
+
+// queryPauseReason returns the reason and message of the Paused condition of
+// a paused BSSQuery, or an empty reason if it is not paused
+func queryPauseReason(bssQuery *bssv1alpha1.BSSQuery) (string, string) {
+	switch {
+	case bssQuery.Spec.Paused:
+		return ReasonSpecPaused, "spec.paused is set, the query is not polled"
+	case bssQuery.Annotations[bssv1alpha1.AnnotationPaused] == "true":
+		return ReasonAnnotationPaused, fmt.Sprintf("The %s annotation is set, the query is not polled",
+			bssv1alpha1.AnnotationPaused)
+	}
+	return "", ""
 }
 
 // validateQuery validates the BSSQuery configuration
 func (r *BSSQueryReconciler) validateQuery(bssQuery *bssv1alpha1.BSSQuery) error {
-	if bssQuery.Spec.APIEndpoint == "" {
-		return fmt.Errorf("APIEndpoint is required")
+	return validation.NewValidator().ValidateQuery(bssQuery)
+}
+
+// resolveEndpoint returns the GraphQL endpoint of a BSSQuery. For a query
+// referencing a BssCluster it also returns why the cluster cannot be queried
+// yet, if it cannot.
+func (r *BSSQueryReconciler) resolveEndpoint(ctx context.Context, bssQuery *bssv1alpha1.BSSQuery) (string, string, error) {
+	if bssQuery.Spec.ClusterRef == nil {
+		return bssQuery.Spec.APIEndpoint, "", nil
 	}
 
-	if bssQuery.Spec.Query == bssv1alpha1.QueryTypeCluster && bssQuery.Spec.ClusterID == "" {
-		return fmt.Errorf("ClusterID is required for cluster query type")
+	key := clusterRefKey(bssQuery)
+	bssCluster := &bssv1alpha1.BssCluster{}
+	if err := r.Get(ctx, key, bssCluster); err != nil {
+		if errors.IsNotFound(err) {
+			return "", fmt.Sprintf("BssCluster %s not found", key), nil
+		}
+		return "", "", err
 	}
 
-	return nil
+	endpoint := builder.ServiceURL(bssCluster)
+	if !bssCluster.DeletionTimestamp.IsZero() {
+		return endpoint, fmt.Sprintf("BssCluster %s is being deleted", key), nil
+	}
+	if !meta.IsStatusConditionTrue(bssCluster.Status.Conditions, TypeAvailable) {
+		return endpoint, fmt.Sprintf("BssCluster %s is not available", key), nil
+	}
+	return endpoint, "", nil
 }
 
-// executeQuery executes the GraphQL query and updates the status
-func (r *BSSQueryReconciler) executeQuery(ctx context.Context, bssQuery *bssv1alpha1.BSSQuery) error {
-	logger := log.FromContext(ctx)
+// clusterRefKey returns the key of the BssCluster referenced by a BSSQuery,
+// which defaults to the namespace of the query
+func clusterRefKey(bssQuery *bssv1alpha1.BSSQuery) types.NamespacedName {
+	key := types.NamespacedName{Name: bssQuery.Spec.ClusterRef.Name, Namespace: bssQuery.Spec.ClusterRef.Namespace}
+	if key.Namespace == "" {
+		key.Namespace = bssQuery.Namespace
+	}
+	return key
+}
 
-	// Create GraphQL client
-	gqlClient := bssclient.NewGraphQLClient(bssQuery.Spec.APIEndpoint)
+// executeQuery executes the GraphQL query with the given client and returns
+// its JSON result. request is the prepared document of graphql queries.
+func (r *BSSQueryReconciler) executeQuery(ctx context.Context, bssQuery *bssv1alpha1.BSSQuery, gqlClient *bssclient.GraphQLClient, request *bssclient.GraphQLRequest) ([]byte, error) {
+	logger := log.FromContext(ctx)
 
 	switch bssQuery.Spec.Query {
 	case bssv1alpha1.QueryTypeCluster:
-		cluster, err := gqlClient.GetCluster(bssQuery.Spec.ClusterID)
+		cluster, err := gqlClient.GetCluster(ctx, bssQuery.Spec.ClusterID)
 		if err != nil {
-			return fmt.Errorf("failed to get cluster: %w", err)
-		}
-
-		if cluster == nil {
-			return fmt.Errorf("cluster not found: %s", bssQuery.Spec.ClusterID)
+			return nil, fmt.Errorf("failed to get cluster: %w", err)
 		}
 
 		resultJSON, err := json.Marshal(cluster)
 		if err != nil {
-			return fmt.Errorf("failed to marshal result: %w", err)
+			return nil, fmt.Errorf("failed to marshal result: %w", err)
 		}
 
-		bssQuery.Status.Result = string(resultJSON)
 		bssQuery.Status.ClusterCount = 1
 		logger.Info("Retrieved cluster", "id", cluster.ID, "name", cluster.Name, "state", cluster.State)
+		return resultJSON, nil
 
 	case bssv1alpha1.QueryTypeClusters:
-		clusters, err := gqlClient.ListClusters()
+		clusters, err := gqlClient.ListClusters(ctx)
 		if err != nil {
-			return fmt.Errorf("failed to list clusters: %w", err)
+			return nil, fmt.Errorf("failed to list clusters: %w", err)
 		}
 
 		resultJSON, err := json.Marshal(clusters)
 		if err != nil {
-			return fmt.Errorf("failed to marshal result: %w", err)
+			return nil, fmt.Errorf("failed to marshal result: %w", err)
 		}
 
-		bssQuery.Status.Result = string(resultJSON)
 		bssQuery.Status.ClusterCount = len(clusters)
 		logger.Info("Retrieved clusters", "count", len(clusters))
+		return resultJSON, nil
+
+	case bssv1alpha1.QueryTypeGraphQL:
+		resp, err := gqlClient.ExecuteRequest(ctx, request)
+		if err != nil {
+			return nil, fmt.Errorf("failed to run document: %w", err)
+		}
+
+		bssQuery.Status.ClusterCount = 0
+		logger.Info("Ran GraphQL document", "operation", request.OperationName, "bytes", len(resp.Data))
+		return resp.Data, nil
 
 	default:
-		return fmt.Errorf("unknown query type: %s", bssQuery.Spec.Query)
+		return nil, fmt.Errorf("unknown query type: %s", bssQuery.Spec.Query)
 	}
-
-	return nil
 }
 
 // SetupWithManager sets up the controller with the Manager.
+// BssClusters are watched so that queries referencing one are resolved
+// again when it becomes available or its Service moves, and ConfigMaps and
+// Secrets so that changes to GraphQL documents, credentials and
+// certificates are picked up.
 func (r *BSSQueryReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	if r.Clients == nil {
+		r.Clients = bssclient.NewRegistry(bssclient.DefaultIdleTimeout, bssclient.DefaultEndpointLimits)
+	}
+	if err := mgr.Add(r.Clients); err != nil {
+		return err
+	}
+
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &bssv1alpha1.BSSQuery{}, clusterRefIndex,
+		func(obj client.Object) []string {
+			bssQuery := obj.(*bssv1alpha1.BSSQuery)
+			if bssQuery.Spec.ClusterRef == nil {
+				return nil
+			}
+			return []string{clusterRefKey(bssQuery).String()}
+		}); err != nil {
+		return err
+	}
+
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &bssv1alpha1.BSSQuery{}, configMapRefIndex,
+		func(obj client.Object) []string {
+			return referencedConfigMaps(obj.(*bssv1alpha1.BSSQuery))
+		}); err != nil {
+		return err
+	}
+
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &bssv1alpha1.BSSQuery{}, secretRefIndex,
+		func(obj client.Object) []string {
+			return referencedSecrets(obj.(*bssv1alpha1.BSSQuery))
+		}); err != nil {
+		return err
+	}
+
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&bssv1alpha1.BSSQuery{}).
+		Watches(&bssv1alpha1.BssCluster{},
+			handler.EnqueueRequestsFromMapFunc(r.queriesForCluster),
+			ctrlbuilder.WithPredicates(clusterEndpointPredicate())).
+		Watches(&corev1.ConfigMap{},
+			handler.EnqueueRequestsFromMapFunc(r.queriesForConfigMap)).
+		Watches(&corev1.Secret{},
+			handler.EnqueueRequestsFromMapFunc(r.queriesForSecret)).
 		Complete(r)
 }
+
+// queriesForCluster returns a request for every BSSQuery referencing a BssCluster
+func (r *BSSQueryReconciler) queriesForCluster(ctx context.Context, obj client.Object) []reconcile.Request {
+	var bssQueries bssv1alpha1.BSSQueryList
+	if err := r.List(ctx, &bssQueries,
+		client.MatchingFields{clusterRefIndex: client.ObjectKeyFromObject(obj).String()}); err != nil {
+		log.FromContext(ctx).Error(err, "Failed to list BSSQueries referencing BssCluster", "bssCluster", obj.GetName())
+		return nil
+	}
+
+	requests := make([]reconcile.Request, 0, len(bssQueries.Items))
+	for i := range bssQueries.Items {
+		requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&bssQueries.Items[i])})
+	}
+	return requests
+}
@@ -18,14 +18,23 @@ package controller
 
 import (
 	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
 	"time"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	bssv1alpha1 "github.com/brmorris/bss-operator/api/v1alpha1"
+	bssclient "github.com/brmorris/bss-operator/internal/client"
 )
 
 var _ = Describe("BSSQuery Controller", func() {
@@ -135,5 +144,384 @@ var _ = Describe("BSSQuery Controller", func() {
 			// Clean up
 			Expect(k8sClient.Delete(ctx, bssQuery)).Should(Succeed())
 		})
+
+		It("should wait for a referenced BssCluster to become available", func() {
+			bssQuery := &bssv1alpha1.BSSQuery{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-cluster-ref",
+					Namespace: "default",
+				},
+				Spec: bssv1alpha1.BSSQuerySpec{
+					ClusterRef: &bssv1alpha1.BssClusterReference{Name: "test-query-target"},
+					Query:      bssv1alpha1.QueryTypeClusters,
+				},
+			}
+			key := types.NamespacedName{Name: bssQuery.Name, Namespace: bssQuery.Namespace}
+			degradedReason := func() string {
+				if err := k8sClient.Get(ctx, key, bssQuery); err != nil {
+					return ""
+				}
+				degraded := meta.FindStatusCondition(bssQuery.Status.Conditions, TypeDegraded)
+				if degraded == nil {
+					return ""
+				}
+				return degraded.Reason
+			}
+
+			Expect(k8sClient.Create(ctx, bssQuery)).Should(Succeed())
+			Eventually(degradedReason, timeout, interval).Should(Equal(ReasonClusterNotReady))
+
+			// No BssCluster controller runs here, so availability is reported by hand
+			bssCluster := &bssv1alpha1.BssCluster{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-query-target", Namespace: "default"},
+				Spec:       bssv1alpha1.BssClusterSpec{Name: "test-query-target", Version: "1.0.0"},
+			}
+			Expect(k8sClient.Create(ctx, bssCluster)).Should(Succeed())
+			Eventually(func() string {
+				Expect(k8sClient.Get(ctx, key, bssQuery)).To(Succeed())
+				return bssQuery.Status.Endpoint
+			}, timeout, interval).Should(Equal("http://test-query-target.default.svc:80/graphql"))
+			Expect(degradedReason()).To(Equal(ReasonClusterNotReady))
+
+			meta.SetStatusCondition(&bssCluster.Status.Conditions, metav1.Condition{
+				Type:   TypeAvailable,
+				Status: metav1.ConditionTrue,
+				Reason: ReasonMinimumReplicasAvailable,
+			})
+			Expect(k8sClient.Status().Update(ctx, bssCluster)).Should(Succeed())
+			Eventually(degradedReason, timeout, interval).Should(Equal(ReasonQueryFailed))
+
+			// Clean up
+			Expect(k8sClient.Delete(ctx, bssQuery)).Should(Succeed())
+			Expect(k8sClient.Delete(ctx, bssCluster)).Should(Succeed())
+		})
+
+		It("should read a GraphQL document from a ConfigMap once it exists", func() {
+			bssQuery := &bssv1alpha1.BSSQuery{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-graphql-document",
+					Namespace: "default",
+				},
+				Spec: bssv1alpha1.BSSQuerySpec{
+					APIEndpoint: "http://localhost:8880/graphql",
+					Query:       bssv1alpha1.QueryTypeGraphQL,
+					GraphQL: &bssv1alpha1.GraphQLQuerySpec{
+						DocumentFrom: &corev1.ConfigMapKeySelector{
+							LocalObjectReference: corev1.LocalObjectReference{Name: "test-graphql-document"},
+							Key:                  "query.graphql",
+						},
+					},
+				},
+			}
+			key := types.NamespacedName{Name: bssQuery.Name, Namespace: bssQuery.Namespace}
+			degradedMessage := func() string {
+				if err := k8sClient.Get(ctx, key, bssQuery); err != nil {
+					return ""
+				}
+				degraded := meta.FindStatusCondition(bssQuery.Status.Conditions, TypeDegraded)
+				if degraded == nil {
+					return ""
+				}
+				return degraded.Reason + ": " + degraded.Message
+			}
+
+			Expect(k8sClient.Create(ctx, bssQuery)).Should(Succeed())
+			Eventually(degradedMessage, timeout, interval).Should(
+				Equal(ReasonInvalidConfig + ": ConfigMap test-graphql-document of the GraphQL document not found"))
+
+			// No bss-api runs here, so the document is read but cannot be validated
+			configMap := &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-graphql-document", Namespace: "default"},
+				Data:       map[string]string{"query.graphql": "{ clusters { id name } }"},
+			}
+			Expect(k8sClient.Create(ctx, configMap)).Should(Succeed())
+			Eventually(degradedMessage, timeout, interval).Should(
+				HavePrefix(ReasonQueryFailed + ": failed to introspect schema"))
+
+			// Clean up
+			Expect(k8sClient.Delete(ctx, bssQuery)).Should(Succeed())
+			Expect(k8sClient.Delete(ctx, configMap)).Should(Succeed())
+		})
+
+		It("should write the fields of spec.output to an owned ConfigMap or Secret", func() {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				_, _ = fmt.Fprint(w, `{"data":{"clusters":[`+
+					`{"id":"a","name":"alpha","replicas":3},{"id":"b","name":"beta","replicas":1}]}}`)
+			}))
+			defer server.Close()
+
+			bssQuery := &bssv1alpha1.BSSQuery{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-query-output",
+					Namespace: "default",
+				},
+				Spec: bssv1alpha1.BSSQuerySpec{
+					APIEndpoint: server.URL,
+					Query:       bssv1alpha1.QueryTypeClusters,
+					Output: &bssv1alpha1.QueryOutputSpec{
+						Fields: []bssv1alpha1.QueryOutputField{
+							{Name: "first", JSONPath: "{[0].name}"},
+							{Name: "scaled", Expression: "result.filter(c, c.replicas > 1.0).size()"},
+						},
+						ConfigMap: &bssv1alpha1.QueryOutputObject{},
+					},
+				},
+			}
+			key := types.NamespacedName{Name: bssQuery.Name, Namespace: bssQuery.Namespace}
+
+			Expect(k8sClient.Create(ctx, bssQuery)).Should(Succeed())
+
+			configMap := &corev1.ConfigMap{}
+			Eventually(func() map[string]string {
+				if err := k8sClient.Get(ctx, key, configMap); err != nil {
+					return nil
+				}
+				return configMap.Data
+			}, timeout, interval).Should(Equal(map[string]string{"first": "alpha", "scaled": "1"}))
+			Expect(metav1.IsControlledBy(configMap, bssQuery)).To(BeTrue())
+
+			// Only the digest and size of the result are kept in status
+			Eventually(func() string {
+				Expect(k8sClient.Get(ctx, key, bssQuery)).To(Succeed())
+				return bssQuery.Status.ResultDigest
+			}, timeout, interval).Should(HavePrefix("sha256:"))
+			Expect(bssQuery.Status.Result).To(BeEmpty())
+			Expect(bssQuery.Status.ResultSize).To(BeNumerically(">", 0))
+
+			// Moving the output to a Secret deletes the ConfigMap
+			bssQuery.Spec.Output.ConfigMap = nil
+			bssQuery.Spec.Output.Secret = &bssv1alpha1.QueryOutputObject{Name: "test-query-output-secret"}
+			Expect(k8sClient.Update(ctx, bssQuery)).Should(Succeed())
+
+			secret := &corev1.Secret{}
+			Eventually(func() map[string][]byte {
+				if err := k8sClient.Get(ctx, types.NamespacedName{
+					Name: "test-query-output-secret", Namespace: "default"}, secret); err != nil {
+					return nil
+				}
+				return secret.Data
+			}, timeout, interval).Should(HaveKeyWithValue("first", []byte("alpha")))
+			Eventually(func() bool {
+				return errors.IsNotFound(k8sClient.Get(ctx, key, &corev1.ConfigMap{}))
+			}, timeout, interval).Should(BeTrue())
+
+			// Clean up
+			Expect(k8sClient.Delete(ctx, bssQuery)).Should(Succeed())
+		})
+
+		It("should not take annotations of its target from another field manager", func() {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				_, _ = fmt.Fprint(w, `{"data":{"clusters":[{"id":"a","name":"alpha"}]}}`)
+			}))
+			defer server.Close()
+
+			annotationKey := bssv1alpha1.DefaultQueryOutputAnnotationPrefix + "first"
+			target := &corev1.ConfigMap{
+				TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "ConfigMap"},
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "test-query-annotated",
+					Namespace:   "default",
+					Annotations: map[string]string{annotationKey: "manual"},
+				},
+			}
+			Expect(k8sClient.Patch(ctx, target, client.Apply, client.FieldOwner("someone-else"))).Should(Succeed())
+
+			bssQuery := &bssv1alpha1.BSSQuery{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-query-annotations",
+					Namespace: "default",
+				},
+				Spec: bssv1alpha1.BSSQuerySpec{
+					APIEndpoint: server.URL,
+					Query:       bssv1alpha1.QueryTypeClusters,
+					Output: &bssv1alpha1.QueryOutputSpec{
+						Fields: []bssv1alpha1.QueryOutputField{{Name: "first", JSONPath: "{[0].name}"}},
+						Annotations: &bssv1alpha1.QueryOutputAnnotations{
+							TargetRef: bssv1alpha1.QueryOutputTargetReference{
+								APIVersion: "v1",
+								Kind:       "ConfigMap",
+								Name:       target.Name,
+							},
+						},
+					},
+				},
+			}
+			key := types.NamespacedName{Name: bssQuery.Name, Namespace: bssQuery.Namespace}
+			Expect(k8sClient.Create(ctx, bssQuery)).Should(Succeed())
+			Expect(bssQuery.Spec.Output.Annotations.Prefix).To(Equal(bssv1alpha1.DefaultQueryOutputAnnotationPrefix))
+
+			// The conflict fails the output instead of overwriting the annotation
+			Eventually(func() string {
+				if err := k8sClient.Get(ctx, key, bssQuery); err != nil {
+					return ""
+				}
+				degraded := meta.FindStatusCondition(bssQuery.Status.Conditions, TypeDegraded)
+				if degraded == nil {
+					return ""
+				}
+				return degraded.Reason
+			}, timeout, interval).Should(Equal(ReasonOutputFailed))
+			Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(target), target)).Should(Succeed())
+			Expect(target.Annotations).To(HaveKeyWithValue(annotationKey, "manual"))
+
+			// Clean up
+			Expect(k8sClient.Delete(ctx, bssQuery)).Should(Succeed())
+			Expect(k8sClient.Delete(ctx, target)).Should(Succeed())
+		})
+
+		It("should reload a bearer token when its Secret changes", func() {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Header.Get("Authorization") != "Bearer rotated" {
+					w.WriteHeader(http.StatusUnauthorized)
+					return
+				}
+				_, _ = fmt.Fprint(w, `{"data":{"clusters":[]}}`)
+			}))
+			defer server.Close()
+
+			secret := &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-query-token", Namespace: "default"},
+				Data:       map[string][]byte{"token": []byte("initial")},
+			}
+			Expect(k8sClient.Create(ctx, secret)).Should(Succeed())
+
+			bssQuery := &bssv1alpha1.BSSQuery{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-query-auth",
+					Namespace: "default",
+				},
+				Spec: bssv1alpha1.BSSQuerySpec{
+					APIEndpoint: server.URL,
+					Query:       bssv1alpha1.QueryTypeClusters,
+					Auth: &bssv1alpha1.QueryAuthSpec{
+						BearerTokenSecretRef: &corev1.SecretKeySelector{
+							LocalObjectReference: corev1.LocalObjectReference{Name: "test-query-token"},
+							Key:                  "token",
+						},
+					},
+					// Only the Secret watch can pick up the new token in time
+					RefreshInterval: 3600,
+				},
+			}
+			key := types.NamespacedName{Name: bssQuery.Name, Namespace: bssQuery.Namespace}
+			degradedReason := func() string {
+				if err := k8sClient.Get(ctx, key, bssQuery); err != nil {
+					return ""
+				}
+				degraded := meta.FindStatusCondition(bssQuery.Status.Conditions, TypeDegraded)
+				if degraded == nil {
+					return ""
+				}
+				return degraded.Reason
+			}
+
+			// Rejected credentials are not retried until the Secret changes
+			Expect(k8sClient.Create(ctx, bssQuery)).Should(Succeed())
+			Eventually(degradedReason, timeout, interval).Should(Equal(ReasonUnauthorized))
+
+			secret.Data["token"] = []byte("rotated")
+			Expect(k8sClient.Update(ctx, secret)).Should(Succeed())
+			Eventually(degradedReason, timeout, interval).Should(Equal(ReasonQuerySuccess))
+
+			// Clean up
+			Expect(k8sClient.Delete(ctx, bssQuery)).Should(Succeed())
+			Expect(k8sClient.Delete(ctx, secret)).Should(Succeed())
+		})
+
+		It("should report an endpoint failing repeatedly as unavailable", func() {
+			var requests atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				requests.Add(1)
+				w.WriteHeader(http.StatusServiceUnavailable)
+			}))
+			defer server.Close()
+
+			bssQuery := &bssv1alpha1.BSSQuery{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-query-unavailable",
+					Namespace: "default",
+				},
+				Spec: bssv1alpha1.BSSQuerySpec{
+					APIEndpoint:     server.URL,
+					Query:           bssv1alpha1.QueryTypeClusters,
+					RefreshInterval: 3600,
+				},
+			}
+			key := types.NamespacedName{Name: bssQuery.Name, Namespace: bssQuery.Namespace}
+			condition := func(conditionType string) func() string {
+				return func() string {
+					if err := k8sClient.Get(ctx, key, bssQuery); err != nil {
+						return ""
+					}
+					found := meta.FindStatusCondition(bssQuery.Status.Conditions, conditionType)
+					if found == nil {
+						return ""
+					}
+					return found.Reason
+				}
+			}
+
+			Expect(k8sClient.Create(ctx, bssQuery)).Should(Succeed())
+			Eventually(condition(TypeDegraded), timeout, interval).ShouldNot(BeEmpty())
+
+			// Every attempt counts, so the breaker opens by the next poll
+			patch := client.MergeFrom(bssQuery.DeepCopy())
+			bssQuery.Annotations = map[string]string{"test": "poll"}
+			Expect(k8sClient.Patch(ctx, bssQuery, patch)).Should(Succeed())
+			Eventually(condition(TypeEndpointUnavailable), timeout, interval).Should(Equal(ReasonCircuitOpen))
+			Expect(condition(TypeDegraded)()).To(Equal(ReasonEndpointUnavailable))
+			Expect(requests.Load()).To(BeEquivalentTo(bssclient.DefaultEndpointLimits.FailureThreshold))
+
+			// Clean up
+			Expect(k8sClient.Delete(ctx, bssQuery)).Should(Succeed())
+		})
+
+		It("should not poll a paused query", func() {
+			bssQuery := &bssv1alpha1.BSSQuery{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-paused-query",
+					Namespace: "default",
+				},
+				Spec: bssv1alpha1.BSSQuerySpec{
+					APIEndpoint: "http://localhost:8880/graphql",
+					Query:       bssv1alpha1.QueryTypeClusters,
+					Paused:      true,
+					Output: &bssv1alpha1.QueryOutputSpec{
+						Fields: []bssv1alpha1.QueryOutputField{{Name: "count", Expression: "size(result.clusters)"}},
+						Annotations: &bssv1alpha1.QueryOutputAnnotations{
+							TargetRef: bssv1alpha1.QueryOutputTargetReference{
+								APIVersion: "v1",
+								Kind:       "ConfigMap",
+								Name:       "test-paused-query-target",
+							},
+							Prefix: bssv1alpha1.DefaultQueryOutputAnnotationPrefix,
+						},
+					},
+				},
+			}
+
+			Expect(k8sClient.Create(ctx, bssQuery)).Should(Succeed())
+
+			// Wait for the pause to be reported
+			Eventually(func() bool {
+				err := k8sClient.Get(ctx, types.NamespacedName{
+					Name:      bssQuery.Name,
+					Namespace: bssQuery.Namespace,
+				}, bssQuery)
+				if err != nil {
+					return false
+				}
+				return meta.IsStatusConditionTrue(bssQuery.Status.Conditions, TypePaused)
+			}, timeout, interval).Should(BeTrue())
+
+			Expect(bssQuery.Status.LastQueryTime).To(BeNil())
+			Expect(meta.FindStatusCondition(bssQuery.Status.Conditions, TypeDegraded)).To(BeNil())
+			// A paused query is not changed, not even to add its finalizer
+			Expect(bssQuery.Finalizers).NotTo(ContainElement(QueryOutputFinalizer))
+
+			// Clean up
+			Expect(k8sClient.Delete(ctx, bssQuery)).Should(Succeed())
+		})
 	})
 })
@@ -0,0 +1,146 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	bssv1alpha1 "github.com/brmorris/bss-operator/api/v1alpha1"
+	bssclient "github.com/brmorris/bss-operator/internal/client"
+	"github.com/brmorris/bss-operator/internal/validation"
+)
+
+// configMapRefIndex indexes BSSQueries by the names of the ConfigMaps their
+// GraphQL document or CA bundle is read from
+const configMapRefIndex = "spec.configMapRefs"
+
+// prepareDocument reads and parses the GraphQL document of a BSSQuery and
+// returns the request running it. The document is validated against the
+// schema of the endpoint, through introspection, whenever it, its variables
+// or the endpoint change. On failure it also returns the reason of the
+// Degraded condition.
+func (r *BSSQueryReconciler) prepareDocument(ctx context.Context, bssQuery *bssv1alpha1.BSSQuery, gqlClient *bssclient.GraphQLClient, endpoint string) (*bssclient.GraphQLRequest, string, error) {
+	graphQL := bssQuery.Spec.GraphQL
+	source, err := r.loadDocument(ctx, bssQuery)
+	if err != nil {
+		return nil, ReasonInvalidConfig, err
+	}
+	document, err := bssclient.ParseDocument(source, graphQL.OperationName)
+	if err != nil {
+		return nil, ReasonInvalidConfig, fmt.Errorf("invalid GraphQL document: %w", err)
+	}
+	variables, err := validation.GraphQLVariables(graphQL)
+	if err != nil {
+		return nil, ReasonInvalidConfig, fmt.Errorf("invalid GraphQL variables: %w", err)
+	}
+	request := document.Request(variables)
+
+	key := client.ObjectKeyFromObject(bssQuery)
+	digest := documentDigest(endpoint, source, graphQL)
+	if validated, ok := r.validated.Load(key); ok && validated == digest {
+		return request, "", nil
+	}
+
+	schema, err := gqlClient.Introspect(ctx)
+	if err != nil {
+		reason, _ := queryErrorReason(err)
+		return nil, reason, err
+	}
+	if err := schema.Validate(document, variables); err != nil {
+		r.validated.Delete(key)
+		return nil, ReasonQueryInvalid, fmt.Errorf("GraphQL document does not match the schema of %s: %w", endpoint, err)
+	}
+	log.FromContext(ctx).Info("Validated GraphQL document against the schema", "endpoint", endpoint)
+	r.validated.Store(key, digest)
+	return request, "", nil
+}
+
+// loadDocument returns the GraphQL document of a BSSQuery, reading it from
+// its ConfigMap if it is not inline
+func (r *BSSQueryReconciler) loadDocument(ctx context.Context, bssQuery *bssv1alpha1.BSSQuery) (string, error) {
+	graphQL := bssQuery.Spec.GraphQL
+	if graphQL.DocumentFrom == nil {
+		return graphQL.Document, nil
+	}
+
+	selector := graphQL.DocumentFrom
+	configMap := &corev1.ConfigMap{}
+	if err := r.Get(ctx, types.NamespacedName{Name: selector.Name, Namespace: bssQuery.Namespace}, configMap); err != nil {
+		if errors.IsNotFound(err) {
+			return "", fmt.Errorf("ConfigMap %s of the GraphQL document not found", selector.Name)
+		}
+		return "", err
+	}
+	document, ok := configMap.Data[selector.Key]
+	if !ok {
+		return "", fmt.Errorf("ConfigMap %s has no key %s", selector.Name, selector.Key)
+	}
+	return document, nil
+}
+
+// documentDigest identifies a GraphQL document, its operation and variables
+// as validated against an endpoint
+func documentDigest(endpoint, source string, graphQL *bssv1alpha1.GraphQLQuerySpec) string {
+	hash := sha256.New()
+	for _, part := range []string{endpoint, source, graphQL.OperationName} {
+		hash.Write([]byte(part))
+		hash.Write([]byte{0})
+	}
+	if graphQL.Variables != nil {
+		hash.Write(graphQL.Variables.Raw)
+	}
+	return fmt.Sprintf("%x", hash.Sum(nil))
+}
+
+// referencedConfigMaps returns the names of the ConfigMaps a BSSQuery reads
+func referencedConfigMaps(bssQuery *bssv1alpha1.BSSQuery) []string {
+	var names []string
+	if graphQL := bssQuery.Spec.GraphQL; graphQL != nil && graphQL.DocumentFrom != nil {
+		names = append(names, graphQL.DocumentFrom.Name)
+	}
+	if auth := bssQuery.Spec.Auth; auth != nil && auth.TLS != nil && auth.TLS.CA != nil &&
+		auth.TLS.CA.ConfigMapKeyRef != nil {
+		names = append(names, auth.TLS.CA.ConfigMapKeyRef.Name)
+	}
+	return names
+}
+
+// queriesForConfigMap returns a request for every BSSQuery reading its
+// GraphQL document or CA bundle from a ConfigMap
+func (r *BSSQueryReconciler) queriesForConfigMap(ctx context.Context, obj client.Object) []reconcile.Request {
+	var bssQueries bssv1alpha1.BSSQueryList
+	if err := r.List(ctx, &bssQueries, client.InNamespace(obj.GetNamespace()),
+		client.MatchingFields{configMapRefIndex: obj.GetName()}); err != nil {
+		log.FromContext(ctx).Error(err, "Failed to list BSSQueries reading ConfigMap", "configMap", obj.GetName())
+		return nil
+	}
+
+	requests := make([]reconcile.Request, 0, len(bssQueries.Items))
+	for i := range bssQueries.Items {
+		requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&bssQueries.Items[i])})
+	}
+	return requests
+}
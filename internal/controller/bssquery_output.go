@@ -0,0 +1,252 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	bssv1alpha1 "github.com/brmorris/bss-operator/api/v1alpha1"
+	"github.com/brmorris/bss-operator/internal/builder"
+	"github.com/brmorris/bss-operator/internal/output"
+	"github.com/brmorris/bss-operator/internal/resources"
+)
+
+const (
+	// QueryOutputFinalizer removes the annotations a BSSQuery wrote on its
+	// target when the query is deleted
+	QueryOutputFinalizer = "bss.localhost/query-output"
+
+	// ReasonOutputFailed is the reason of the Degraded condition when the
+	// fields of spec.output cannot be extracted or written
+	ReasonOutputFailed = "OutputFailed"
+
+	// maxFieldManagerLength is the longest field manager the API server accepts
+	maxFieldManagerLength = 128
+)
+
+// recordResult keeps the digest and size of a result in status, and the
+// result itself unless it is projected through spec.output
+func recordResult(bssQuery *bssv1alpha1.BSSQuery, result []byte) {
+	sum := sha256.Sum256(result)
+	bssQuery.Status.ResultDigest = "sha256:" + hex.EncodeToString(sum[:])
+	bssQuery.Status.ResultSize = len(result)
+	bssQuery.Status.Result = ""
+	if bssQuery.Spec.Output == nil {
+		bssQuery.Status.Result = string(result)
+	}
+}
+
+// writeOutput extracts the fields of spec.output from a result and writes
+// them to the ConfigMap, Secret or target object of the BSSQuery
+func (r *BSSQueryReconciler) writeOutput(ctx context.Context, bssQuery *bssv1alpha1.BSSQuery, result []byte) error {
+	spec := bssQuery.Spec.Output
+	extractor, err := output.NewExtractor(spec.Fields)
+	if err != nil {
+		return err
+	}
+	values, err := extractor.Extract(result)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case spec.ConfigMap != nil:
+		return r.applyOutputObject(ctx, bssQuery, builder.NewQueryOutputBuilder(bssQuery, values).BuildConfigMap())
+	case spec.Secret != nil:
+		return r.applyOutputObject(ctx, bssQuery, builder.NewQueryOutputBuilder(bssQuery, values).BuildSecret())
+	case spec.Annotations != nil:
+		return r.annotateTarget(ctx, bssQuery, builder.QueryOutputAnnotations(bssQuery, values))
+	}
+	return nil
+}
+
+// applyOutputObject server-side applies a ConfigMap or Secret owned by the
+// BSSQuery. An object of the same name that the query does not own is left
+// alone.
+func (r *BSSQueryReconciler) applyOutputObject(ctx context.Context, bssQuery *bssv1alpha1.BSSQuery, desired client.Object) error {
+	gvk, err := apiutil.GVKForObject(desired, r.Scheme)
+	if err != nil {
+		return err
+	}
+	// Apply requests must carry the type of the object
+	desired.GetObjectKind().SetGroupVersionKind(gvk)
+	if err := controllerutil.SetControllerReference(bssQuery, desired, r.Scheme); err != nil {
+		return err
+	}
+
+	newObject, err := r.Scheme.New(gvk)
+	if err != nil {
+		return err
+	}
+	existing, ok := newObject.(client.Object)
+	if !ok {
+		return fmt.Errorf("%s is not a client.Object", gvk.Kind)
+	}
+	if err := r.Get(ctx, client.ObjectKeyFromObject(desired), existing); err == nil {
+		if !metav1.IsControlledBy(existing, bssQuery) {
+			return fmt.Errorf("%s %s already exists and is not owned by the BSSQuery", gvk.Kind, desired.GetName())
+		}
+	} else if !errors.IsNotFound(err) {
+		return err
+	}
+
+	return r.Patch(ctx, desired, client.Apply, client.FieldOwner(resources.FieldOwner), client.ForceOwnership)
+}
+
+// annotateTarget server-side applies the annotations of a BSSQuery on its
+// target. Every query applies them as its own field manager, so annotations
+// of fields it no longer has are removed without touching the others.
+// Ownership is not forced: annotations set by another field manager are
+// left alone and fail the apply.
+func (r *BSSQueryReconciler) annotateTarget(ctx context.Context, bssQuery *bssv1alpha1.BSSQuery, annotations map[string]string) error {
+	// Applying would create a missing target
+	target := outputTarget(bssQuery)
+	if err := r.Get(ctx, client.ObjectKeyFromObject(target), target); err != nil {
+		if errors.IsNotFound(err) {
+			return fmt.Errorf("%s %s to annotate not found", target.GetKind(), target.GetName())
+		}
+		return err
+	}
+
+	patch := outputTarget(bssQuery)
+	patch.SetAnnotations(annotations)
+	return r.Patch(ctx, patch, client.Apply, client.FieldOwner(queryFieldOwner(bssQuery)))
+}
+
+// removeAnnotations removes the annotations a BSSQuery applied on its target
+func (r *BSSQueryReconciler) removeAnnotations(ctx context.Context, bssQuery *bssv1alpha1.BSSQuery) error {
+	target := outputTarget(bssQuery)
+	if err := r.Get(ctx, client.ObjectKeyFromObject(target), target); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	return r.Patch(ctx, outputTarget(bssQuery), client.Apply, client.FieldOwner(queryFieldOwner(bssQuery)))
+}
+
+// finalizeOutput removes the annotations of a BSSQuery that is being
+// deleted, then its finalizer
+func (r *BSSQueryReconciler) finalizeOutput(ctx context.Context, bssQuery *bssv1alpha1.BSSQuery) error {
+	if !controllerutil.ContainsFinalizer(bssQuery, QueryOutputFinalizer) {
+		return nil
+	}
+	if bssQuery.Spec.Output != nil && bssQuery.Spec.Output.Annotations != nil {
+		if err := r.removeAnnotations(ctx, bssQuery); err != nil {
+			return err
+		}
+	}
+	log.FromContext(ctx).Info("Removing finalizer", "finalizer", QueryOutputFinalizer)
+	controllerutil.RemoveFinalizer(bssQuery, QueryOutputFinalizer)
+	return r.Update(ctx, bssQuery)
+}
+
+// reconcileOutputFinalizer adds the finalizer to a BSSQuery annotating a
+// target, and removes it once the query no longer does. Annotations left on
+// a previous target are not removed.
+func (r *BSSQueryReconciler) reconcileOutputFinalizer(ctx context.Context, bssQuery *bssv1alpha1.BSSQuery) error {
+	annotates := bssQuery.Spec.Output != nil && bssQuery.Spec.Output.Annotations != nil
+	switch {
+	case annotates && controllerutil.AddFinalizer(bssQuery, QueryOutputFinalizer):
+	case !annotates && controllerutil.RemoveFinalizer(bssQuery, QueryOutputFinalizer):
+	default:
+		return nil
+	}
+	return r.Update(ctx, bssQuery)
+}
+
+// pruneOutputs deletes the ConfigMaps and Secrets a BSSQuery wrote before
+// its output moved to another object or was removed
+func (r *BSSQueryReconciler) pruneOutputs(ctx context.Context, bssQuery *bssv1alpha1.BSSQuery) error {
+	var current string
+	spec := bssQuery.Spec.Output
+	switch {
+	case spec == nil:
+	case spec.ConfigMap != nil:
+		current = "ConfigMap/" + builder.QueryOutputName(bssQuery)
+	case spec.Secret != nil:
+		current = "Secret/" + builder.QueryOutputName(bssQuery)
+	}
+
+	selector := client.MatchingLabels{
+		builder.LabelApp:      builder.QueryOutputLabels(bssQuery)[builder.LabelApp],
+		builder.LabelInstance: bssQuery.Name,
+	}
+	var configMaps corev1.ConfigMapList
+	if err := r.List(ctx, &configMaps, client.InNamespace(bssQuery.Namespace), selector); err != nil {
+		return err
+	}
+	var secrets corev1.SecretList
+	if err := r.List(ctx, &secrets, client.InNamespace(bssQuery.Namespace), selector); err != nil {
+		return err
+	}
+
+	var stale []client.Object
+	for i := range configMaps.Items {
+		if "ConfigMap/"+configMaps.Items[i].Name != current {
+			stale = append(stale, &configMaps.Items[i])
+		}
+	}
+	for i := range secrets.Items {
+		if "Secret/"+secrets.Items[i].Name != current {
+			stale = append(stale, &secrets.Items[i])
+		}
+	}
+	for _, obj := range stale {
+		if !metav1.IsControlledBy(obj, bssQuery) {
+			continue
+		}
+		log.FromContext(ctx).Info("Deleting previous output", "name", obj.GetName())
+		if err := r.Delete(ctx, obj); client.IgnoreNotFound(err) != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// outputTarget returns the object a BSSQuery annotates, with only its type
+// and key set
+func outputTarget(bssQuery *bssv1alpha1.BSSQuery) *unstructured.Unstructured {
+	ref := bssQuery.Spec.Output.Annotations.TargetRef
+	target := &unstructured.Unstructured{}
+	target.SetAPIVersion(ref.APIVersion)
+	target.SetKind(ref.Kind)
+	target.SetName(ref.Name)
+	target.SetNamespace(bssQuery.Namespace)
+	return target
+}
+
+// queryFieldOwner returns the field manager a BSSQuery applies the
+// annotations of its target as. Names too long for a field manager are
+// replaced by their digest.
+func queryFieldOwner(bssQuery *bssv1alpha1.BSSQuery) string {
+	owner := resources.FieldOwner + "/" + bssQuery.Name
+	if len(owner) <= maxFieldManagerLength {
+		return owner
+	}
+	sum := sha256.Sum256([]byte(bssQuery.Name))
+	return resources.FieldOwner + "/" + hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,265 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	bssv1alpha1 "github.com/brmorris/bss-operator/api/v1alpha1"
+)
+
+// metadataChangedPredicate passes updates to the labels or annotations of an
+// owned resource, so hand edits to the metadata the operator manages are reverted
+var metadataChangedPredicate = predicate.Or(
+	predicate.LabelChangedPredicate{},
+	predicate.AnnotationChangedPredicate{},
+)
+
+// deploymentPredicate passes Deployment events that either change the desired
+// state (spec or metadata) or the rollout readiness reported in status.
+// Heartbeat-only status updates, such as condition timestamps, are dropped.
+func deploymentPredicate() predicate.Predicate {
+	return predicate.Or(
+		predicate.GenerationChangedPredicate{},
+		metadataChangedPredicate,
+		predicate.Funcs{
+			UpdateFunc: func(e event.UpdateEvent) bool {
+				oldDeployment, ok := e.ObjectOld.(*appsv1.Deployment)
+				if !ok {
+					return false
+				}
+				newDeployment, ok := e.ObjectNew.(*appsv1.Deployment)
+				if !ok {
+					return false
+				}
+				return deploymentRolloutChanged(&oldDeployment.Status, &newDeployment.Status)
+			},
+		},
+	)
+}
+
+// statefulSetPredicate is the StatefulSet counterpart of deploymentPredicate
+func statefulSetPredicate() predicate.Predicate {
+	return predicate.Or(
+		predicate.GenerationChangedPredicate{},
+		metadataChangedPredicate,
+		predicate.Funcs{
+			UpdateFunc: func(e event.UpdateEvent) bool {
+				oldStatefulSet, ok := e.ObjectOld.(*appsv1.StatefulSet)
+				if !ok {
+					return false
+				}
+				newStatefulSet, ok := e.ObjectNew.(*appsv1.StatefulSet)
+				if !ok {
+					return false
+				}
+				return statefulSetRolloutChanged(&oldStatefulSet.Status, &newStatefulSet.Status)
+			},
+		},
+	)
+}
+
+// servicePredicate passes Service events that change the spec or metadata.
+// Services do not bump metadata.generation, so the spec is compared directly,
+// and load balancer status updates are ignored.
+func servicePredicate() predicate.Predicate {
+	return predicate.Or(
+		metadataChangedPredicate,
+		predicate.Funcs{
+			UpdateFunc: func(e event.UpdateEvent) bool {
+				oldService, ok := e.ObjectOld.(*corev1.Service)
+				if !ok {
+					return false
+				}
+				newService, ok := e.ObjectNew.(*corev1.Service)
+				if !ok {
+					return false
+				}
+				return !equality.Semantic.DeepEqual(oldService.Spec, newService.Spec)
+			},
+		},
+	)
+}
+
+// configMapPredicate passes ConfigMap events that change the data or
+// metadata. ConfigMaps do not bump metadata.generation, so the data is
+// compared directly.
+func configMapPredicate() predicate.Predicate {
+	return predicate.Or(
+		metadataChangedPredicate,
+		predicate.Funcs{
+			UpdateFunc: func(e event.UpdateEvent) bool {
+				oldConfigMap, ok := e.ObjectOld.(*corev1.ConfigMap)
+				if !ok {
+					return false
+				}
+				newConfigMap, ok := e.ObjectNew.(*corev1.ConfigMap)
+				if !ok {
+					return false
+				}
+				return !equality.Semantic.DeepEqual(oldConfigMap.Data, newConfigMap.Data) ||
+					!equality.Semantic.DeepEqual(oldConfigMap.BinaryData, newConfigMap.BinaryData)
+			},
+		},
+	)
+}
+
+// secretPredicate is the Secret counterpart of configMapPredicate
+func secretPredicate() predicate.Predicate {
+	return predicate.Or(
+		metadataChangedPredicate,
+		predicate.Funcs{
+			UpdateFunc: func(e event.UpdateEvent) bool {
+				oldSecret, ok := e.ObjectOld.(*corev1.Secret)
+				if !ok {
+					return false
+				}
+				newSecret, ok := e.ObjectNew.(*corev1.Secret)
+				if !ok {
+					return false
+				}
+				return !equality.Semantic.DeepEqual(oldSecret.Data, newSecret.Data)
+			},
+		},
+	)
+}
+
+// specPredicate passes events that change the spec or metadata of kinds that
+// bump metadata.generation on spec changes, such as Ingresses, HTTPRoutes,
+// NetworkPolicies and PodDisruptionBudgets. Status updates written by other controllers are ignored.
+func specPredicate() predicate.Predicate {
+	return predicate.Or(
+		predicate.GenerationChangedPredicate{},
+		metadataChangedPredicate,
+	)
+}
+
+// horizontalPodAutoscalerPredicate passes HorizontalPodAutoscaler events that
+// change the spec or metadata. The autoscaler rewrites its status with
+// current metrics on every sync, and does not bump metadata.generation.
+func horizontalPodAutoscalerPredicate() predicate.Predicate {
+	return predicate.Or(
+		metadataChangedPredicate,
+		predicate.Funcs{
+			UpdateFunc: func(e event.UpdateEvent) bool {
+				oldHPA, ok := e.ObjectOld.(*autoscalingv2.HorizontalPodAutoscaler)
+				if !ok {
+					return false
+				}
+				newHPA, ok := e.ObjectNew.(*autoscalingv2.HorizontalPodAutoscaler)
+				if !ok {
+					return false
+				}
+				return !equality.Semantic.DeepEqual(oldHPA.Spec, newHPA.Spec)
+			},
+		},
+	)
+}
+
+// jobPredicate passes upgrade Job events that finish the Job. Progress
+// updates of running Jobs are ignored.
+func jobPredicate() predicate.Predicate {
+	return predicate.Funcs{
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			oldJob, ok := e.ObjectOld.(*batchv1.Job)
+			if !ok {
+				return false
+			}
+			newJob, ok := e.ObjectNew.(*batchv1.Job)
+			if !ok {
+				return false
+			}
+			oldFinished, _ := jobFinished(oldJob)
+			newFinished, _ := jobFinished(newJob)
+			return oldFinished != newFinished
+		},
+	}
+}
+
+// clusterEndpointPredicate passes BssCluster events that change whether or
+// where BSSQueries referencing it can reach bss-api: spec changes, such as
+// the Service port, deletion and changes to the Available condition
+func clusterEndpointPredicate() predicate.Predicate {
+	return predicate.Or(
+		predicate.GenerationChangedPredicate{},
+		predicate.Funcs{
+			UpdateFunc: func(e event.UpdateEvent) bool {
+				oldCluster, ok := e.ObjectOld.(*bssv1alpha1.BssCluster)
+				if !ok {
+					return false
+				}
+				newCluster, ok := e.ObjectNew.(*bssv1alpha1.BssCluster)
+				if !ok {
+					return false
+				}
+				return meta.IsStatusConditionTrue(oldCluster.Status.Conditions, TypeAvailable) !=
+					meta.IsStatusConditionTrue(newCluster.Status.Conditions, TypeAvailable)
+			},
+		},
+	)
+}
+
+// deploymentRolloutChanged reports whether any field used to compute the
+// BssCluster status differs between two Deployment statuses
+func deploymentRolloutChanged(oldStatus, newStatus *appsv1.DeploymentStatus) bool {
+	if oldStatus.ObservedGeneration != newStatus.ObservedGeneration ||
+		oldStatus.Replicas != newStatus.Replicas ||
+		oldStatus.UpdatedReplicas != newStatus.UpdatedReplicas ||
+		oldStatus.ReadyReplicas != newStatus.ReadyReplicas ||
+		oldStatus.AvailableReplicas != newStatus.AvailableReplicas {
+		return true
+	}
+
+	if len(oldStatus.Conditions) != len(newStatus.Conditions) {
+		return true
+	}
+	for _, newCondition := range newStatus.Conditions {
+		found := false
+		for _, oldCondition := range oldStatus.Conditions {
+			if oldCondition.Type != newCondition.Type {
+				continue
+			}
+			found = true
+			if oldCondition.Status != newCondition.Status || oldCondition.Reason != newCondition.Reason {
+				return true
+			}
+		}
+		if !found {
+			return true
+		}
+	}
+	return false
+}
+
+// statefulSetRolloutChanged reports whether any field used to compute the
+// BssCluster status differs between two StatefulSet statuses
+func statefulSetRolloutChanged(oldStatus, newStatus *appsv1.StatefulSetStatus) bool {
+	return oldStatus.ObservedGeneration != newStatus.ObservedGeneration ||
+		oldStatus.Replicas != newStatus.Replicas ||
+		oldStatus.UpdatedReplicas != newStatus.UpdatedReplicas ||
+		oldStatus.ReadyReplicas != newStatus.ReadyReplicas ||
+		oldStatus.AvailableReplicas != newStatus.AvailableReplicas ||
+		oldStatus.CurrentRevision != newStatus.CurrentRevision ||
+		oldStatus.UpdateRevision != newStatus.UpdateRevision
+}
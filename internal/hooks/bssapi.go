@@ -0,0 +1,115 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hooks
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/record"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	bssv1alpha1 "github.com/brmorris/bss-operator/api/v1alpha1"
+	bssclient "github.com/brmorris/bss-operator/internal/client"
+)
+
+// EventReasonRemoteClusterNotFound is the reason of the Event recorded when
+// a deleted BssCluster has no remote cluster to deregister
+const EventReasonRemoteClusterNotFound = "RemoteClusterNotFound"
+
+// BSSAPIDeregistration is a pre-delete hook that removes the remote cluster
+// of a BssCluster from the bss-api. The remote cluster is identified by the
+// bss.localhost/cluster-id annotation, or else by the cluster name in the
+// spec. A BssCluster without a remote cluster is skipped with an Event.
+type BSSAPIDeregistration struct {
+	client   *bssclient.GraphQLClient
+	recorder record.EventRecorder
+}
+
+// NewBSSAPIDeregistration creates a BSSAPIDeregistration hook for the given
+// GraphQL endpoint. Events are not recorded when recorder is nil.
+func NewBSSAPIDeregistration(endpoint string, recorder record.EventRecorder) *BSSAPIDeregistration {
+	return &BSSAPIDeregistration{
+		client:   bssclient.NewGraphQLClient(endpoint),
+		recorder: recorder,
+	}
+}
+
+// Name identifies the hook in logs and status messages
+func (h *BSSAPIDeregistration) Name() string {
+	return "bss-api-deregistration"
+}
+
+// PreDelete deletes the remote cluster. A cluster that is already gone is not an error.
+func (h *BSSAPIDeregistration) PreDelete(ctx context.Context, bssCluster *bssv1alpha1.BssCluster) error {
+	log := logf.FromContext(ctx)
+
+	clusterID := bssCluster.Annotations[bssv1alpha1.AnnotationClusterID]
+	if clusterID == "" {
+		var err error
+		if clusterID, err = h.findCluster(ctx, bssCluster.Spec.Name); err != nil {
+			return err
+		}
+	}
+	if clusterID == "" {
+		log.Info("No remote cluster registered, skipping deregistration", "name", bssCluster.Spec.Name)
+		if h.recorder != nil {
+			h.recorder.Eventf(bssCluster, corev1.EventTypeWarning, EventReasonRemoteClusterNotFound,
+				"No %s annotation and no bss-api cluster named %s, nothing to deregister",
+				bssv1alpha1.AnnotationClusterID, bssCluster.Spec.Name)
+		}
+		return nil
+	}
+
+	deleted, err := h.client.DeleteCluster(ctx, clusterID)
+	if err != nil {
+		return fmt.Errorf("failed to deregister cluster %s: %w", clusterID, err)
+	}
+
+	if deleted {
+		log.Info("Deregistered cluster from bss-api", "clusterID", clusterID)
+	} else {
+		log.Info("Cluster already absent from bss-api", "clusterID", clusterID)
+	}
+	return nil
+}
+
+// findCluster returns the ID of the remote cluster with the given name, or an
+// empty ID if there is none. Several clusters with the name are an error, since
+// the one to deregister cannot be told apart.
+func (h *BSSAPIDeregistration) findCluster(ctx context.Context, name string) (string, error) {
+	clusters, err := h.client.ListClusters(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to look up cluster %s: %w", name, err)
+	}
+	var ids []string
+	for _, cluster := range clusters {
+		if cluster != nil && cluster.Name == name {
+			ids = append(ids, cluster.ID)
+		}
+	}
+	switch len(ids) {
+	case 0:
+		return "", nil
+	case 1:
+		return ids[0], nil
+	default:
+		return "", fmt.Errorf("found %d clusters named %s, set the %s annotation to the one to deregister",
+			len(ids), name, bssv1alpha1.AnnotationClusterID)
+	}
+}
@@ -0,0 +1,108 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hooks
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+
+	bssv1alpha1 "github.com/brmorris/bss-operator/api/v1alpha1"
+	bssclient "github.com/brmorris/bss-operator/internal/client"
+)
+
+var _ = Describe("BSSAPIDeregistration", func() {
+	var (
+		ctx        context.Context
+		server     *httptest.Server
+		recorder   *record.FakeRecorder
+		bssCluster *bssv1alpha1.BssCluster
+		clusters   []string
+		deleted    []string
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		recorder = record.NewFakeRecorder(10)
+		bssCluster = &bssv1alpha1.BssCluster{
+			ObjectMeta: metav1.ObjectMeta{Name: "demo", Namespace: "default"},
+			Spec:       bssv1alpha1.BssClusterSpec{Name: "demo"},
+		}
+		clusters = nil
+		deleted = nil
+
+		// The server lists a cluster named after each entry of clusters, with
+		// the ID "id-<index>", and records the IDs it is asked to delete
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var request bssclient.GraphQLRequest
+			Expect(json.NewDecoder(r.Body).Decode(&request)).To(Succeed())
+			if strings.Contains(request.Query, "deleteCluster") {
+				deleted = append(deleted, request.Variables["id"].(string))
+				_, _ = fmt.Fprint(w, `{"data":{"deleteCluster":true}}`)
+				return
+			}
+			var listed []bssclient.ClusterData
+			for i, name := range clusters {
+				listed = append(listed, bssclient.ClusterData{ID: fmt.Sprintf("id-%d", i), Name: name})
+			}
+			Expect(json.NewEncoder(w).Encode(map[string]any{"data": map[string]any{"clusters": listed}})).To(Succeed())
+		}))
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("should deregister the cluster named in the annotation", func() {
+		bssCluster.Annotations = map[string]string{bssv1alpha1.AnnotationClusterID: "registered"}
+
+		Expect(NewBSSAPIDeregistration(server.URL, recorder).PreDelete(ctx, bssCluster)).To(Succeed())
+		Expect(deleted).To(ConsistOf("registered"))
+	})
+
+	It("should look up the cluster by name without the annotation", func() {
+		clusters = []string{"other", "demo"}
+
+		Expect(NewBSSAPIDeregistration(server.URL, recorder).PreDelete(ctx, bssCluster)).To(Succeed())
+		Expect(deleted).To(ConsistOf("id-1"))
+		Expect(recorder.Events).To(BeEmpty())
+	})
+
+	It("should record an Event when there is no cluster to deregister", func() {
+		clusters = []string{"other"}
+
+		Expect(NewBSSAPIDeregistration(server.URL, recorder).PreDelete(ctx, bssCluster)).To(Succeed())
+		Expect(deleted).To(BeEmpty())
+		Expect(recorder.Events).To(Receive(ContainSubstring(EventReasonRemoteClusterNotFound)))
+	})
+
+	It("should fail when several clusters have the name", func() {
+		clusters = []string{"demo", "demo"}
+
+		err := NewBSSAPIDeregistration(server.URL, recorder).PreDelete(ctx, bssCluster)
+		Expect(err).To(MatchError(ContainSubstring(bssv1alpha1.AnnotationClusterID)))
+		Expect(deleted).To(BeEmpty())
+	})
+})
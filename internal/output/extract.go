@@ -0,0 +1,172 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/google/cel-go/cel"
+	"google.golang.org/protobuf/types/known/structpb"
+	"k8s.io/client-go/util/jsonpath"
+
+	bssv1alpha1 "github.com/brmorris/bss-operator/api/v1alpha1"
+)
+
+const (
+	// ResultVariable is the variable CEL expressions read the result from
+	ResultVariable = "result"
+
+	// costLimit bounds the work of a CEL expression on one result
+	costLimit = 1000000
+)
+
+// Extractor extracts the fields of spec.output from query results
+type Extractor struct {
+	fields []field
+}
+
+// field is a compiled output field
+type field struct {
+	name string
+	eval func(result interface{}) (string, error)
+}
+
+// NewExtractor compiles the JSONPath and CEL expressions of output fields
+func NewExtractor(fields []bssv1alpha1.QueryOutputField) (*Extractor, error) {
+	extractor := &Extractor{}
+	for _, f := range fields {
+		eval, err := compileField(f)
+		if err != nil {
+			return nil, fmt.Errorf("field %s: %w", f.Name, err)
+		}
+		extractor.fields = append(extractor.fields, field{name: f.Name, eval: eval})
+	}
+	return extractor, nil
+}
+
+// CompileField compiles the expression of one output field
+func CompileField(f bssv1alpha1.QueryOutputField) error {
+	_, err := compileField(f)
+	return err
+}
+
+func compileField(f bssv1alpha1.QueryOutputField) (func(interface{}) (string, error), error) {
+	switch {
+	case f.JSONPath != "" && f.Expression != "":
+		return nil, fmt.Errorf("jsonPath cannot be combined with expression")
+	case f.JSONPath != "":
+		return compileJSONPath(f.Name, f.JSONPath)
+	case f.Expression != "":
+		return compileExpression(f.Expression)
+	default:
+		return nil, fmt.Errorf("jsonPath or expression is required")
+	}
+}
+
+// Extract returns the value of every field for a JSON result
+func (e *Extractor) Extract(result []byte) (map[string]string, error) {
+	var decoded interface{}
+	if err := json.Unmarshal(result, &decoded); err != nil {
+		return nil, fmt.Errorf("failed to decode result: %w", err)
+	}
+
+	values := make(map[string]string, len(e.fields))
+	for _, f := range e.fields {
+		value, err := f.eval(decoded)
+		if err != nil {
+			return nil, fmt.Errorf("field %s: %w", f.name, err)
+		}
+		values[f.name] = value
+	}
+	return values, nil
+}
+
+// compileJSONPath compiles a kubectl-style JSONPath expression; the braces
+// around it may be left out
+func compileJSONPath(name, expression string) (func(interface{}) (string, error), error) {
+	if !strings.HasPrefix(expression, "{") {
+		expression = "{" + expression + "}"
+	}
+	path := jsonpath.New(name)
+	if err := path.Parse(expression); err != nil {
+		return nil, err
+	}
+
+	return func(result interface{}) (string, error) {
+		found, err := path.FindResults(result)
+		if err != nil {
+			return "", err
+		}
+		var values []interface{}
+		for _, set := range found {
+			for _, value := range set {
+				values = append(values, value.Interface())
+			}
+		}
+		switch len(values) {
+		case 0:
+			return "", fmt.Errorf("%s matched nothing", expression)
+		case 1:
+			return format(values[0])
+		default:
+			return format(values)
+		}
+	}, nil
+}
+
+// compileExpression compiles a CEL expression over the result
+func compileExpression(expression string) (func(interface{}) (string, error), error) {
+	env, err := cel.NewEnv(cel.Variable(ResultVariable, cel.DynType))
+	if err != nil {
+		return nil, err
+	}
+	ast, issues := env.Compile(expression)
+	if issues.Err() != nil {
+		return nil, issues.Err()
+	}
+	program, err := env.Program(ast, cel.CostLimit(costLimit))
+	if err != nil {
+		return nil, err
+	}
+
+	return func(result interface{}) (string, error) {
+		value, _, err := program.Eval(map[string]interface{}{ResultVariable: result})
+		if err != nil {
+			return "", err
+		}
+		native, err := value.ConvertToNative(reflect.TypeOf(&structpb.Value{}))
+		if err != nil {
+			return "", err
+		}
+		return format(native.(*structpb.Value).AsInterface())
+	}, nil
+}
+
+// format writes strings as they are and other values as JSON
+func format(value interface{}) (string, error) {
+	if s, ok := value.(string); ok {
+		return s, nil
+	}
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+	return string(encoded), nil
+}
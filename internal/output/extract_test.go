@@ -0,0 +1,70 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package output
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	bssv1alpha1 "github.com/brmorris/bss-operator/api/v1alpha1"
+)
+
+var _ = Describe("Extractor", func() {
+	result := []byte(`{"cluster":{"id":"abc","name":"demo","replicas":3,"nodes":[{"name":"a"},{"name":"b"}]}}`)
+
+	It("should extract values with JSONPath and CEL", func() {
+		extractor, err := NewExtractor([]bssv1alpha1.QueryOutputField{
+			{Name: "name", JSONPath: "{.cluster.name}"},
+			{Name: "replicas", JSONPath: ".cluster.replicas"},
+			{Name: "nodes", JSONPath: "{.cluster.nodes[*].name}"},
+			{Name: "scaled", Expression: "result.cluster.replicas > 1.0"},
+			{Name: "summary", Expression: `result.cluster.name + "/" + result.cluster.id`},
+			{Name: "nodeNames", Expression: "result.cluster.nodes.map(n, n.name)"},
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		values, err := extractor.Extract(result)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(values).To(Equal(map[string]string{
+			"name":      "demo",
+			"replicas":  "3",
+			"nodes":     `["a","b"]`,
+			"scaled":    "true",
+			"summary":   "demo/abc",
+			"nodeNames": `["a","b"]`,
+		}))
+	})
+
+	It("should reject invalid expressions", func() {
+		Expect(CompileField(bssv1alpha1.QueryOutputField{Name: "a", JSONPath: "{.cluster["})).NotTo(Succeed())
+		Expect(CompileField(bssv1alpha1.QueryOutputField{Name: "a", Expression: "result.cluster +"})).NotTo(Succeed())
+		Expect(CompileField(bssv1alpha1.QueryOutputField{Name: "a"})).NotTo(Succeed())
+		Expect(CompileField(bssv1alpha1.QueryOutputField{Name: "a", JSONPath: "{.a}", Expression: "1"})).NotTo(Succeed())
+	})
+
+	It("should fail when a field is missing from the result", func() {
+		extractor, err := NewExtractor([]bssv1alpha1.QueryOutputField{{Name: "state", JSONPath: "{.cluster.state}"}})
+		Expect(err).NotTo(HaveOccurred())
+		_, err = extractor.Extract(result)
+		Expect(err).To(MatchError(ContainSubstring("field state")))
+
+		extractor, err = NewExtractor([]bssv1alpha1.QueryOutputField{{Name: "state", Expression: "result.cluster.state"}})
+		Expect(err).NotTo(HaveOccurred())
+		_, err = extractor.Extract(result)
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -0,0 +1,273 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	bssv1alpha1 "github.com/brmorris/bss-operator/api/v1alpha1"
+)
+
+const (
+	// FieldOwner is the field manager the operator applies child resources as
+	FieldOwner = "bss-operator"
+
+	// EventReasonDriftCorrected is the reason of the Event recorded when
+	// fields changed outside the operator are reverted
+	EventReasonDriftCorrected = "DriftCorrected"
+
+	// maxReportedFields caps the drifted field paths kept in status and Events
+	maxReportedFields = 10
+)
+
+// Applier applies the desired state of child resources with server-side
+// apply. The output of the builders is the single source of truth for every
+// field the operator sets; fields set by other controllers are left alone.
+type Applier struct {
+	client.Client
+	Scheme *runtime.Scheme
+
+	// Recorder records an Event when drift is corrected. Events are not
+	// recorded when it is nil.
+	Recorder record.EventRecorder
+}
+
+// NewApplier creates a new Applier
+func NewApplier(c client.Client, scheme *runtime.Scheme) *Applier {
+	return &Applier{
+		Client: c,
+		Scheme: scheme,
+	}
+}
+
+// Apply server-side applies desired as a child of the BssCluster. An existing
+// child is first applied in dry-run mode to find the fields that differ from
+// the desired state, so nothing is written while the child is up to date.
+// The hash of the applied state is stamped on the child, so differences found
+// while the desired state is the one last applied are drift: they are
+// corrected and recorded in status and as an Event.
+func (a *Applier) Apply(ctx context.Context, bssCluster *bssv1alpha1.BssCluster, desired client.Object, log logr.Logger) error {
+	gvk, err := apiutil.GVKForObject(desired, a.Scheme)
+	if err != nil {
+		return err
+	}
+	// Apply requests must carry the type of the object
+	desired.GetObjectKind().SetGroupVersionKind(gvk)
+	if err := controllerutil.SetControllerReference(bssCluster, desired, a.Scheme); err != nil {
+		return err
+	}
+	hash, err := appliedHash(desired)
+	if err != nil {
+		return err
+	}
+	annotations := map[string]string{}
+	for key, value := range desired.GetAnnotations() {
+		annotations[key] = value
+	}
+	annotations[bssv1alpha1.AnnotationAppliedHash] = hash
+	desired.SetAnnotations(annotations)
+
+	newObject, err := a.Scheme.New(gvk)
+	if err != nil {
+		return err
+	}
+	existing, ok := newObject.(client.Object)
+	if !ok {
+		return fmt.Errorf("%s is not a client.Object", gvk.Kind)
+	}
+	if err := a.Get(ctx, client.ObjectKeyFromObject(desired), existing); err != nil {
+		if !errors.IsNotFound(err) {
+			return err
+		}
+		log.Info("Creating "+gvk.Kind, "name", desired.GetName())
+		return a.apply(ctx, desired)
+	}
+
+	dryRun, ok := desired.DeepCopyObject().(client.Object)
+	if !ok {
+		return fmt.Errorf("%s is not a client.Object", gvk.Kind)
+	}
+	if err := a.Patch(ctx, dryRun, client.Apply,
+		client.FieldOwner(FieldOwner), client.ForceOwnership, client.DryRunAll); err != nil {
+		return err
+	}
+
+	fields, err := driftedFields(existing, dryRun)
+	if err != nil {
+		return err
+	}
+	switch {
+	case len(fields) > 0:
+	case !appliedBy(existing, FieldOwner):
+		// Adopt children written before the operator used server-side apply,
+		// so fields dropped from the builders are removed in the future
+		log.Info("Taking ownership of "+gvk.Kind, "name", desired.GetName(), "fieldManager", FieldOwner)
+		return a.apply(ctx, desired)
+	default:
+		log.V(1).Info(gvk.Kind+" is up to date", "name", desired.GetName())
+		return nil
+	}
+
+	// A child the operator applied another state to is being updated
+	if existing.GetAnnotations()[bssv1alpha1.AnnotationAppliedHash] != hash {
+		log.Info("Updating "+gvk.Kind, "name", desired.GetName(), "fields", fields)
+		return a.apply(ctx, desired)
+	}
+
+	log.Info("Correcting drift on "+gvk.Kind, "name", desired.GetName(), "fields", fields)
+	if err := a.apply(ctx, desired); err != nil {
+		return err
+	}
+	a.recordDrift(bssCluster, gvk.Kind, desired.GetName(), fields)
+	return nil
+}
+
+func (a *Applier) apply(ctx context.Context, desired client.Object) error {
+	return a.Patch(ctx, desired, client.Apply, client.FieldOwner(FieldOwner), client.ForceOwnership)
+}
+
+// recordDrift records corrected fields in the BssCluster status and as an Event
+func (a *Applier) recordDrift(bssCluster *bssv1alpha1.BssCluster, kind, name string, fields []string) {
+	if len(fields) > maxReportedFields {
+		fields = append(fields[:maxReportedFields:maxReportedFields], fmt.Sprintf("and %d more", len(fields)-maxReportedFields))
+	}
+	bssCluster.Status.LastDriftCorrection = &bssv1alpha1.DriftCorrection{
+		Kind:   kind,
+		Name:   name,
+		Fields: fields,
+		Time:   metav1.Now(),
+	}
+	if a.Recorder != nil {
+		a.Recorder.Eventf(bssCluster, corev1.EventTypeWarning, EventReasonDriftCorrected,
+			"Reverted changes to %s %s: %v", kind, name, fields)
+	}
+}
+
+// appliedHash returns the hash of the desired state of a child
+func appliedHash(desired client.Object) (string, error) {
+	encoded, err := json.Marshal(desired)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(encoded)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// appliedBy reports whether the field manager has applied the object before
+func appliedBy(obj client.Object, fieldManager string) bool {
+	for _, entry := range obj.GetManagedFields() {
+		if entry.Manager == fieldManager && entry.Operation == metav1.ManagedFieldsOperationApply {
+			return true
+		}
+	}
+	return false
+}
+
+// driftedFields returns the paths of the fields that differ between the live
+// object and the result of applying the desired state to it. Status and
+// server-managed metadata are ignored.
+func driftedFields(live, applied client.Object) ([]string, error) {
+	liveFields, err := comparableFields(live)
+	if err != nil {
+		return nil, err
+	}
+	appliedFields, err := comparableFields(applied)
+	if err != nil {
+		return nil, err
+	}
+
+	var fields []string
+	diffFields("", liveFields, appliedFields, &fields)
+	sort.Strings(fields)
+	return fields, nil
+}
+
+// comparableFields converts an object to a map without the fields that
+// change on every write or are owned by other controllers
+func comparableFields(obj client.Object) (map[string]interface{}, error) {
+	fields, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return nil, err
+	}
+	delete(fields, "apiVersion")
+	delete(fields, "kind")
+	delete(fields, "status")
+	if metadata, ok := fields["metadata"].(map[string]interface{}); ok {
+		fields["metadata"] = map[string]interface{}{
+			"labels":          metadata["labels"],
+			"annotations":     metadata["annotations"],
+			"ownerReferences": metadata["ownerReferences"],
+		}
+	}
+	return fields, nil
+}
+
+// diffFields appends the paths below prefix whose values differ. Maps are
+// compared key by key; lists of the same length element by element.
+func diffFields(prefix string, live, applied interface{}, fields *[]string) {
+	switch appliedValue := applied.(type) {
+	case map[string]interface{}:
+		liveValue, ok := live.(map[string]interface{})
+		if !ok {
+			break
+		}
+		keys := map[string]struct{}{}
+		for key := range liveValue {
+			keys[key] = struct{}{}
+		}
+		for key := range appliedValue {
+			keys[key] = struct{}{}
+		}
+		for key := range keys {
+			path := key
+			if prefix != "" {
+				path = prefix + "." + key
+			}
+			diffFields(path, liveValue[key], appliedValue[key], fields)
+		}
+		return
+	case []interface{}:
+		liveValue, ok := live.([]interface{})
+		if !ok || len(liveValue) != len(appliedValue) {
+			break
+		}
+		for i := range appliedValue {
+			diffFields(fmt.Sprintf("%s[%d]", prefix, i), liveValue[i], appliedValue[i], fields)
+		}
+		return
+	}
+
+	if !reflect.DeepEqual(live, applied) {
+		*fields = append(*fields, prefix)
+	}
+}
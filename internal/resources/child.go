@@ -0,0 +1,184 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"context"
+	"reflect"
+
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	bssv1alpha1 "github.com/brmorris/bss-operator/api/v1alpha1"
+)
+
+// Child is a kind of child resource of a BssCluster, reconciled and torn
+// down by the BssClusterReconciler in registration order
+type Child interface {
+	// Kind names the child in logs and status messages
+	Kind() string
+
+	// Object returns an empty object of the child's type, used to watch it
+	Object() client.Object
+
+	// Reconcile creates or updates the child, or deletes it if it is disabled
+	Reconcile(ctx context.Context, bssCluster *bssv1alpha1.BssCluster, log logr.Logger) error
+
+	// Exists reports whether the child exists
+	Exists(ctx context.Context, bssCluster *bssv1alpha1.BssCluster) (bool, error)
+
+	// Delete removes the child if it exists
+	Delete(ctx context.Context, bssCluster *bssv1alpha1.BssCluster, log logr.Logger) error
+}
+
+// Builder builds the desired state of a child resource of a BssCluster
+type Builder[T client.Object] interface {
+	Build(bssCluster *bssv1alpha1.BssCluster) T
+}
+
+// BuilderFunc adapts a function to a Builder
+type BuilderFunc[T client.Object] func(bssCluster *bssv1alpha1.BssCluster) T
+
+// Build calls f(bssCluster)
+func (f BuilderFunc[T]) Build(bssCluster *bssv1alpha1.BssCluster) T {
+	return f(bssCluster)
+}
+
+// MutateFunc adjusts the desired state of a child against the live object
+// before it is applied, for example to carry over immutable fields or fields
+// managed by another controller. It returns false to skip applying the child
+// in this reconcile.
+type MutateFunc[T client.Object] func(ctx context.Context, c client.Client, bssCluster *bssv1alpha1.BssCluster,
+	existing, desired T, log logr.Logger) (bool, error)
+
+// ChildReconciler reconciles one kind of child resource of a BssCluster. The
+// builder's output is server-side applied; children that are disabled for a
+// BssCluster are deleted.
+type ChildReconciler[T client.Object] struct {
+	client.Client
+
+	applier *Applier
+	kind    string
+	builder Builder[T]
+	enabled func(bssCluster *bssv1alpha1.BssCluster) bool
+	mutate  MutateFunc[T]
+}
+
+// NewChildReconciler creates a ChildReconciler that applies the output of the
+// builder through the given Applier. The child is enabled for every BssCluster
+// unless WithEnabled says otherwise.
+func NewChildReconciler[T client.Object](c client.Client, applier *Applier, builder Builder[T]) *ChildReconciler[T] {
+	return &ChildReconciler[T]{
+		Client:  c,
+		applier: applier,
+		kind:    reflect.TypeOf(newObject[T]()).Elem().Name(),
+		builder: builder,
+	}
+}
+
+// WithEnabled sets the condition under which a BssCluster has this child
+func (r *ChildReconciler[T]) WithEnabled(enabled func(bssCluster *bssv1alpha1.BssCluster) bool) *ChildReconciler[T] {
+	r.enabled = enabled
+	return r
+}
+
+// WithMutate sets the function that adjusts the desired state against the
+// live object before it is applied
+func (r *ChildReconciler[T]) WithMutate(mutate MutateFunc[T]) *ChildReconciler[T] {
+	r.mutate = mutate
+	return r
+}
+
+// Kind implements Child
+func (r *ChildReconciler[T]) Kind() string {
+	return r.kind
+}
+
+// Object implements Child
+func (r *ChildReconciler[T]) Object() client.Object {
+	return newObject[T]()
+}
+
+// Reconcile implements Child
+func (r *ChildReconciler[T]) Reconcile(ctx context.Context, bssCluster *bssv1alpha1.BssCluster, log logr.Logger) error {
+	if r.enabled != nil && !r.enabled(bssCluster) {
+		return r.Delete(ctx, bssCluster, log)
+	}
+
+	desired := r.builder.Build(bssCluster)
+	if r.mutate != nil {
+		existing := newObject[T]()
+		if err := r.Get(ctx, client.ObjectKeyFromObject(desired), existing); err != nil {
+			if !errors.IsNotFound(err) {
+				return err
+			}
+		} else {
+			apply, err := r.mutate(ctx, r.Client, bssCluster, existing, desired, log)
+			if err != nil || !apply {
+				return err
+			}
+		}
+	}
+
+	return r.applier.Apply(ctx, bssCluster, desired, log)
+}
+
+// Exists implements Child. Objects of the same name that are not controlled
+// by the BssCluster are not its child.
+func (r *ChildReconciler[T]) Exists(ctx context.Context, bssCluster *bssv1alpha1.BssCluster) (bool, error) {
+	existing, err := r.get(ctx, bssCluster)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return metav1.IsControlledBy(existing, bssCluster), nil
+}
+
+// Delete implements Child. Objects of the same name that are not controlled
+// by the BssCluster, such as a Secret referenced by its spec, are left alone.
+func (r *ChildReconciler[T]) Delete(ctx context.Context, bssCluster *bssv1alpha1.BssCluster, log logr.Logger) error {
+	existing, err := r.get(ctx, bssCluster)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil // Already deleted
+		}
+		return err
+	}
+	if !existing.GetDeletionTimestamp().IsZero() || !metav1.IsControlledBy(existing, bssCluster) {
+		return nil
+	}
+
+	log.Info("Deleting "+r.kind, "name", existing.GetName())
+	return client.IgnoreNotFound(r.Client.Delete(ctx, existing))
+}
+
+// get fetches the live child, named as the builder names it
+func (r *ChildReconciler[T]) get(ctx context.Context, bssCluster *bssv1alpha1.BssCluster) (T, error) {
+	existing := newObject[T]()
+	err := r.Get(ctx, client.ObjectKeyFromObject(r.builder.Build(bssCluster)), existing)
+	return existing, err
+}
+
+// newObject returns a new empty object of the pointer type T
+func newObject[T client.Object]() T {
+	var zero T
+	return reflect.New(reflect.TypeOf(zero).Elem()).Interface().(T)
+}
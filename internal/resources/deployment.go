@@ -21,105 +21,44 @@ import (
 
 	"github.com/go-logr/logr"
 	appsv1 "k8s.io/api/apps/v1"
-	"k8s.io/apimachinery/pkg/api/errors"
-	"k8s.io/apimachinery/pkg/runtime"
-	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
-	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 
 	bssv1alpha1 "github.com/brmorris/bss-operator/api/v1alpha1"
 	"github.com/brmorris/bss-operator/internal/builder"
 )
 
-// DeploymentReconciler handles Deployment reconciliation
-type DeploymentReconciler struct {
-	client.Client
-	Scheme *runtime.Scheme
-}
-
-// NewDeploymentReconciler creates a new DeploymentReconciler
-func NewDeploymentReconciler(c client.Client, scheme *runtime.Scheme) *DeploymentReconciler {
-	return &DeploymentReconciler{
-		Client: c,
-		Scheme: scheme,
+// MutateDeployment is the MutateFunc of the Deployment child. The replica
+// count of an autoscaled BssCluster is left to the HorizontalPodAutoscaler,
+// and pod template changes wait for the maintenance window. A Deployment
+// being migrated away from is left as it is.
+func MutateDeployment(_ context.Context, _ client.Client, bssCluster *bssv1alpha1.BssCluster,
+	existing, desired *appsv1.Deployment, log logr.Logger) (bool, error) {
+	if migratingFrom(bssCluster, bssv1alpha1.WorkloadTypeDeployment) {
+		log.V(1).Info("Keeping the previous workload until the migration completes", "name", existing.Name)
+		return false, nil
 	}
-}
-
-// Reconcile ensures the Deployment exists and matches the desired state
-func (r *DeploymentReconciler) Reconcile(ctx context.Context, bssCluster *bssv1alpha1.BssCluster, log logr.Logger) error {
-	desired := builder.NewDeploymentBuilder(bssCluster).Build()
-
-	// Try to get the existing Deployment
-	existing := &appsv1.Deployment{}
-	err := r.Get(ctx, types.NamespacedName{
-		Name:      desired.Name,
-		Namespace: desired.Namespace,
-	}, existing)
-
-	if err != nil {
-		if errors.IsNotFound(err) {
-			return r.create(ctx, bssCluster, desired, log)
-		}
-		return err
+	keepAutoscaledReplicas(bssCluster, &desired.Spec.Replicas, existing.Spec.Replicas)
+	if err := holdPodTemplate(bssCluster, &existing.ObjectMeta, &desired.ObjectMeta,
+		&existing.Spec.Template, &desired.Spec.Template, log); err != nil {
+		return false, err
 	}
-
-	return r.update(ctx, bssCluster, existing, desired, log)
+	return true, nil
 }
 
-func (r *DeploymentReconciler) create(ctx context.Context, bssCluster *bssv1alpha1.BssCluster, deployment *appsv1.Deployment, log logr.Logger) error {
-	// Set owner reference
-	if err := controllerutil.SetControllerReference(bssCluster, deployment, r.Scheme); err != nil {
-		return err
-	}
-
-	log.Info("Creating Deployment", "name", deployment.Name)
-	if err := r.Create(ctx, deployment); err != nil {
-		return err
-	}
-
-	log.Info("Deployment created successfully", "name", deployment.Name)
-	return nil
+// migratingFrom reports whether the workload of the given kind only serves
+// the BssCluster until a migration away from it completes. It is not applied
+// in the meantime, since its desired state is built from the spec of the new
+// workload, which may for example no longer claim the volumes of a
+// StatefulSet.
+func migratingFrom(bssCluster *bssv1alpha1.BssCluster, kind bssv1alpha1.WorkloadType) bool {
+	return builder.Workload(bssCluster) != kind
 }
 
-func (r *DeploymentReconciler) update(ctx context.Context, bssCluster *bssv1alpha1.BssCluster, existing, desired *appsv1.Deployment, log logr.Logger) error {
-	// Copy resource version and other metadata that should be preserved
-	desired.ResourceVersion = existing.ResourceVersion
-
-	// Set owner reference
-	if err := controllerutil.SetControllerReference(bssCluster, desired, r.Scheme); err != nil {
-		return err
+// keepAutoscaledReplicas replaces the desired replica count with the live
+// one while the BssCluster is autoscaled, so applying the workload does not
+// undo the scaling decisions of the HorizontalPodAutoscaler
+func keepAutoscaledReplicas(bssCluster *bssv1alpha1.BssCluster, desired **int32, existing *int32) {
+	if builder.Autoscaled(bssCluster) && existing != nil {
+		*desired = existing
 	}
-
-	// Check if update is needed (simple comparison for now)
-	if r.needsUpdate(existing, desired) {
-		log.Info("Updating Deployment", "name", desired.Name)
-		if err := r.Update(ctx, desired); err != nil {
-			return err
-		}
-		log.Info("Deployment updated successfully", "name", desired.Name)
-	} else {
-		log.V(1).Info("Deployment already up to date", "name", desired.Name)
-	}
-
-	return nil
-}
-
-func (r *DeploymentReconciler) needsUpdate(existing, desired *appsv1.Deployment) bool {
-	// Compare replicas
-	if existing.Spec.Replicas == nil || desired.Spec.Replicas == nil {
-		return true
-	}
-	if *existing.Spec.Replicas != *desired.Spec.Replicas {
-		return true
-	}
-
-	// Compare image
-	if len(existing.Spec.Template.Spec.Containers) == 0 || len(desired.Spec.Template.Spec.Containers) == 0 {
-		return true
-	}
-	if existing.Spec.Template.Spec.Containers[0].Image != desired.Spec.Template.Spec.Containers[0].Image {
-		return true
-	}
-
-	return false
 }
@@ -1,30 +0,0 @@
-/*
-Copyright 2025.
-
-Licensed under the Apache License, Version 2.0 (the "License");
-you may not use this file except in compliance with the License.
-You may obtain a copy of the License at
-
-    http://www.apache.org/licenses/LICENSE-2.0
-
-Unless required by applicable law or agreed to in writing, software
-distributed under the License is distributed on an "AS IS" BASIS,
-WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
-See the License for the specific language governing permissions and
-limitations under the License.
-*/
-
-package resources
-
-// IngressReconciler handles Ingress reconciliation
-// TODO: Implement Ingress reconciliation following the pattern in statefulset.go
-//
-// Example usage:
-// 1. Create internal/builder/ingress_builder.go
-// 2. Implement IngressReconciler.Reconcile()
-// 3. Add to BssClusterReconciler in controller
-// 4. Add RBAC marker: // +kubebuilder:rbac:groups=networking.k8s.io,resources=ingresses,verbs=get;list;watch;create;update;patch;delete
-//
-// Note: Ingress should be reconciled AFTER Service exists
-//
-// See docs/controller_architecture.md for detailed implementation guide
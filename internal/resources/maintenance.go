@@ -0,0 +1,76 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	bssv1alpha1 "github.com/brmorris/bss-operator/api/v1alpha1"
+	"github.com/brmorris/bss-operator/internal/builder"
+)
+
+// holdPodTemplate keeps the live pod template of the workload while its
+// maintenance window is closed, so changes that roll the pods wait for the
+// window to open. Upgrades and credentials rotations wait for the window
+// before they start, so their templates are let through. The hash of the
+// rolled out template is stamped on the workload, and that of a held one is
+// recorded in the pending annotation.
+func holdPodTemplate(bssCluster *bssv1alpha1.BssCluster, existingMeta, desiredMeta *metav1.ObjectMeta,
+	existing, desired *corev1.PodTemplateSpec, log logr.Logger) error {
+	hash, err := podTemplateHash(desired)
+	if err != nil {
+		return err
+	}
+	if desiredMeta.Annotations == nil {
+		desiredMeta.Annotations = map[string]string{}
+	}
+
+	rolledOut := existingMeta.Annotations[bssv1alpha1.AnnotationPodTemplateHash]
+	opens, open := builder.NextMaintenanceWindow(bssCluster, time.Now())
+	if rolledOut == "" || rolledOut == hash || open ||
+		bssCluster.Status.TargetVersion != "" ||
+		existing.Annotations[bssv1alpha1.AnnotationCredentialsRotatedAt] !=
+			desired.Annotations[bssv1alpha1.AnnotationCredentialsRotatedAt] {
+		desiredMeta.Annotations[bssv1alpha1.AnnotationPodTemplateHash] = hash
+		return nil
+	}
+
+	if existingMeta.Annotations[bssv1alpha1.AnnotationPendingPodTemplateHash] != hash {
+		log.Info("Pod template change waits for the maintenance window", "name", existingMeta.Name, "opens", opens)
+	}
+	*desired = *existing.DeepCopy()
+	desiredMeta.Annotations[bssv1alpha1.AnnotationPodTemplateHash] = rolledOut
+	desiredMeta.Annotations[bssv1alpha1.AnnotationPendingPodTemplateHash] = hash
+	return nil
+}
+
+// podTemplateHash returns the hash of a desired pod template
+func podTemplateHash(template *corev1.PodTemplateSpec) (string, error) {
+	encoded, err := json.Marshal(template)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(encoded)
+	return hex.EncodeToString(sum[:]), nil
+}
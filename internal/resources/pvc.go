@@ -16,16 +16,136 @@ limitations under the License.
 
 package resources
 
-// PVCReconciler handles PersistentVolumeClaim reconciliation
-// TODO: Implement PVC reconciliation following the pattern in statefulset.go
-//
-// Example usage:
-// 1. Create internal/builder/pvc_builder.go
-// 2. Implement PVCReconciler.Reconcile()
-// 3. Add to BssClusterReconciler in controller
-// 4. Add RBAC marker: // +kubebuilder:rbac:groups=core,resources=persistentvolumeclaims,verbs=get;list;watch;create;update;patch;delete
-//
-// Note: Consider using StatefulSet VolumeClaimTemplates instead of manual PVC management
-// PVCs should be reconciled BEFORE StatefulSet if not using VolumeClaimTemplates
-//
-// See docs/controller_architecture.md for detailed implementation guide
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	bssv1alpha1 "github.com/brmorris/bss-operator/api/v1alpha1"
+	"github.com/brmorris/bss-operator/internal/builder"
+)
+
+// PVCReconciler handles the PersistentVolumeClaims created from the
+// StatefulSet's volume claim templates. The StatefulSet controller creates
+// the claims; this reconciler expands them when spec.storage.size grows and
+// deletes them with the BssCluster when the retention policy asks for it.
+type PVCReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+}
+
+// NewPVCReconciler creates a new PVCReconciler
+func NewPVCReconciler(c client.Client, scheme *runtime.Scheme) *PVCReconciler {
+	return &PVCReconciler{
+		Client: c,
+		Scheme: scheme,
+	}
+}
+
+// Reconcile expands existing claims that are smaller than the requested size.
+// Claims are never shrunk.
+func (r *PVCReconciler) Reconcile(ctx context.Context, bssCluster *bssv1alpha1.BssCluster, log logr.Logger) error {
+	storage := bssCluster.Spec.Storage
+	if storage == nil || builder.Workload(bssCluster) != bssv1alpha1.WorkloadTypeStatefulSet {
+		return nil
+	}
+
+	claims, err := r.Claims(ctx, bssCluster)
+	if err != nil {
+		return err
+	}
+
+	for i := range claims {
+		claim := &claims[i]
+		if !claim.DeletionTimestamp.IsZero() {
+			continue
+		}
+		current := claim.Spec.Resources.Requests[corev1.ResourceStorage]
+		if storage.Size.Cmp(current) <= 0 {
+			continue
+		}
+
+		log.Info("Expanding PersistentVolumeClaim", "name", claim.Name, "from", current.String(), "to", storage.Size.String())
+		if claim.Spec.Resources.Requests == nil {
+			claim.Spec.Resources.Requests = corev1.ResourceList{}
+		}
+		claim.Spec.Resources.Requests[corev1.ResourceStorage] = storage.Size
+		if err := r.Update(ctx, claim); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Kind implements Child
+func (r *PVCReconciler) Kind() string {
+	return "PersistentVolumeClaim"
+}
+
+// Object implements Child
+func (r *PVCReconciler) Object() client.Object {
+	return &corev1.PersistentVolumeClaim{}
+}
+
+// Exists implements Child. It reports whether claims remain that are deleted
+// with the BssCluster; retained claims do not count.
+func (r *PVCReconciler) Exists(ctx context.Context, bssCluster *bssv1alpha1.BssCluster) (bool, error) {
+	if !DeleteClaimsWithCluster(bssCluster) {
+		return false, nil
+	}
+	claims, err := r.Claims(ctx, bssCluster)
+	if err != nil {
+		return false, err
+	}
+	return len(claims) > 0, nil
+}
+
+// Claims lists the data volume claims of a BssCluster
+func (r *PVCReconciler) Claims(ctx context.Context, bssCluster *bssv1alpha1.BssCluster) ([]corev1.PersistentVolumeClaim, error) {
+	var claims corev1.PersistentVolumeClaimList
+	if err := r.List(ctx, &claims,
+		client.InNamespace(bssCluster.Namespace),
+		client.MatchingLabels(builder.SelectorLabels(bssCluster)),
+	); err != nil {
+		return nil, err
+	}
+	return claims.Items, nil
+}
+
+// Delete removes the data volume claims if the retention policy deletes them
+// with the BssCluster. Retained claims are left untouched.
+func (r *PVCReconciler) Delete(ctx context.Context, bssCluster *bssv1alpha1.BssCluster, log logr.Logger) error {
+	if !DeleteClaimsWithCluster(bssCluster) {
+		return nil
+	}
+
+	claims, err := r.Claims(ctx, bssCluster)
+	if err != nil {
+		return err
+	}
+
+	for i := range claims {
+		if !claims[i].DeletionTimestamp.IsZero() {
+			continue
+		}
+		log.Info("Deleting PersistentVolumeClaim", "name", claims[i].Name)
+		if err := r.Client.Delete(ctx, &claims[i]); err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// DeleteClaimsWithCluster reports whether the data volume claims of a
+// BssCluster are deleted along with it
+func DeleteClaimsWithCluster(bssCluster *bssv1alpha1.BssCluster) bool {
+	storage := bssCluster.Spec.Storage
+	return storage != nil && storage.RetentionPolicy != nil &&
+		storage.RetentionPolicy.WhenDeleted == appsv1.DeletePersistentVolumeClaimRetentionPolicyType
+}
@@ -16,15 +16,33 @@ limitations under the License.
 
 package resources
 
-// SecretReconciler handles Secret reconciliation
-// TODO: Implement Secret reconciliation following the pattern in statefulset.go
-//
-// Example usage:
-// 1. Create internal/builder/secret_builder.go
-// 2. Implement SecretReconciler.Reconcile()
-// 3. Add to BssClusterReconciler in controller
-// 4. Add RBAC marker: // +kubebuilder:rbac:groups=core,resources=secrets,verbs=get;list;watch;create;update;patch;delete
-//
-// IMPORTANT: Never log secret data. Be careful with secret handling.
-//
-// See docs/controller_architecture.md for detailed implementation guide
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	bssv1alpha1 "github.com/brmorris/bss-operator/api/v1alpha1"
+)
+
+// MutateCredentialsSecret is the MutateFunc of the generated credentials
+// Secret. The builder generates new values on every call, so the live values
+// are kept until the rotation time stamped on the Secret changes. Values are
+// never logged.
+func MutateCredentialsSecret(_ context.Context, _ client.Client, _ *bssv1alpha1.BssCluster,
+	existing, desired *corev1.Secret, log logr.Logger) (bool, error) {
+	rotatedAt := desired.Annotations[bssv1alpha1.AnnotationCredentialsRotatedAt]
+	if existing.Annotations[bssv1alpha1.AnnotationCredentialsRotatedAt] != rotatedAt {
+		log.Info("Rotating credentials", "name", desired.Name, "rotatedAt", rotatedAt)
+		return true, nil
+	}
+
+	// Keys removed from the live Secret are regenerated
+	for key := range desired.Data {
+		if value := existing.Data[key]; len(value) > 0 {
+			desired.Data[key] = value
+		}
+	}
+	return true, nil
+}
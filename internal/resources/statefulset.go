@@ -21,122 +21,71 @@ import (
 
 	"github.com/go-logr/logr"
 	appsv1 "k8s.io/api/apps/v1"
-	"k8s.io/apimachinery/pkg/api/errors"
-	"k8s.io/apimachinery/pkg/runtime"
-	"k8s.io/apimachinery/pkg/types"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
-	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 
 	bssv1alpha1 "github.com/brmorris/bss-operator/api/v1alpha1"
-	"github.com/brmorris/bss-operator/internal/builder"
 )
 
-// StatefulSetReconciler handles StatefulSet reconciliation
-type StatefulSetReconciler struct {
-	client.Client
-	Scheme *runtime.Scheme
-}
-
-// NewStatefulSetReconciler creates a new StatefulSetReconciler
-func NewStatefulSetReconciler(c client.Client, scheme *runtime.Scheme) *StatefulSetReconciler {
-	return &StatefulSetReconciler{
-		Client: c,
-		Scheme: scheme,
-	}
-}
-
-// Reconcile ensures the StatefulSet exists and matches the desired state
-func (r *StatefulSetReconciler) Reconcile(ctx context.Context, bssCluster *bssv1alpha1.BssCluster, log logr.Logger) error {
-	desired := builder.NewStatefulSetBuilder(bssCluster).Build()
-
-	// Try to get the existing StatefulSet
-	existing := &appsv1.StatefulSet{}
-	err := r.Get(ctx, types.NamespacedName{
-		Name:      desired.Name,
-		Namespace: desired.Namespace,
-	}, existing)
-
-	if err != nil {
-		if errors.IsNotFound(err) {
-			return r.create(ctx, bssCluster, desired, log)
-		}
-		return err
+// MutateStatefulSet is the MutateFunc of the StatefulSet child. Volume claim
+// templates and the governing Service are immutable, so a change to either
+// recreates the StatefulSet. The replica count of an autoscaled BssCluster is
+// left to the HorizontalPodAutoscaler, and pod template changes wait for the
+// maintenance window. A StatefulSet being migrated away from is left as it is.
+func MutateStatefulSet(ctx context.Context, c client.Client, bssCluster *bssv1alpha1.BssCluster,
+	existing, desired *appsv1.StatefulSet, log logr.Logger) (bool, error) {
+	if migratingFrom(bssCluster, bssv1alpha1.WorkloadTypeStatefulSet) {
+		log.V(1).Info("Keeping the previous workload until the migration completes", "name", existing.Name)
+		return false, nil
 	}
-
-	return r.update(ctx, bssCluster, existing, desired, log)
-}
-
-func (r *StatefulSetReconciler) create(ctx context.Context, bssCluster *bssv1alpha1.BssCluster, statefulSet *appsv1.StatefulSet, log logr.Logger) error {
-	// Set owner reference
-	if err := controllerutil.SetControllerReference(bssCluster, statefulSet, r.Scheme); err != nil {
-		return err
+	// An orphaning delete is in flight; the StatefulSet is recreated once it is gone
+	if !existing.DeletionTimestamp.IsZero() {
+		log.V(1).Info("Waiting for StatefulSet deletion to complete", "name", existing.Name)
+		return false, nil
 	}
 
-	log.Info("Creating StatefulSet", "name", statefulSet.Name)
-	if err := r.Create(ctx, statefulSet); err != nil {
-		return err
+	// Delete the StatefulSet but orphan its pods and claims, so the recreated
+	// StatefulSet adopts them and rolls them onto the new spec without downtime
+	if !claimTemplatesMatch(existing.Spec.VolumeClaimTemplates, desired.Spec.VolumeClaimTemplates) {
+		log.Info("Volume claim templates changed, recreating StatefulSet", "name", existing.Name)
+		return false, c.Delete(ctx, existing, client.PropagationPolicy(metav1.DeletePropagationOrphan))
 	}
-
-	log.Info("StatefulSet created successfully", "name", statefulSet.Name)
-	return nil
-}
-
-func (r *StatefulSetReconciler) update(ctx context.Context, bssCluster *bssv1alpha1.BssCluster, existing, desired *appsv1.StatefulSet, log logr.Logger) error {
-	// Copy resource version and other metadata that should be preserved
-	desired.ResourceVersion = existing.ResourceVersion
-
-	// Set owner reference
-	if err := controllerutil.SetControllerReference(bssCluster, desired, r.Scheme); err != nil {
-		return err
+	if existing.Spec.ServiceName != desired.Spec.ServiceName {
+		log.Info("Governing Service changed, recreating StatefulSet", "name", existing.Name,
+			"from", existing.Spec.ServiceName, "to", desired.Spec.ServiceName)
+		return false, c.Delete(ctx, existing, client.PropagationPolicy(metav1.DeletePropagationOrphan))
 	}
 
-	// Check if update is needed (simple comparison for now)
-	if r.needsUpdate(existing, desired) {
-		log.Info("Updating StatefulSet", "name", desired.Name)
-		if err := r.Update(ctx, desired); err != nil {
-			return err
-		}
-		log.Info("StatefulSet updated successfully", "name", desired.Name)
-	} else {
-		log.V(1).Info("StatefulSet is up to date", "name", desired.Name)
+	// Apply the live templates, which carry the values defaulted by the API
+	// server, so the immutable field is left unchanged
+	desired.Spec.VolumeClaimTemplates = existing.Spec.VolumeClaimTemplates
+	keepAutoscaledReplicas(bssCluster, &desired.Spec.Replicas, existing.Spec.Replicas)
+	if err := holdPodTemplate(bssCluster, &existing.ObjectMeta, &desired.ObjectMeta,
+		&existing.Spec.Template, &desired.Spec.Template, log); err != nil {
+		return false, err
 	}
-
-	return nil
+	return true, nil
 }
 
-// needsUpdate determines if the StatefulSet needs to be updated
-func (r *StatefulSetReconciler) needsUpdate(existing, desired *appsv1.StatefulSet) bool {
-	// Compare replicas
-	if *existing.Spec.Replicas != *desired.Spec.Replicas {
-		return true
+// claimTemplatesMatch compares the fields of the volume claim templates that
+// the builder sets, ignoring values defaulted by the API server
+func claimTemplatesMatch(existing, desired []corev1.PersistentVolumeClaim) bool {
+	if len(existing) != len(desired) {
+		return false
 	}
-
-	// Compare image
-	if len(existing.Spec.Template.Spec.Containers) > 0 && len(desired.Spec.Template.Spec.Containers) > 0 {
-		if existing.Spec.Template.Spec.Containers[0].Image != desired.Spec.Template.Spec.Containers[0].Image {
-			return true
+	for i := range desired {
+		if existing[i].Name != desired[i].Name ||
+			!equality.Semantic.DeepEqual(existing[i].Spec.AccessModes, desired[i].Spec.AccessModes) ||
+			!equality.Semantic.DeepEqual(existing[i].Spec.Resources.Requests, desired[i].Spec.Resources.Requests) {
+			return false
 		}
-	}
-
-	// Add more sophisticated comparison as needed
-	return false
-}
-
-// Delete removes the StatefulSet if it exists
-func (r *StatefulSetReconciler) Delete(ctx context.Context, bssCluster *bssv1alpha1.BssCluster, log logr.Logger) error {
-	statefulSet := &appsv1.StatefulSet{}
-	err := r.Get(ctx, types.NamespacedName{
-		Name:      bssCluster.Name,
-		Namespace: bssCluster.Namespace,
-	}, statefulSet)
-
-	if err != nil {
-		if errors.IsNotFound(err) {
-			return nil // Already deleted
+		// An unset storage class is resolved to the cluster default by the API server
+		if desired[i].Spec.StorageClassName != nil &&
+			!equality.Semantic.DeepEqual(existing[i].Spec.StorageClassName, desired[i].Spec.StorageClassName) {
+			return false
 		}
-		return err
 	}
-
-	log.Info("Deleting StatefulSet", "name", statefulSet.Name)
-	return r.Client.Delete(ctx, statefulSet)
+	return true
 }
@@ -0,0 +1,326 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validation
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	k8svalidation "k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	bssv1alpha1 "github.com/brmorris/bss-operator/api/v1alpha1"
+	bssclient "github.com/brmorris/bss-operator/internal/client"
+	"github.com/brmorris/bss-operator/internal/output"
+)
+
+// ValidateQuery performs validation on a BSSQuery
+func (v *Validator) ValidateQuery(bssQuery *bssv1alpha1.BSSQuery) error {
+	return v.validateQuerySpec(bssQuery).ToAggregate()
+}
+
+func (v *Validator) validateQuerySpec(bssQuery *bssv1alpha1.BSSQuery) field.ErrorList {
+	var allErrs field.ErrorList
+	specPath := field.NewPath("spec")
+
+	// Validate the query targets either a BssCluster or an absolute http(s) URL
+	endpointPath := specPath.Child("apiEndpoint")
+	if clusterRef := bssQuery.Spec.ClusterRef; clusterRef != nil {
+		if bssQuery.Spec.APIEndpoint != "" {
+			allErrs = append(allErrs, field.Forbidden(endpointPath, "apiEndpoint cannot be combined with clusterRef"))
+		}
+		if clusterRef.Name == "" {
+			allErrs = append(allErrs, field.Required(specPath.Child("clusterRef", "name"),
+				"name of the BssCluster is required"))
+		}
+	} else if bssQuery.Spec.APIEndpoint == "" {
+		allErrs = append(allErrs, field.Required(endpointPath, "APIEndpoint or clusterRef is required"))
+	} else if endpoint, err := url.Parse(bssQuery.Spec.APIEndpoint); err != nil {
+		allErrs = append(allErrs, field.Invalid(endpointPath, bssQuery.Spec.APIEndpoint, err.Error()))
+	} else if (endpoint.Scheme != "http" && endpoint.Scheme != "https") || endpoint.Host == "" {
+		allErrs = append(allErrs, field.Invalid(endpointPath, bssQuery.Spec.APIEndpoint,
+			"must be an absolute http or https URL such as http://bss-api:8880/graphql"))
+	}
+
+	// Validate the query type
+	switch bssQuery.Spec.Query {
+	case bssv1alpha1.QueryTypeCluster:
+		if bssQuery.Spec.ClusterID == "" {
+			allErrs = append(allErrs, field.Required(specPath.Child("clusterID"),
+				"ClusterID is required for cluster query type"))
+		}
+	case bssv1alpha1.QueryTypeClusters:
+	case bssv1alpha1.QueryTypeGraphQL:
+		allErrs = append(allErrs, v.validateGraphQL(bssQuery.Spec.GraphQL, specPath.Child("graphql"))...)
+	default:
+		allErrs = append(allErrs, field.NotSupported(specPath.Child("query"), bssQuery.Spec.Query,
+			[]bssv1alpha1.BSSQueryType{bssv1alpha1.QueryTypeCluster, bssv1alpha1.QueryTypeClusters,
+				bssv1alpha1.QueryTypeGraphQL}))
+	}
+	if bssQuery.Spec.Query != bssv1alpha1.QueryTypeGraphQL && bssQuery.Spec.GraphQL != nil {
+		allErrs = append(allErrs, field.Forbidden(specPath.Child("graphql"),
+			fmt.Sprintf("only applies to query type %s", bssv1alpha1.QueryTypeGraphQL)))
+	}
+
+	// Validate the refresh interval; zero selects the default
+	if bssQuery.Spec.RefreshInterval < 0 {
+		allErrs = append(allErrs, field.Invalid(specPath.Child("refreshInterval"), bssQuery.Spec.RefreshInterval,
+			"must not be negative"))
+	}
+
+	if bssQuery.Spec.Output != nil {
+		allErrs = append(allErrs, v.validateOutput(bssQuery.Spec.Output, specPath.Child("output"))...)
+	}
+
+	if bssQuery.Spec.Auth != nil {
+		allErrs = append(allErrs, v.validateAuth(bssQuery, specPath.Child("auth"))...)
+	}
+
+	return allErrs
+}
+
+func (v *Validator) validateAuth(bssQuery *bssv1alpha1.BSSQuery, authPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	auth := bssQuery.Spec.Auth
+
+	// A request carries a single Authorization header
+	credentials := auth.BearerTokenSecretRef != nil || auth.BasicAuthSecretRef != nil
+	if auth.BearerTokenSecretRef != nil && auth.BasicAuthSecretRef != nil {
+		allErrs = append(allErrs, field.Forbidden(authPath.Child("basicAuthSecretRef"),
+			"basicAuthSecretRef cannot be combined with bearerTokenSecretRef"))
+	}
+	if ref := auth.BearerTokenSecretRef; ref != nil && (ref.Name == "" || ref.Key == "") {
+		allErrs = append(allErrs, field.Required(authPath.Child("bearerTokenSecretRef"),
+			"name and key of the Secret are required"))
+	}
+	if ref := auth.BasicAuthSecretRef; ref != nil && ref.Name == "" {
+		allErrs = append(allErrs, field.Required(authPath.Child("basicAuthSecretRef", "name"),
+			"name of the Secret is required"))
+	}
+
+	// Headers are sorted so errors are reported in a stable order
+	names := make([]string, 0, len(auth.Headers))
+	for name := range auth.Headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		headerPath := authPath.Child("headers").Key(name)
+		for _, msg := range k8svalidation.IsHTTPHeaderName(name) {
+			allErrs = append(allErrs, field.Invalid(headerPath, name, msg))
+		}
+		if credentials && http.CanonicalHeaderKey(name) == "Authorization" {
+			allErrs = append(allErrs, field.Forbidden(headerPath,
+				"the Authorization header cannot be combined with a bearer token or basic auth"))
+		}
+	}
+
+	tls := auth.TLS
+	if tls == nil {
+		return allErrs
+	}
+	tlsPath := authPath.Child("tls")
+	if endpoint, err := url.Parse(bssQuery.Spec.APIEndpoint); bssQuery.Spec.ClusterRef != nil ||
+		err != nil || endpoint.Scheme != "https" {
+		allErrs = append(allErrs, field.Forbidden(tlsPath, "tls requires an https apiEndpoint"))
+	}
+	if ca := tls.CA; ca != nil {
+		caPath := tlsPath.Child("ca")
+		switch {
+		case (ca.ConfigMapKeyRef == nil) == (ca.SecretKeyRef == nil):
+			allErrs = append(allErrs, field.Invalid(caPath, field.OmitValueType{},
+				"exactly one of configMapKeyRef and secretKeyRef must be set"))
+		case ca.ConfigMapKeyRef != nil && (ca.ConfigMapKeyRef.Name == "" || ca.ConfigMapKeyRef.Key == ""):
+			allErrs = append(allErrs, field.Required(caPath.Child("configMapKeyRef"),
+				"name and key of the ConfigMap are required"))
+		case ca.SecretKeyRef != nil && (ca.SecretKeyRef.Name == "" || ca.SecretKeyRef.Key == ""):
+			allErrs = append(allErrs, field.Required(caPath.Child("secretKeyRef"),
+				"name and key of the Secret are required"))
+		}
+	}
+	if ref := tls.ClientCertSecretRef; ref != nil && ref.Name == "" {
+		allErrs = append(allErrs, field.Required(tlsPath.Child("clientCertSecretRef", "name"),
+			"name of the Secret is required"))
+	}
+
+	return allErrs
+}
+
+func (v *Validator) validateOutput(spec *bssv1alpha1.QueryOutputSpec, outputPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+
+	// The fields go to exactly one destination
+	destinations := 0
+	for _, set := range []bool{spec.ConfigMap != nil, spec.Secret != nil, spec.Annotations != nil} {
+		if set {
+			destinations++
+		}
+	}
+	if destinations != 1 {
+		allErrs = append(allErrs, field.Invalid(outputPath, field.OmitValueType{},
+			"exactly one of configMap, secret and annotations must be set"))
+	}
+	for _, object := range []struct {
+		path *field.Path
+		spec *bssv1alpha1.QueryOutputObject
+	}{
+		{outputPath.Child("configMap"), spec.ConfigMap},
+		{outputPath.Child("secret"), spec.Secret},
+	} {
+		if object.spec == nil || object.spec.Name == "" {
+			continue
+		}
+		for _, msg := range k8svalidation.IsDNS1123Subdomain(object.spec.Name) {
+			allErrs = append(allErrs, field.Invalid(object.path.Child("name"), object.spec.Name, msg))
+		}
+	}
+	if annotations := spec.Annotations; annotations != nil {
+		annotationsPath := outputPath.Child("annotations")
+		targetPath := annotationsPath.Child("targetRef")
+		if gv, err := schema.ParseGroupVersion(annotations.TargetRef.APIVersion); err != nil ||
+			annotations.TargetRef.APIVersion == "" {
+			allErrs = append(allErrs, field.Invalid(targetPath.Child("apiVersion"), annotations.TargetRef.APIVersion,
+				"must be an API version such as apps/v1"))
+		} else if annotations.TargetRef.Kind != "" && !annotationTargets[gv.WithKind(annotations.TargetRef.Kind).GroupKind()] {
+			// The operator annotates the target with its own permissions
+			allErrs = append(allErrs, field.NotSupported(targetPath.Child("kind"),
+				annotations.TargetRef.APIVersion+" "+annotations.TargetRef.Kind, annotationTargetNames()))
+		}
+		if annotations.TargetRef.Kind == "" {
+			allErrs = append(allErrs, field.Required(targetPath.Child("kind"), "kind of the target is required"))
+		}
+		if annotations.TargetRef.Name == "" {
+			allErrs = append(allErrs, field.Required(targetPath.Child("name"), "name of the target is required"))
+		}
+		if !validAnnotationPrefix(annotations.Prefix) {
+			allErrs = append(allErrs, field.Invalid(annotationsPath.Child("prefix"), annotations.Prefix,
+				fmt.Sprintf("must be %s/ or a subdomain of it, such as team.%s/",
+					bssv1alpha1.QueryOutputAnnotationDomain, bssv1alpha1.QueryOutputAnnotationDomain)))
+		}
+	}
+
+	// Field names become keys of the destination
+	fieldsPath := outputPath.Child("fields")
+	if len(spec.Fields) == 0 {
+		allErrs = append(allErrs, field.Required(fieldsPath, "at least one field is required"))
+	}
+	names := map[string]bool{}
+	for i, f := range spec.Fields {
+		fieldPath := fieldsPath.Index(i)
+		if names[f.Name] {
+			allErrs = append(allErrs, field.Duplicate(fieldPath.Child("name"), f.Name))
+		}
+		names[f.Name] = true
+
+		var msgs []string
+		if spec.Annotations != nil {
+			msgs = k8svalidation.IsQualifiedName(spec.Annotations.Prefix + f.Name)
+		} else {
+			msgs = k8svalidation.IsConfigMapKey(f.Name)
+		}
+		for _, msg := range msgs {
+			allErrs = append(allErrs, field.Invalid(fieldPath.Child("name"), f.Name, msg))
+		}
+
+		if err := output.CompileField(f); err != nil {
+			allErrs = append(allErrs, field.Invalid(fieldPath, field.OmitValueType{}, err.Error()))
+		}
+	}
+
+	return allErrs
+}
+
+// annotationTargets are the kinds of objects a BSSQuery output can annotate
+var annotationTargets = map[schema.GroupKind]bool{
+	{Group: "apps", Kind: "Deployment"}:  true,
+	{Group: "apps", Kind: "StatefulSet"}: true,
+	{Group: "", Kind: "Service"}:         true,
+	{Group: "", Kind: "ConfigMap"}:       true,
+	{Group: "", Kind: "Secret"}:          true,
+}
+
+// annotationTargetNames lists the annotation targets for error messages
+func annotationTargetNames() []string {
+	names := make([]string, 0, len(annotationTargets))
+	for gk := range annotationTargets {
+		apiVersion := "v1"
+		if gk.Group != "" {
+			apiVersion = gk.Group + "/v1"
+		}
+		names = append(names, apiVersion+" "+gk.Kind)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// validAnnotationPrefix reports whether an annotation prefix belongs to the
+// domain of BSSQuery outputs
+func validAnnotationPrefix(prefix string) bool {
+	domain, ok := strings.CutSuffix(prefix, "/")
+	return ok && (domain == bssv1alpha1.QueryOutputAnnotationDomain ||
+		strings.HasSuffix(domain, "."+bssv1alpha1.QueryOutputAnnotationDomain))
+}
+
+func (v *Validator) validateGraphQL(graphQL *bssv1alpha1.GraphQLQuerySpec, graphQLPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	if graphQL == nil {
+		return append(allErrs, field.Required(graphQLPath,
+			fmt.Sprintf("a GraphQL document is required for query type %s", bssv1alpha1.QueryTypeGraphQL)))
+	}
+
+	// The document is either inline or read from a ConfigMap, which is only
+	// parsed once the controller reads it
+	documentPath := graphQLPath.Child("document")
+	switch {
+	case graphQL.Document != "" && graphQL.DocumentFrom != nil:
+		allErrs = append(allErrs, field.Forbidden(graphQLPath.Child("documentFrom"),
+			"documentFrom cannot be combined with document"))
+	case graphQL.Document != "":
+		if _, err := bssclient.ParseDocument(graphQL.Document, graphQL.OperationName); err != nil {
+			allErrs = append(allErrs, field.Invalid(documentPath, field.OmitValueType{}, err.Error()))
+		}
+	case graphQL.DocumentFrom == nil:
+		allErrs = append(allErrs, field.Required(documentPath, "document or documentFrom is required"))
+	case graphQL.DocumentFrom.Name == "" || graphQL.DocumentFrom.Key == "":
+		allErrs = append(allErrs, field.Required(graphQLPath.Child("documentFrom"),
+			"name and key of the ConfigMap are required"))
+	}
+
+	if _, err := GraphQLVariables(graphQL); err != nil {
+		allErrs = append(allErrs, field.Invalid(graphQLPath.Child("variables"), field.OmitValueType{}, err.Error()))
+	}
+
+	return allErrs
+}
+
+// GraphQLVariables decodes the variables of a GraphQL query, which must be a
+// JSON object
+func GraphQLVariables(graphQL *bssv1alpha1.GraphQLQuerySpec) (map[string]interface{}, error) {
+	if graphQL.Variables == nil || len(graphQL.Variables.Raw) == 0 {
+		return nil, nil
+	}
+	var variables map[string]interface{}
+	if err := json.Unmarshal(graphQL.Variables.Raw, &variables); err != nil {
+		return nil, fmt.Errorf("must be a JSON object: %w", err)
+	}
+	return variables, nil
+}
@@ -18,10 +18,25 @@ package validation
 
 import (
 	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/apimachinery/pkg/util/version"
 
 	bssv1alpha1 "github.com/brmorris/bss-operator/api/v1alpha1"
+	"github.com/brmorris/bss-operator/internal/builder"
 )
 
+// minRotationPeriod bounds how often credentials can be rotated, as every
+// rotation rolls the bss-api pods
+const minRotationPeriod = time.Hour
+
+// maxMaintenanceWindow bounds how long a maintenance window stays open
+const maxMaintenanceWindow = 7 * 24 * time.Hour
+
 // Validator validates BssCluster resources
 type Validator struct{}
 
@@ -32,29 +47,293 @@ func NewValidator() *Validator {
 
 // Validate performs validation on a BssCluster
 func (v *Validator) Validate(bssCluster *bssv1alpha1.BssCluster) error {
-	if err := v.validateSpec(bssCluster); err != nil {
-		return err
-	}
+	allErrs := v.validateSpec(bssCluster)
 
 	// Add more validation as needed
-	return nil
+	return allErrs.ToAggregate()
 }
 
-func (v *Validator) validateSpec(bssCluster *bssv1alpha1.BssCluster) error {
+// ValidateUpdate performs validation on a change from oldCluster to newCluster,
+// including the rules that only apply to updates
+func (v *Validator) ValidateUpdate(oldCluster, newCluster *bssv1alpha1.BssCluster) error {
+	allErrs := v.validateSpec(newCluster)
+	allErrs = append(allErrs, v.validateSpecUpdate(oldCluster, newCluster)...)
+	return allErrs.ToAggregate()
+}
+
+func (v *Validator) validateSpec(bssCluster *bssv1alpha1.BssCluster) field.ErrorList {
+	var allErrs field.ErrorList
+	specPath := field.NewPath("spec")
+
 	// Validate name
 	if bssCluster.Spec.Name == "" {
-		return fmt.Errorf("spec.name is required but not specified")
+		allErrs = append(allErrs, field.Required(specPath.Child("name"), "name is required but not specified"))
 	}
 
 	// Validate version
 	if bssCluster.Spec.Version == "" {
-		return fmt.Errorf("spec.version is required but not specified")
+		allErrs = append(allErrs, field.Required(specPath.Child("version"), "version is required but not specified"))
+	} else if _, err := version.ParseSemantic(bssCluster.Spec.Version); err != nil {
+		allErrs = append(allErrs, field.Invalid(specPath.Child("version"), bssCluster.Spec.Version,
+			"must be a semantic version such as 1.2.3"))
 	}
 
 	// Validate replicas
 	if bssCluster.Spec.Replicas != nil && *bssCluster.Spec.Replicas < 1 {
-		return fmt.Errorf("spec.replicas must be at least 1")
+		allErrs = append(allErrs, field.Invalid(specPath.Child("replicas"), *bssCluster.Spec.Replicas,
+			"must be at least 1"))
+	}
+
+	allErrs = append(allErrs, v.validateStorage(bssCluster, specPath.Child("storage"))...)
+	allErrs = append(allErrs, v.validateService(bssCluster, specPath.Child("service"))...)
+	allErrs = append(allErrs, v.validateExposure(bssCluster, specPath.Child("exposure"))...)
+
+	allErrs = append(allErrs, v.validateScaling(bssCluster, specPath)...)
+
+	// Only generated credentials can be regenerated on a schedule
+	if credentials := bssCluster.Spec.Credentials; credentials != nil && credentials.RotateEvery != nil {
+		rotateEveryPath := specPath.Child("credentials", "rotateEvery")
+		if credentials.SecretName != "" {
+			allErrs = append(allErrs, field.Forbidden(rotateEveryPath,
+				"referenced Secrets are rotated by their owner"))
+		}
+		if credentials.RotateEvery.Duration < minRotationPeriod {
+			allErrs = append(allErrs, field.Invalid(rotateEveryPath, credentials.RotateEvery.Duration.String(),
+				fmt.Sprintf("must be at least %s", minRotationPeriod)))
+		}
+	}
+
+	if upgrade := bssCluster.Spec.Upgrade; upgrade != nil && upgrade.Timeout != nil && upgrade.Timeout.Duration <= 0 {
+		allErrs = append(allErrs, field.Invalid(specPath.Child("upgrade", "timeout"), upgrade.Timeout.Duration.String(),
+			"must be greater than zero"))
+	}
+
+	allErrs = append(allErrs, v.validateMaintenanceWindow(bssCluster, specPath.Child("maintenanceWindow"))...)
+
+	// The configuration file is either rendered from settings or given verbatim
+	if config := bssCluster.Spec.Config; config != nil && len(config.Settings) > 0 && config.Raw != "" {
+		allErrs = append(allErrs, field.Forbidden(specPath.Child("config", "raw"),
+			"raw cannot be combined with settings"))
+	}
+
+	return allErrs
+}
+
+func (v *Validator) validateStorage(bssCluster *bssv1alpha1.BssCluster, storagePath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	storage := bssCluster.Spec.Storage
+	if storage == nil {
+		return allErrs
+	}
+
+	// Only StatefulSets can claim a volume per replica
+	if bssCluster.Spec.Workload != bssv1alpha1.WorkloadTypeStatefulSet {
+		allErrs = append(allErrs, field.Forbidden(storagePath,
+			"storage requires workload StatefulSet"))
+	}
+
+	if storage.Size.Sign() <= 0 {
+		allErrs = append(allErrs, field.Invalid(storagePath.Child("size"), storage.Size.String(),
+			"must be greater than zero"))
+	}
+
+	return allErrs
+}
+
+func (v *Validator) validateMaintenanceWindow(bssCluster *bssv1alpha1.BssCluster, windowPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	window := bssCluster.Spec.MaintenanceWindow
+	if window == nil {
+		return allErrs
+	}
+
+	if _, err := time.Parse(builder.MaintenanceWindowTimeOfDay, window.Start); err != nil {
+		allErrs = append(allErrs, field.Invalid(windowPath.Child("start"), window.Start,
+			"must be a time of day such as 02:30"))
+	}
+	if duration := window.Duration.Duration; duration <= 0 || duration > maxMaintenanceWindow {
+		allErrs = append(allErrs, field.Invalid(windowPath.Child("duration"), duration.String(),
+			fmt.Sprintf("must be greater than zero and at most %s", maxMaintenanceWindow)))
+	}
+	if _, err := time.LoadLocation(window.TimeZone); err != nil {
+		allErrs = append(allErrs, field.Invalid(windowPath.Child("timeZone"), window.TimeZone,
+			"must be an IANA time zone such as Europe/Paris"))
+	}
+
+	return allErrs
+}
+
+func (v *Validator) validateService(bssCluster *bssv1alpha1.BssCluster, servicePath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	service := bssCluster.Spec.Service
+	if service == nil {
+		return allErrs
+	}
+
+	serviceType := service.Type
+	if serviceType == "" {
+		serviceType = corev1.ServiceTypeClusterIP
+	}
+	if len(service.LoadBalancerSourceRanges) > 0 && serviceType != corev1.ServiceTypeLoadBalancer {
+		allErrs = append(allErrs, field.Forbidden(servicePath.Child("loadBalancerSourceRanges"),
+			"only supported for type LoadBalancer"))
+	}
+	if service.ExternalTrafficPolicy != "" && serviceType == corev1.ServiceTypeClusterIP {
+		allErrs = append(allErrs, field.Forbidden(servicePath.Child("externalTrafficPolicy"),
+			"only supported for types NodePort and LoadBalancer"))
 	}
 
+	// Extra ports must not clash with the GraphQL port
+	port := service.Port
+	if port == 0 {
+		port = builder.DefaultServicePort
+	}
+	names := sets.New(builder.HTTPPortName)
+	ports := sets.New(port)
+	for i, extraPort := range service.ExtraPorts {
+		extraPortPath := servicePath.Child("extraPorts").Index(i)
+		if extraPort.Name == "" {
+			allErrs = append(allErrs, field.Required(extraPortPath.Child("name"), "extra ports must be named"))
+		} else if names.Has(extraPort.Name) {
+			allErrs = append(allErrs, field.Duplicate(extraPortPath.Child("name"), extraPort.Name))
+		}
+		if ports.Has(extraPort.Port) {
+			allErrs = append(allErrs, field.Duplicate(extraPortPath.Child("port"), extraPort.Port))
+		}
+		names.Insert(extraPort.Name)
+		ports.Insert(extraPort.Port)
+	}
+
+	return allErrs
+}
+
+func (v *Validator) validateExposure(bssCluster *bssv1alpha1.BssCluster, exposurePath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	exposure := bssCluster.Spec.Exposure
+	if exposure == nil {
+		return allErrs
+	}
+
+	if exposure.Host == "" {
+		allErrs = append(allErrs, field.Required(exposurePath.Child("host"), "host is required but not specified"))
+	}
+
+	// Ingresses and HTTPRoutes are configured differently: an Ingress picks
+	// its controller and certificate, an HTTPRoute inherits both from its Gateway
+	switch builder.Exposure(bssCluster) {
+	case bssv1alpha1.ExposureTypeIngress:
+		if len(exposure.ParentRefs) > 0 {
+			allErrs = append(allErrs, field.Forbidden(exposurePath.Child("parentRefs"),
+				"only supported for type HTTPRoute"))
+		}
+		if exposure.TLS != nil && exposure.TLS.SecretName == "" {
+			allErrs = append(allErrs, field.Required(exposurePath.Child("tls", "secretName"),
+				"secretName is required for type Ingress"))
+		}
+	case bssv1alpha1.ExposureTypeHTTPRoute:
+		if len(exposure.ParentRefs) == 0 {
+			allErrs = append(allErrs, field.Required(exposurePath.Child("parentRefs"),
+				"parentRefs are required for type HTTPRoute"))
+		}
+		if exposure.IngressClassName != nil {
+			allErrs = append(allErrs, field.Forbidden(exposurePath.Child("ingressClassName"),
+				"only supported for type Ingress"))
+		}
+		if exposure.TLS != nil && exposure.TLS.SecretName != "" {
+			allErrs = append(allErrs, field.Forbidden(exposurePath.Child("tls", "secretName"),
+				"TLS is terminated by the parent Gateway for type HTTPRoute"))
+		}
+	}
+
+	return allErrs
+}
+
+func (v *Validator) validateScaling(bssCluster *bssv1alpha1.BssCluster, specPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+
+	if builder.HasPodDisruptionBudget(bssCluster) {
+		budget := bssCluster.Spec.Availability.PodDisruptionBudget
+		if budget.MinAvailable != nil && budget.MaxUnavailable != nil {
+			allErrs = append(allErrs, field.Forbidden(specPath.Child("availability", "podDisruptionBudget"),
+				"minAvailable and maxUnavailable are mutually exclusive"))
+		}
+	}
+
+	if autoscaling := bssCluster.Spec.Autoscaling; autoscaling != nil {
+		autoscalingPath := specPath.Child("autoscaling")
+		if autoscaling.MaxReplicas < 1 {
+			allErrs = append(allErrs, field.Invalid(autoscalingPath.Child("maxReplicas"), autoscaling.MaxReplicas,
+				"must be at least 1"))
+		} else if minReplicas := builder.Replicas(bssCluster); minReplicas > autoscaling.MaxReplicas {
+			allErrs = append(allErrs, field.Invalid(autoscalingPath.Child("minReplicas"), minReplicas,
+				fmt.Sprintf("must not exceed maxReplicas (%d)", autoscaling.MaxReplicas)))
+		}
+	}
+
+	return allErrs
+}
+
+func (v *Validator) validateSpecUpdate(oldCluster, newCluster *bssv1alpha1.BssCluster) field.ErrorList {
+	var allErrs field.ErrorList
+	specPath := field.NewPath("spec")
+
+	// The name identifies the cluster in the bss-api and cannot be changed
+	if oldCluster.Spec.Name != "" && oldCluster.Spec.Name != newCluster.Spec.Name {
+		allErrs = append(allErrs, field.Forbidden(specPath.Child("name"), "name is immutable"))
+	}
+
+	allErrs = append(allErrs, v.validateStorageUpdate(oldCluster, newCluster, specPath.Child("storage"))...)
+
+	// Unsafe upgrade paths are only allowed when the cluster opts in
+	if newCluster.Annotations[bssv1alpha1.AnnotationAllowUnsafeUpgrade] != "true" {
+		if err := ValidateUpgradePath(oldCluster.Spec.Version, newCluster.Spec.Version); err != nil {
+			allErrs = append(allErrs, field.Forbidden(specPath.Child("version"), err.Error()))
+		}
+	}
+
+	return allErrs
+}
+
+// ValidateUpgradePath checks that bss-api can be upgraded from one version to
+// another: downgrades are not supported by bss-api, and upgrades must not skip
+// a major version. A from version that does not parse predates validation and
+// is allowed to move anywhere.
+func ValidateUpgradePath(from, to string) error {
+	fromVersion, err := version.ParseSemantic(from)
+	if err != nil {
+		return nil
+	}
+	toVersion, err := version.ParseSemantic(to)
+	if err != nil {
+		return nil // already reported by validateSpec
+	}
+	if toVersion.LessThan(fromVersion) {
+		return fmt.Errorf("downgrade from %s to %s is not allowed", from, to)
+	}
+	if toVersion.Major() > fromVersion.Major()+1 {
+		return fmt.Errorf("upgrade from %s to %s skips major version %d", from, to, fromVersion.Major()+1)
+	}
 	return nil
 }
+
+func (v *Validator) validateStorageUpdate(oldCluster, newCluster *bssv1alpha1.BssCluster, storagePath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	oldStorage, newStorage := oldCluster.Spec.Storage, newCluster.Spec.Storage
+	if oldStorage == nil || newStorage == nil {
+		return allErrs
+	}
+
+	// Existing claims can be expanded but never shrunk
+	if newStorage.Size.Cmp(oldStorage.Size) < 0 {
+		allErrs = append(allErrs, field.Forbidden(storagePath.Child("size"),
+			fmt.Sprintf("cannot shrink volumes from %s to %s", oldStorage.Size.String(), newStorage.Size.String())))
+	}
+
+	// Existing claims keep their storage class, so changing it would leave
+	// replicas on different classes
+	if !equality.Semantic.DeepEqual(oldStorage.StorageClassName, newStorage.StorageClassName) {
+		allErrs = append(allErrs, field.Forbidden(storagePath.Child("storageClassName"), "storageClassName is immutable"))
+	}
+
+	return allErrs
+}
@@ -0,0 +1,126 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	bssv1alpha1 "github.com/brmorris/bss-operator/api/v1alpha1"
+	"github.com/brmorris/bss-operator/internal/validation"
+)
+
+// bssclusterlog is for logging in this package.
+var bssclusterlog = logf.Log.WithName("bsscluster-resource")
+
+// SetupBssClusterWebhookWithManager registers the webhook for BssCluster in the manager.
+func SetupBssClusterWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).For(&bssv1alpha1.BssCluster{}).
+		WithValidator(NewBssClusterCustomValidator()).
+		WithDefaulter(&BssClusterCustomDefaulter{}).
+		Complete()
+}
+
+// +kubebuilder:webhook:path=/mutate-bss-localhost-v1alpha1-bsscluster,mutating=true,failurePolicy=fail,sideEffects=None,groups=bss.localhost,resources=bssclusters,verbs=create;update,versions=v1alpha1,name=mbsscluster-v1alpha1.kb.io,admissionReviewVersions=v1
+
+// BssClusterCustomDefaulter sets default values on BssCluster resources when
+// they are created or updated.
+type BssClusterCustomDefaulter struct{}
+
+var _ webhook.CustomDefaulter = &BssClusterCustomDefaulter{}
+
+// Default implements webhook.CustomDefaulter so a webhook will be registered for the Kind BssCluster.
+func (d *BssClusterCustomDefaulter) Default(_ context.Context, obj runtime.Object) error {
+	bsscluster, ok := obj.(*bssv1alpha1.BssCluster)
+	if !ok {
+		return fmt.Errorf("expected a BssCluster object but got %T", obj)
+	}
+	bssclusterlog.V(1).Info("Defaulting for BssCluster", "name", bsscluster.GetName())
+
+	// The bss-api cluster is named after the resource unless told otherwise
+	if bsscluster.Spec.Name == "" {
+		bsscluster.Spec.Name = bsscluster.Name
+	}
+
+	if bsscluster.Spec.Replicas == nil {
+		replicas := int32(1)
+		bsscluster.Spec.Replicas = &replicas
+	}
+
+	return nil
+}
+
+// +kubebuilder:webhook:path=/validate-bss-localhost-v1alpha1-bsscluster,mutating=false,failurePolicy=fail,sideEffects=None,groups=bss.localhost,resources=bssclusters,verbs=create;update,versions=v1alpha1,name=vbsscluster-v1alpha1.kb.io,admissionReviewVersions=v1
+
+// BssClusterCustomValidator validates BssCluster resources when they are
+// created or updated, using the same rules the controller applies.
+type BssClusterCustomValidator struct {
+	validator *validation.Validator
+}
+
+var _ webhook.CustomValidator = &BssClusterCustomValidator{}
+
+// NewBssClusterCustomValidator creates a new BssClusterCustomValidator
+func NewBssClusterCustomValidator() *BssClusterCustomValidator {
+	return &BssClusterCustomValidator{
+		validator: validation.NewValidator(),
+	}
+}
+
+// ValidateCreate implements webhook.CustomValidator so a webhook will be registered for the type BssCluster.
+func (v *BssClusterCustomValidator) ValidateCreate(_ context.Context, obj runtime.Object) (admission.Warnings, error) {
+	bsscluster, ok := obj.(*bssv1alpha1.BssCluster)
+	if !ok {
+		return nil, fmt.Errorf("expected a BssCluster object but got %T", obj)
+	}
+	bssclusterlog.V(1).Info("Validation for BssCluster upon creation", "name", bsscluster.GetName())
+
+	return nil, v.validator.Validate(bsscluster)
+}
+
+// ValidateUpdate implements webhook.CustomValidator so a webhook will be registered for the type BssCluster.
+func (v *BssClusterCustomValidator) ValidateUpdate(_ context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	oldCluster, ok := oldObj.(*bssv1alpha1.BssCluster)
+	if !ok {
+		return nil, fmt.Errorf("expected a BssCluster object for the oldObj but got %T", oldObj)
+	}
+	newCluster, ok := newObj.(*bssv1alpha1.BssCluster)
+	if !ok {
+		return nil, fmt.Errorf("expected a BssCluster object for the newObj but got %T", newObj)
+	}
+	bssclusterlog.V(1).Info("Validation for BssCluster upon update", "name", newCluster.GetName())
+
+	// Metadata-only updates, such as the controller adding or removing its
+	// finalizer, must go through even for objects created before validation
+	if equality.Semantic.DeepEqual(oldCluster.Spec, newCluster.Spec) {
+		return nil, nil
+	}
+
+	return nil, v.validator.ValidateUpdate(oldCluster, newCluster)
+}
+
+// ValidateDelete implements webhook.CustomValidator so a webhook will be registered for the type BssCluster.
+func (v *BssClusterCustomValidator) ValidateDelete(_ context.Context, _ runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
@@ -0,0 +1,266 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/ptr"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+
+	bssv1alpha1 "github.com/brmorris/bss-operator/api/v1alpha1"
+)
+
+var _ = Describe("BssCluster Webhook", func() {
+	var (
+		ctx       context.Context
+		obj       *bssv1alpha1.BssCluster
+		oldObj    *bssv1alpha1.BssCluster
+		validator *BssClusterCustomValidator
+		defaulter *BssClusterCustomDefaulter
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		obj = &bssv1alpha1.BssCluster{
+			ObjectMeta: metav1.ObjectMeta{Name: "demo", Namespace: "default"},
+			Spec: bssv1alpha1.BssClusterSpec{
+				Name:    "demo",
+				Version: "1.2.0",
+			},
+		}
+		oldObj = obj.DeepCopy()
+		validator = NewBssClusterCustomValidator()
+		defaulter = &BssClusterCustomDefaulter{}
+	})
+
+	Context("When creating BssCluster under Defaulting Webhook", func() {
+		It("Should apply defaults when fields are unset", func() {
+			obj.Spec.Name = ""
+			Expect(defaulter.Default(ctx, obj)).To(Succeed())
+			Expect(obj.Spec.Name).To(Equal("demo"))
+			Expect(obj.Spec.Replicas).NotTo(BeNil())
+			Expect(*obj.Spec.Replicas).To(Equal(int32(1)))
+		})
+	})
+
+	Context("When creating or updating BssCluster under Validating Webhook", func() {
+		It("Should admit a valid BssCluster", func() {
+			Expect(validator.ValidateCreate(ctx, obj)).Error().NotTo(HaveOccurred())
+		})
+
+		It("Should deny a version that is not semver", func() {
+			obj.Spec.Version = "latest"
+			_, err := validator.ValidateCreate(ctx, obj)
+			Expect(err).To(MatchError(ContainSubstring("spec.version")))
+		})
+
+		It("Should deny fewer than one replica", func() {
+			replicas := int32(0)
+			obj.Spec.Replicas = &replicas
+			_, err := validator.ValidateCreate(ctx, obj)
+			Expect(err).To(MatchError(ContainSubstring("spec.replicas")))
+		})
+
+		It("Should admit an upgrade", func() {
+			obj.Spec.Version = "1.3.0"
+			Expect(validator.ValidateUpdate(ctx, oldObj, obj)).Error().NotTo(HaveOccurred())
+		})
+
+		It("Should deny a downgrade", func() {
+			obj.Spec.Version = "1.1.9"
+			_, err := validator.ValidateUpdate(ctx, oldObj, obj)
+			Expect(err).To(MatchError(ContainSubstring("downgrade")))
+		})
+
+		It("Should deny skipping a major version", func() {
+			obj.Spec.Version = "3.0.0"
+			_, err := validator.ValidateUpdate(ctx, oldObj, obj)
+			Expect(err).To(MatchError(ContainSubstring("skips major version 2")))
+		})
+
+		It("Should admit unsafe upgrades when the cluster allows them", func() {
+			obj.Annotations = map[string]string{bssv1alpha1.AnnotationAllowUnsafeUpgrade: "true"}
+			obj.Spec.Version = "1.1.9"
+			Expect(validator.ValidateUpdate(ctx, oldObj, obj)).Error().NotTo(HaveOccurred())
+			obj.Spec.Version = "3.0.0"
+			Expect(validator.ValidateUpdate(ctx, oldObj, obj)).Error().NotTo(HaveOccurred())
+		})
+
+		It("Should deny renaming the cluster", func() {
+			obj.Spec.Name = "renamed"
+			_, err := validator.ValidateUpdate(ctx, oldObj, obj)
+			Expect(err).To(MatchError(ContainSubstring("spec.name")))
+		})
+
+		It("Should deny storage without the StatefulSet workload", func() {
+			obj.Spec.Storage = &bssv1alpha1.StorageSpec{Size: resource.MustParse("1Gi")}
+			_, err := validator.ValidateCreate(ctx, obj)
+			Expect(err).To(MatchError(ContainSubstring("spec.storage")))
+
+			obj.Spec.Workload = bssv1alpha1.WorkloadTypeStatefulSet
+			Expect(validator.ValidateCreate(ctx, obj)).Error().NotTo(HaveOccurred())
+		})
+
+		It("Should admit expanding storage but deny shrinking it", func() {
+			oldObj.Spec.Workload = bssv1alpha1.WorkloadTypeStatefulSet
+			oldObj.Spec.Storage = &bssv1alpha1.StorageSpec{Size: resource.MustParse("2Gi")}
+			obj = oldObj.DeepCopy()
+
+			obj.Spec.Storage.Size = resource.MustParse("4Gi")
+			Expect(validator.ValidateUpdate(ctx, oldObj, obj)).Error().NotTo(HaveOccurred())
+
+			obj.Spec.Storage.Size = resource.MustParse("1Gi")
+			_, err := validator.ValidateUpdate(ctx, oldObj, obj)
+			Expect(err).To(MatchError(ContainSubstring("spec.storage.size")))
+		})
+
+		It("Should deny Service settings that do not apply to the Service type", func() {
+			obj.Spec.Service = &bssv1alpha1.BssClusterServiceSpec{
+				ExternalTrafficPolicy:    corev1.ServiceExternalTrafficPolicyLocal,
+				LoadBalancerSourceRanges: []string{"10.0.0.0/8"},
+			}
+			_, err := validator.ValidateCreate(ctx, obj)
+			Expect(err).To(MatchError(ContainSubstring("spec.service.externalTrafficPolicy")))
+			Expect(err).To(MatchError(ContainSubstring("spec.service.loadBalancerSourceRanges")))
+
+			obj.Spec.Service.Type = corev1.ServiceTypeLoadBalancer
+			Expect(validator.ValidateCreate(ctx, obj)).Error().NotTo(HaveOccurred())
+		})
+
+		It("Should deny extra Service ports that clash with the http port", func() {
+			obj.Spec.Service = &bssv1alpha1.BssClusterServiceSpec{
+				ExtraPorts: []corev1.ServicePort{{Name: "http", Port: 9090}},
+			}
+			_, err := validator.ValidateCreate(ctx, obj)
+			Expect(err).To(MatchError(ContainSubstring("spec.service.extraPorts[0].name")))
+
+			obj.Spec.Service.ExtraPorts = []corev1.ServicePort{{Name: "metrics", Port: 80}}
+			_, err = validator.ValidateCreate(ctx, obj)
+			Expect(err).To(MatchError(ContainSubstring("spec.service.extraPorts[0].port")))
+
+			obj.Spec.Service.ExtraPorts[0].Port = 9090
+			Expect(validator.ValidateCreate(ctx, obj)).Error().NotTo(HaveOccurred())
+		})
+
+		It("Should require a certificate for a TLS Ingress", func() {
+			obj.Spec.Exposure = &bssv1alpha1.ExposureSpec{
+				Host: "bss.example.com",
+				TLS:  &bssv1alpha1.ExposureTLSSpec{},
+			}
+			_, err := validator.ValidateCreate(ctx, obj)
+			Expect(err).To(MatchError(ContainSubstring("spec.exposure.tls.secretName")))
+
+			obj.Spec.Exposure.TLS.SecretName = "bss-tls"
+			Expect(validator.ValidateCreate(ctx, obj)).Error().NotTo(HaveOccurred())
+		})
+
+		It("Should require parent Gateways for an HTTPRoute", func() {
+			obj.Spec.Exposure = &bssv1alpha1.ExposureSpec{
+				Type:             bssv1alpha1.ExposureTypeHTTPRoute,
+				Host:             "bss.example.com",
+				IngressClassName: ptr.To("nginx"),
+			}
+			_, err := validator.ValidateCreate(ctx, obj)
+			Expect(err).To(MatchError(ContainSubstring("spec.exposure.parentRefs")))
+			Expect(err).To(MatchError(ContainSubstring("spec.exposure.ingressClassName")))
+
+			obj.Spec.Exposure.IngressClassName = nil
+			obj.Spec.Exposure.ParentRefs = []gatewayv1.ParentReference{{Name: "public"}}
+			Expect(validator.ValidateCreate(ctx, obj)).Error().NotTo(HaveOccurred())
+		})
+
+		It("Should deny combining config settings with a raw config file", func() {
+			obj.Spec.Config = &bssv1alpha1.ConfigSpec{
+				Settings: map[string]string{"logLevel": "info"},
+				Raw:      "logLevel: debug\n",
+			}
+			_, err := validator.ValidateCreate(ctx, obj)
+			Expect(err).To(MatchError(ContainSubstring("spec.config.raw")))
+
+			obj.Spec.Config.Settings = nil
+			Expect(validator.ValidateCreate(ctx, obj)).Error().NotTo(HaveOccurred())
+		})
+
+		It("Should only rotate generated credentials on a schedule", func() {
+			obj.Spec.Credentials = &bssv1alpha1.CredentialsSpec{
+				SecretName:  "bss-credentials",
+				RotateEvery: &metav1.Duration{Duration: time.Minute},
+			}
+			_, err := validator.ValidateCreate(ctx, obj)
+			Expect(err).To(MatchError(ContainSubstring("referenced Secrets are rotated by their owner")))
+			Expect(err).To(MatchError(ContainSubstring("must be at least 1h0m0s")))
+
+			obj.Spec.Credentials.SecretName = ""
+			obj.Spec.Credentials.RotateEvery.Duration = 30 * 24 * time.Hour
+			Expect(validator.ValidateCreate(ctx, obj)).Error().NotTo(HaveOccurred())
+		})
+
+		It("Should deny a disruption budget with both minAvailable and maxUnavailable", func() {
+			obj.Spec.Availability = &bssv1alpha1.AvailabilitySpec{
+				PodDisruptionBudget: &bssv1alpha1.PodDisruptionBudgetSpec{
+					MinAvailable:   ptr.To(intstr.FromInt32(1)),
+					MaxUnavailable: ptr.To(intstr.FromString("50%")),
+				},
+			}
+			_, err := validator.ValidateCreate(ctx, obj)
+			Expect(err).To(MatchError(ContainSubstring("spec.availability.podDisruptionBudget")))
+
+			obj.Spec.Availability.PodDisruptionBudget.MinAvailable = nil
+			Expect(validator.ValidateCreate(ctx, obj)).Error().NotTo(HaveOccurred())
+		})
+
+		It("Should deny autoscaling with minReplicas above maxReplicas", func() {
+			obj.Spec.Autoscaling = &bssv1alpha1.AutoscalingSpec{MinReplicas: ptr.To[int32](5), MaxReplicas: 3}
+			_, err := validator.ValidateCreate(ctx, obj)
+			Expect(err).To(MatchError(ContainSubstring("spec.autoscaling.minReplicas")))
+
+			obj.Spec.Autoscaling.MaxReplicas = 5
+			Expect(validator.ValidateCreate(ctx, obj)).Error().NotTo(HaveOccurred())
+		})
+
+		It("Should deny a maintenance window with an unknown time zone or no duration", func() {
+			obj.Spec.MaintenanceWindow = &bssv1alpha1.MaintenanceWindowSpec{
+				Days:     []bssv1alpha1.Weekday{"Saturday", "Sunday"},
+				Start:    "02:00",
+				TimeZone: "Mars/Olympus_Mons",
+			}
+			_, err := validator.ValidateCreate(ctx, obj)
+			Expect(err).To(MatchError(ContainSubstring("spec.maintenanceWindow.timeZone")))
+			Expect(err).To(MatchError(ContainSubstring("spec.maintenanceWindow.duration")))
+
+			obj.Spec.MaintenanceWindow.TimeZone = "Europe/Paris"
+			obj.Spec.MaintenanceWindow.Duration = metav1.Duration{Duration: 4 * time.Hour}
+			Expect(validator.ValidateCreate(ctx, obj)).Error().NotTo(HaveOccurred())
+		})
+
+		It("Should admit metadata-only updates to an invalid BssCluster", func() {
+			oldObj.Spec.Version = "latest"
+			obj = oldObj.DeepCopy()
+			now := metav1.NewTime(time.Now())
+			obj.DeletionTimestamp = &now
+			Expect(validator.ValidateUpdate(ctx, oldObj, obj)).Error().NotTo(HaveOccurred())
+		})
+	})
+})
@@ -0,0 +1,120 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	bssv1alpha1 "github.com/brmorris/bss-operator/api/v1alpha1"
+	"github.com/brmorris/bss-operator/internal/validation"
+)
+
+// defaultRefreshInterval is the polling interval, in seconds, of a BSSQuery that sets none
+const defaultRefreshInterval = 30
+
+// bssquerylog is for logging in this package.
+var bssquerylog = logf.Log.WithName("bssquery-resource")
+
+// SetupBSSQueryWebhookWithManager registers the webhook for BSSQuery in the manager.
+func SetupBSSQueryWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).For(&bssv1alpha1.BSSQuery{}).
+		WithValidator(NewBSSQueryCustomValidator()).
+		WithDefaulter(&BSSQueryCustomDefaulter{}).
+		Complete()
+}
+
+// +kubebuilder:webhook:path=/mutate-bss-localhost-v1alpha1-bssquery,mutating=true,failurePolicy=fail,sideEffects=None,groups=bss.localhost,resources=bssqueries,verbs=create;update,versions=v1alpha1,name=mbssquery-v1alpha1.kb.io,admissionReviewVersions=v1
+
+// BSSQueryCustomDefaulter sets default values on BSSQuery resources when
+// they are created or updated.
+type BSSQueryCustomDefaulter struct{}
+
+var _ webhook.CustomDefaulter = &BSSQueryCustomDefaulter{}
+
+// Default implements webhook.CustomDefaulter so a webhook will be registered for the Kind BSSQuery.
+func (d *BSSQueryCustomDefaulter) Default(_ context.Context, obj runtime.Object) error {
+	bssquery, ok := obj.(*bssv1alpha1.BSSQuery)
+	if !ok {
+		return fmt.Errorf("expected a BSSQuery object but got %T", obj)
+	}
+	bssquerylog.V(1).Info("Defaulting for BSSQuery", "name", bssquery.GetName())
+
+	if bssquery.Spec.RefreshInterval == 0 {
+		bssquery.Spec.RefreshInterval = defaultRefreshInterval
+	}
+	if output := bssquery.Spec.Output; output != nil && output.Annotations != nil && output.Annotations.Prefix == "" {
+		output.Annotations.Prefix = bssv1alpha1.DefaultQueryOutputAnnotationPrefix
+	}
+
+	return nil
+}
+
+// +kubebuilder:webhook:path=/validate-bss-localhost-v1alpha1-bssquery,mutating=false,failurePolicy=fail,sideEffects=None,groups=bss.localhost,resources=bssqueries,verbs=create;update,versions=v1alpha1,name=vbssquery-v1alpha1.kb.io,admissionReviewVersions=v1
+
+// BSSQueryCustomValidator validates BSSQuery resources when they are created
+// or updated, using the same rules the controller applies.
+type BSSQueryCustomValidator struct {
+	validator *validation.Validator
+}
+
+var _ webhook.CustomValidator = &BSSQueryCustomValidator{}
+
+// NewBSSQueryCustomValidator creates a new BSSQueryCustomValidator
+func NewBSSQueryCustomValidator() *BSSQueryCustomValidator {
+	return &BSSQueryCustomValidator{
+		validator: validation.NewValidator(),
+	}
+}
+
+// ValidateCreate implements webhook.CustomValidator so a webhook will be registered for the type BSSQuery.
+func (v *BSSQueryCustomValidator) ValidateCreate(_ context.Context, obj runtime.Object) (admission.Warnings, error) {
+	bssquery, ok := obj.(*bssv1alpha1.BSSQuery)
+	if !ok {
+		return nil, fmt.Errorf("expected a BSSQuery object but got %T", obj)
+	}
+	bssquerylog.V(1).Info("Validation for BSSQuery upon creation", "name", bssquery.GetName())
+
+	return nil, v.validator.ValidateQuery(bssquery)
+}
+
+// ValidateUpdate implements webhook.CustomValidator so a webhook will be registered for the type BSSQuery.
+func (v *BSSQueryCustomValidator) ValidateUpdate(_ context.Context, _, newObj runtime.Object) (admission.Warnings, error) {
+	bssquery, ok := newObj.(*bssv1alpha1.BSSQuery)
+	if !ok {
+		return nil, fmt.Errorf("expected a BSSQuery object for the newObj but got %T", newObj)
+	}
+	bssquerylog.V(1).Info("Validation for BSSQuery upon update", "name", bssquery.GetName())
+
+	// Allow a BSSQuery being deleted to release its finalizers regardless of its spec
+	if !bssquery.DeletionTimestamp.IsZero() {
+		return nil, nil
+	}
+
+	return nil, v.validator.ValidateQuery(bssquery)
+}
+
+// ValidateDelete implements webhook.CustomValidator so a webhook will be registered for the type BSSQuery.
+func (v *BSSQueryCustomValidator) ValidateDelete(_ context.Context, _ runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
@@ -0,0 +1,290 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	bssv1alpha1 "github.com/brmorris/bss-operator/api/v1alpha1"
+)
+
+var _ = Describe("BSSQuery Webhook", func() {
+	var (
+		ctx       context.Context
+		obj       *bssv1alpha1.BSSQuery
+		validator *BSSQueryCustomValidator
+		defaulter *BSSQueryCustomDefaulter
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		obj = &bssv1alpha1.BSSQuery{
+			ObjectMeta: metav1.ObjectMeta{Name: "demo", Namespace: "default"},
+			Spec: bssv1alpha1.BSSQuerySpec{
+				APIEndpoint: "http://bss-api:8880/graphql",
+				Query:       bssv1alpha1.QueryTypeClusters,
+			},
+		}
+		validator = NewBSSQueryCustomValidator()
+		defaulter = &BSSQueryCustomDefaulter{}
+	})
+
+	Context("When creating BSSQuery under Defaulting Webhook", func() {
+		It("Should default the refresh interval", func() {
+			Expect(defaulter.Default(ctx, obj)).To(Succeed())
+			Expect(obj.Spec.RefreshInterval).To(Equal(int32(defaultRefreshInterval)))
+		})
+
+		It("Should default the prefix of output annotations outside the reserved one", func() {
+			obj.Spec.Output = &bssv1alpha1.QueryOutputSpec{
+				Fields: []bssv1alpha1.QueryOutputField{{Name: "state", JSONPath: "{.state}"}},
+				Annotations: &bssv1alpha1.QueryOutputAnnotations{
+					TargetRef: bssv1alpha1.QueryOutputTargetReference{APIVersion: "apps/v1", Kind: "Deployment", Name: "app"},
+				},
+			}
+			Expect(defaulter.Default(ctx, obj)).To(Succeed())
+			Expect(obj.Spec.Output.Annotations.Prefix).To(Equal(bssv1alpha1.DefaultQueryOutputAnnotationPrefix))
+			Expect(obj.Spec.Output.Annotations.Prefix).NotTo(HavePrefix(bssv1alpha1.AnnotationPrefix))
+			Expect(validator.ValidateCreate(ctx, obj)).Error().NotTo(HaveOccurred())
+		})
+	})
+
+	Context("When creating or updating BSSQuery under Validating Webhook", func() {
+		It("Should admit a valid BSSQuery", func() {
+			Expect(validator.ValidateCreate(ctx, obj)).Error().NotTo(HaveOccurred())
+		})
+
+		It("Should deny an endpoint that is not an http URL", func() {
+			obj.Spec.APIEndpoint = "bss-api:8880/graphql"
+			_, err := validator.ValidateCreate(ctx, obj)
+			Expect(err).To(MatchError(ContainSubstring("spec.apiEndpoint")))
+		})
+
+		It("Should target either an endpoint or a BssCluster", func() {
+			obj.Spec.ClusterRef = &bssv1alpha1.BssClusterReference{Name: "demo"}
+			_, err := validator.ValidateCreate(ctx, obj)
+			Expect(err).To(MatchError(ContainSubstring("apiEndpoint cannot be combined with clusterRef")))
+
+			obj.Spec.APIEndpoint = ""
+			Expect(validator.ValidateCreate(ctx, obj)).Error().NotTo(HaveOccurred())
+
+			obj.Spec.ClusterRef = nil
+			_, err = validator.ValidateCreate(ctx, obj)
+			Expect(err).To(MatchError(ContainSubstring("spec.apiEndpoint")))
+		})
+
+		It("Should require a parseable query document for graphql queries", func() {
+			obj.Spec.Query = bssv1alpha1.QueryTypeGraphQL
+			_, err := validator.ValidateCreate(ctx, obj)
+			Expect(err).To(MatchError(ContainSubstring("spec.graphql")))
+
+			obj.Spec.GraphQL = &bssv1alpha1.GraphQLQuerySpec{
+				Document:  `mutation { deleteCluster(id: "1") }`,
+				Variables: &runtime.RawExtension{Raw: []byte(`["not", "an", "object"]`)},
+			}
+			_, err = validator.ValidateCreate(ctx, obj)
+			Expect(err).To(MatchError(ContainSubstring("only queries can be run")))
+			Expect(err).To(MatchError(ContainSubstring("spec.graphql.variables")))
+
+			obj.Spec.GraphQL.Document = `query One($id: String!) { cluster(id: $id) { name } }`
+			obj.Spec.GraphQL.Variables.Raw = []byte(`{"id": "c-1"}`)
+			Expect(validator.ValidateCreate(ctx, obj)).Error().NotTo(HaveOccurred())
+		})
+
+		It("Should require one output destination and compilable fields", func() {
+			obj.Spec.Output = &bssv1alpha1.QueryOutputSpec{
+				Fields: []bssv1alpha1.QueryOutputField{
+					{Name: "name", JSONPath: "{.name"},
+					{Name: "state", Expression: "result.state +"},
+				},
+				ConfigMap: &bssv1alpha1.QueryOutputObject{},
+				Secret:    &bssv1alpha1.QueryOutputObject{},
+			}
+			_, err := validator.ValidateCreate(ctx, obj)
+			Expect(err).To(MatchError(ContainSubstring("exactly one of configMap, secret and annotations")))
+			Expect(err).To(MatchError(ContainSubstring("spec.output.fields[0]")))
+			Expect(err).To(MatchError(ContainSubstring("spec.output.fields[1]")))
+
+			obj.Spec.Output.Secret = nil
+			obj.Spec.Output.Fields[0].JSONPath = "{.name}"
+			obj.Spec.Output.Fields[1].Expression = "result.state"
+			Expect(validator.ValidateCreate(ctx, obj)).Error().NotTo(HaveOccurred())
+
+			// Annotation keys must be qualified names
+			obj.Spec.Output.ConfigMap = nil
+			obj.Spec.Output.Annotations = &bssv1alpha1.QueryOutputAnnotations{
+				TargetRef: bssv1alpha1.QueryOutputTargetReference{APIVersion: "apps/v1", Kind: "Deployment", Name: "app"},
+				Prefix:    bssv1alpha1.DefaultQueryOutputAnnotationPrefix,
+			}
+			obj.Spec.Output.Fields[0].Name = "cluster/name"
+			_, err = validator.ValidateCreate(ctx, obj)
+			Expect(err).To(MatchError(ContainSubstring("spec.output.fields[0].name")))
+		})
+
+		It("Should deny output annotations reserved by the operator", func() {
+			obj.Spec.Output = &bssv1alpha1.QueryOutputSpec{
+				Fields: []bssv1alpha1.QueryOutputField{{Name: "state", JSONPath: "{.state}"}},
+				Annotations: &bssv1alpha1.QueryOutputAnnotations{
+					TargetRef: bssv1alpha1.QueryOutputTargetReference{APIVersion: "apps/v1", Kind: "Deployment", Name: "app"},
+				},
+			}
+			for _, key := range []string{
+				bssv1alpha1.AnnotationClusterID,
+				bssv1alpha1.AnnotationSkipPreDeleteHooks,
+				bssv1alpha1.AnnotationConfigHash,
+				bssv1alpha1.AnnotationRotateCredentials,
+				bssv1alpha1.AnnotationCredentialsRotatedAt,
+				bssv1alpha1.AnnotationCredentialsRotationRequest,
+				bssv1alpha1.AnnotationVersion,
+				bssv1alpha1.AnnotationAllowUnsafeUpgrade,
+				bssv1alpha1.AnnotationUpgradeStartTime,
+				bssv1alpha1.AnnotationAppliedHash,
+				bssv1alpha1.AnnotationPodTemplateHash,
+				bssv1alpha1.AnnotationPendingPodTemplateHash,
+				bssv1alpha1.AnnotationPaused,
+			} {
+				// Either through the prefix or through the field name
+				obj.Spec.Output.Annotations.Prefix = bssv1alpha1.AnnotationPrefix
+				obj.Spec.Output.Fields[0].Name = strings.TrimPrefix(key, bssv1alpha1.AnnotationPrefix)
+				_, err := validator.ValidateCreate(ctx, obj)
+				Expect(err).To(MatchError(ContainSubstring("spec.output.annotations.prefix")))
+
+				obj.Spec.Output.Annotations.Prefix = ""
+				obj.Spec.Output.Fields[0].Name = key
+				_, err = validator.ValidateCreate(ctx, obj)
+				Expect(err).To(MatchError(ContainSubstring("spec.output.annotations.prefix")))
+			}
+		})
+
+		It("Should only allow prefixes of the query annotation domain", func() {
+			obj.Spec.Output = &bssv1alpha1.QueryOutputSpec{
+				Fields: []bssv1alpha1.QueryOutputField{{Name: "state", JSONPath: "{.state}"}},
+				Annotations: &bssv1alpha1.QueryOutputAnnotations{
+					TargetRef: bssv1alpha1.QueryOutputTargetReference{APIVersion: "v1", Kind: "Service", Name: "app"},
+				},
+			}
+			for _, prefix := range []string{
+				"nginx.ingress.kubernetes.io/",
+				"service.beta.kubernetes.io/",
+				"evilquery.bss.localhost/",
+				"query.bss.localhost.example.com/",
+				"query.bss.localhost",
+			} {
+				obj.Spec.Output.Annotations.Prefix = prefix
+				_, err := validator.ValidateCreate(ctx, obj)
+				Expect(err).To(MatchError(ContainSubstring("spec.output.annotations.prefix")), prefix)
+			}
+
+			for _, prefix := range []string{bssv1alpha1.DefaultQueryOutputAnnotationPrefix, "team.query.bss.localhost/"} {
+				obj.Spec.Output.Annotations.Prefix = prefix
+				Expect(validator.ValidateCreate(ctx, obj)).Error().NotTo(HaveOccurred(), prefix)
+			}
+		})
+
+		It("Should only annotate the allowed kinds of targets", func() {
+			obj.Spec.Output = &bssv1alpha1.QueryOutputSpec{
+				Fields: []bssv1alpha1.QueryOutputField{{Name: "state", JSONPath: "{.state}"}},
+				Annotations: &bssv1alpha1.QueryOutputAnnotations{
+					TargetRef: bssv1alpha1.QueryOutputTargetReference{
+						APIVersion: "networking.k8s.io/v1",
+						Kind:       "Ingress",
+						Name:       "app",
+					},
+					Prefix: bssv1alpha1.DefaultQueryOutputAnnotationPrefix,
+				},
+			}
+			_, err := validator.ValidateCreate(ctx, obj)
+			Expect(err).To(MatchError(ContainSubstring("spec.output.annotations.targetRef.kind")))
+
+			obj.Spec.Output.Annotations.TargetRef = bssv1alpha1.QueryOutputTargetReference{
+				APIVersion: "rbac.authorization.k8s.io/v1",
+				Kind:       "ClusterRole",
+				Name:       "admin",
+			}
+			_, err = validator.ValidateCreate(ctx, obj)
+			Expect(err).To(MatchError(ContainSubstring("spec.output.annotations.targetRef.kind")))
+
+			for _, target := range []bssv1alpha1.QueryOutputTargetReference{
+				{APIVersion: "apps/v1", Kind: "Deployment", Name: "app"},
+				{APIVersion: "apps/v1", Kind: "StatefulSet", Name: "app"},
+				{APIVersion: "v1", Kind: "Service", Name: "app"},
+				{APIVersion: "v1", Kind: "ConfigMap", Name: "app"},
+				{APIVersion: "v1", Kind: "Secret", Name: "app"},
+			} {
+				obj.Spec.Output.Annotations.TargetRef = target
+				Expect(validator.ValidateCreate(ctx, obj)).Error().NotTo(HaveOccurred(), target.Kind)
+			}
+		})
+
+		It("Should deny annotating objects of the operator API group", func() {
+			obj.Spec.Output = &bssv1alpha1.QueryOutputSpec{
+				Fields: []bssv1alpha1.QueryOutputField{{Name: "state", JSONPath: "{.state}"}},
+				Annotations: &bssv1alpha1.QueryOutputAnnotations{
+					TargetRef: bssv1alpha1.QueryOutputTargetReference{
+						APIVersion: bssv1alpha1.GroupVersion.String(),
+						Kind:       "BssCluster",
+						Name:       "demo",
+					},
+					Prefix: bssv1alpha1.DefaultQueryOutputAnnotationPrefix,
+				},
+			}
+			_, err := validator.ValidateCreate(ctx, obj)
+			Expect(err).To(MatchError(ContainSubstring("spec.output.annotations.targetRef.kind")))
+		})
+
+		It("Should allow one kind of credentials and TLS only over https", func() {
+			obj.Spec.Auth = &bssv1alpha1.QueryAuthSpec{
+				BearerTokenSecretRef: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: "token"},
+					Key:                  "token",
+				},
+				BasicAuthSecretRef: &corev1.LocalObjectReference{Name: "basic"},
+				Headers:            map[string]string{"authorization": "Bearer static", "X-Tenant": "blue"},
+				TLS: &bssv1alpha1.QueryTLSSpec{
+					CA: &bssv1alpha1.CABundleSource{},
+				},
+			}
+			_, err := validator.ValidateCreate(ctx, obj)
+			Expect(err).To(MatchError(ContainSubstring("spec.auth.basicAuthSecretRef")))
+			Expect(err).To(MatchError(ContainSubstring("spec.auth.headers[authorization]")))
+			Expect(err).To(MatchError(ContainSubstring("tls requires an https apiEndpoint")))
+			Expect(err).To(MatchError(ContainSubstring("spec.auth.tls.ca")))
+
+			obj.Spec.APIEndpoint = "https://bss-api.example.com/graphql"
+			obj.Spec.Auth.BasicAuthSecretRef = nil
+			obj.Spec.Auth.Headers = map[string]string{"X-Tenant": "blue"}
+			obj.Spec.Auth.TLS.CA.ConfigMapKeyRef = &corev1.ConfigMapKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: "bss-api-ca"},
+				Key:                  "ca.crt",
+			}
+			Expect(validator.ValidateCreate(ctx, obj)).Error().NotTo(HaveOccurred())
+		})
+
+		It("Should deny a cluster query without a ClusterID", func() {
+			obj.Spec.Query = bssv1alpha1.QueryTypeCluster
+			_, err := validator.ValidateUpdate(ctx, obj.DeepCopy(), obj)
+			Expect(err).To(MatchError(ContainSubstring("spec.clusterID")))
+		})
+	})
+})
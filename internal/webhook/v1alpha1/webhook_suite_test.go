@@ -14,15 +14,20 @@ See the License for the specific language governing permissions and
 limitations under the License.
 */
 
-package resources
-
-// ConfigMapReconciler handles ConfigMap reconciliation
-// TODO: Implement ConfigMap reconciliation following the pattern in statefulset.go
-//
-// Example usage:
-// 1. Create internal/builder/configmap_builder.go
-// 2. Implement ConfigMapReconciler.Reconcile()
-// 3. Add to BssClusterReconciler in controller
-// 4. Add RBAC marker: // +kubebuilder:rbac:groups=core,resources=configmaps,verbs=get;list;watch;create;update;patch;delete
-
-// See docs/controller_architecture.md for detailed implementation guide
+package v1alpha1
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// The defaulters and validators are exercised directly, so these tests do not
+// need an API server.
+
+func TestWebhooks(t *testing.T) {
+	RegisterFailHandler(Fail)
+
+	RunSpecs(t, "Webhook Suite")
+}